@@ -9,14 +9,12 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/stolostron/search-collector/pkg/budget"
 	"github.com/stolostron/search-collector/pkg/config"
-	"github.com/stolostron/search-collector/pkg/informer"
 	lease "github.com/stolostron/search-collector/pkg/lease"
-	rec "github.com/stolostron/search-collector/pkg/reconciler"
-	tr "github.com/stolostron/search-collector/pkg/transforms"
 
 	"github.com/golang/glog"
-	"github.com/stolostron/search-collector/pkg/send"
+	"github.com/stolostron/search-collector/pkg/stream"
 	"k8s.io/apimachinery/pkg/util/wait"
 )
 
@@ -46,42 +44,96 @@ func main() {
 		glog.Info("Built from git commit: ", commit)
 	}
 
-	if !config.Cfg.DeployedInHub {
-		leaseReconciler := lease.LeaseReconciler{
-			HubKubeClient:        config.GetKubeClient(config.Cfg.AggregatorConfig),
-			LocalKubeClient:      config.GetKubeClient(config.GetKubeConfig()),
-			LeaseName:            AddonName,
-			ClusterName:          config.Cfg.ClusterName,
-			LeaseDurationSeconds: int32(LeaseDurationSeconds),
-		}
-		glog.Info("Create/Update lease for search")
-		go wait.Forever(leaseReconciler.Reconcile, time.Duration(leaseReconciler.LeaseDurationSeconds)*time.Second)
+	cfg, err := config.Load()
+	if err != nil {
+		// Exit because this is an unrecoverable configuration problem.
+		glog.Fatal("Invalid configuration. ", err)
 	}
 
-	// Create input channel
-	transformChannel := make(chan *tr.Event)
+	// Client for the cluster where the collector runs. Used to read the search-collector-config ConfigMap.
+	kubeConfig, err := config.GetKubeConfig(cfg.KubeConfig)
+	if err != nil {
+		glog.Fatal("Error building the kube config. ", err)
+	}
+	kubeClient, err := config.GetKubeClient(kubeConfig)
+	if err != nil {
+		glog.Fatal("Error creating the kube client. ", err)
+	}
 
-	// Create transformers
-	upsertTransformer := tr.NewTransformer(transformChannel, make(chan tr.NodeEvent), numThreads)
+	// Config read by the pipeline goroutines. Live changes replace it with an updated copy.
+	sharedCfg := config.NewShared(cfg)
 
-	// Init reconciler
-	reconciler := rec.NewReconciler()
-	reconciler.Input = upsertTransformer.Output
+	// Shed load when the collector gets close to its heap or goroutine budget.
+	budgetMonitor := budget.NewMonitor(sharedCfg, time.Duration(budget.DEFAULT_BUDGET_CHECK_MS)*time.Millisecond)
 
-	// Create Sender, attached to transformer
-	sender := send.NewSender(reconciler, config.Cfg.AggregatorURL, config.Cfg.ClusterName)
+	// The lease publishes the resource budget conditions on every deployment mode. On managed clusters it's
+	// also the addon lease, and it's created on the hub if leases aren't supported on the managed cluster.
+	leaseReconciler := lease.LeaseReconciler{
+		LocalKubeClient:      kubeClient,
+		LeaseName:            AddonName,
+		ClusterName:          cfg.ClusterName,
+		Conditions:           budgetMonitor.Conditions,
+		LeaseDurationSeconds: int32(LeaseDurationSeconds),
+	}
+	if !cfg.DeployedInHub && !cfg.MultiCluster() {
+		hubKubeClient, err := config.GetKubeClient(cfg.AggregatorConfig)
+		if err != nil {
+			glog.Fatal("Error creating the hub kube client. ", err)
+		}
+		leaseReconciler.HubKubeClient = hubKubeClient
+		leaseReconciler.Config = sharedCfg
+	}
+	glog.Info("Create/Update lease for search")
+	go wait.Forever(leaseReconciler.Reconcile, time.Duration(leaseReconciler.LeaseDurationSeconds)*time.Second)
+
+	// Optionally, publish the changes sent on each cycle to an event stream.
+	var publisher *stream.Publisher
+	if cfg.EventStreamFile != "" {
+		producer, err := stream.NewFileProducer(cfg.EventStreamFile)
+		if err != nil {
+			glog.Fatal("Error opening event stream file. ", err)
+		}
+		glog.Info("Publishing changes to event stream file: ", cfg.EventStreamFile)
+		publisher = stream.NewPublisher(producer)
+	}
 
-	informersInitialized := make(chan interface{})
+	// Watch the config and apply changes to the pipelines without restarting.
+	configWatcher := config.NewWatcher(sharedCfg, time.Duration(config.DEFAULT_CONFIG_WATCH_MS)*time.Millisecond,
+		kubeClient)
 
-	// Start a routine to keep our informers up to date.
-	go informer.RunInformers(informersInitialized, upsertTransformer, reconciler)
+	clusterConfigs, err := cfg.ForClusters()
+	if err != nil {
+		glog.Fatal("Invalid cluster configuration. ", err)
+	}
+	started := 0
+	for _, clusterCfg := range clusterConfigs {
+		clusterShared := sharedCfg
+		if clusterCfg != cfg {
+			clusterShared = config.NewShared(clusterCfg)
+		}
+		p, err := newPipeline(clusterShared, kubeClient, publisher, numThreads)
+		if err != nil {
+			if !cfg.MultiCluster() {
+				glog.Fatal(err)
+			}
+			// Don't let a misconfigured cluster stop the collection from the other clusters.
+			glog.Errorf("Skipping cluster %s. %v", clusterCfg.ClusterName, err)
+			continue
+		}
+		if clusterShared != sharedCfg {
+			configWatcher.OnChange(clusterShared.Apply)
+		}
+		configWatcher.OnChange(p.configChanged)
+		budgetMonitor.OnChange(p.budgetChanged)
+		go p.run()
+		started++
+	}
+	if started == 0 {
+		glog.Fatal("Unable to start collecting from any cluster.")
+	}
+	glog.Infof("Collecting from %d cluster(s).", started)
 
-	// Wait here until informers have collected the full state of the cluster.
-	// The initial payload must have the complete state to avoid unecessary deletion
-	// and recreate of existing rows in the database during the resync.
-	glog.Info("Waiting for informers to load initial state.")
-	<-informersInitialized
+	go budgetMonitor.Run(make(chan struct{}))
 
-	glog.Info("Starting the sender.")
-	sender.StartSendLoop()
+	configWatcher.Run(make(chan struct{}))
 }
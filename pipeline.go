@@ -0,0 +1,133 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package main
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/stolostron/search-collector/pkg/budget"
+	"github.com/stolostron/search-collector/pkg/config"
+	"github.com/stolostron/search-collector/pkg/informer"
+	"github.com/stolostron/search-collector/pkg/queue"
+	rec "github.com/stolostron/search-collector/pkg/reconciler"
+	"github.com/stolostron/search-collector/pkg/send"
+	"github.com/stolostron/search-collector/pkg/stream"
+	tr "github.com/stolostron/search-collector/pkg/transforms"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Interval to log the stats of the queues between the pipeline stages. The logs are the only place they're
+// published, the collector doesn't serve metrics.
+const queueStatsInterval = time.Minute
+
+// The informers, transformer, reconciler and sender that collect the resources of one cluster.
+// In multi-cluster mode each cluster has its own pipeline, so the backoff when a cluster or the
+// aggregator are unavailable doesn't affect the other clusters.
+type pipeline struct {
+	config            *config.Shared
+	clients           informer.Clients
+	control           *informer.Control
+	reducedProperties *atomic.Bool // Shared with the transformer options.
+	transformer       tr.Transformer
+	reconciler        *rec.Reconciler
+	sender            *send.Sender
+}
+
+// Creates the pipeline for the cluster in cfg. The kubeClient is used to read the search-collector-config
+// ConfigMap in the cluster where the collector runs. The publisher is optional.
+func newPipeline(shared *config.Shared, kubeClient kubernetes.Interface, publisher *stream.Publisher,
+	numThreads int) (*pipeline, error) {
+	cfg := shared.Get()
+
+	kubeConfig, err := config.GetKubeConfig(cfg.KubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error building the kube config for cluster %s: %w", cfg.ClusterName, err)
+	}
+	clients, err := informer.NewClients(kubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error creating the kube clients for cluster %s: %w", cfg.ClusterName, err)
+	}
+	clients.Kube = kubeClient
+
+	// Bounded queues between the informers and the transformer, and between the transformer and the reconciler.
+	// When a stage falls behind, the producers of the resources flooding the queue block, not all of them.
+	transformerQueue := queue.New[*tr.Event](cfg.ClusterName+"/transformer", cfg.QueueCapacity)
+	reconcilerQueue := queue.New[tr.NodeEvent](cfg.ClusterName+"/reconciler", cfg.QueueCapacity)
+
+	// Create transformers
+	reducedProperties := &atomic.Bool{}
+	transformer := tr.NewTransformer(transformerQueue.Out(), make(chan tr.NodeEvent), numThreads,
+		tr.Options{ClusterName: cfg.ClusterName, DeployedInHub: cfg.DeployedInHub, ReducedProperties: reducedProperties})
+	transformer.Queue = transformerQueue
+
+	// Init reconciler
+	reconciler := rec.NewReconciler(rec.Options{})
+	reconciler.Input = reconcilerQueue.Out()
+	reconciler.Queue = reconcilerQueue
+	go func() {
+		for ne := range transformer.Output {
+			reconciler.Enqueue(ne)
+		}
+	}()
+
+	// Create Sender, attached to transformer
+	sender, err := send.NewSender(reconciler, shared)
+	if err != nil {
+		return nil, fmt.Errorf("error creating the sender for cluster %s: %w", cfg.ClusterName, err)
+	}
+	sender.Publisher = publisher
+
+	return &pipeline{
+		config:            shared,
+		clients:           clients,
+		control:           informer.NewControl(),
+		reducedProperties: reducedProperties,
+		transformer:       transformer,
+		reconciler:        reconciler,
+		sender:            sender,
+	}, nil
+}
+
+// Applies config changes to the sender and informers.
+func (p *pipeline) configChanged(changes []config.Change) {
+	p.sender.ConfigChanged(changes)
+	p.control.ConfigChanged(changes)
+}
+
+// Sheds or restores load when the collector resource budget level changes.
+func (p *pipeline) budgetChanged(level budget.Level) {
+	if level >= budget.WidenReportInterval {
+		p.sender.SetReportRateFactor(budget.REPORT_RATE_FACTOR)
+	} else {
+		p.sender.SetReportRateFactor(1)
+	}
+	p.reducedProperties.Store(level >= budget.DropGenericProperties)
+	p.control.PauseLowPriority(level >= budget.PauseLowPriorityInformers)
+}
+
+// Starts the informers and runs the send loop. Doesn't return.
+func (p *pipeline) run() {
+	informersInitialized := make(chan interface{})
+
+	// Log the back-pressure between the pipeline stages.
+	go p.transformer.Queue.RunStats(queueStatsInterval, make(chan struct{}))
+	go p.reconciler.Queue.RunStats(queueStatsInterval, make(chan struct{}))
+
+	// Add the recent Warning events to the nodes of their objects.
+	go informer.RunEventsWatcher(p.config.Get(), p.clients, p.control, p.reconciler)
+
+	// Start a routine to keep our informers up to date.
+	go informer.RunInformers(informersInitialized, p.config, p.clients, p.control, p.transformer, p.reconciler)
+
+	// Wait here until informers have collected the full state of the cluster.
+	// The initial payload must have the complete state to avoid unecessary deletion
+	// and recreate of existing rows in the database during the resync.
+	glog.Infof("Waiting for informers to load initial state of cluster %s.", p.config.Get().ClusterName)
+	<-informersInitialized
+
+	glog.Infof("Starting the sender for cluster %s.", p.config.Get().ClusterName)
+	p.sender.StartSendLoop()
+}
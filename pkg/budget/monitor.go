@@ -0,0 +1,216 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package budget
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/stolostron/search-collector/pkg/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Interval to sample the heap and goroutines.
+const DEFAULT_BUDGET_CHECK_MS = 10000 // 10 seconds
+
+// Multiplies the report rate while the report interval is widened.
+const REPORT_RATE_FACTOR = 4
+
+// Degradation steps, in the order they are applied as usage gets closer to the budget.
+// Each level includes the steps of the levels below it.
+type Level int
+
+const (
+	Normal                    Level = iota
+	WidenReportInterval             // Send changes less often, so they are batched in fewer payloads.
+	DropGenericProperties           // Skip the additional properties from the transform config for generic resources.
+	PauseLowPriorityInformers       // Stop the informers for low priority resources.
+)
+
+// Condition types reported for each degradation step.
+const (
+	ConditionReportIntervalWidened      = "ReportIntervalWidened"
+	ConditionGenericPropertiesDropped   = "GenericPropertiesDropped"
+	ConditionLowPriorityInformersPaused = "LowPriorityInformersPaused"
+)
+
+// Usage, as a fraction of the budget, at which each level is entered.
+// A level is left when the usage goes below its threshold minus the hysteresis.
+var thresholds = map[Level]float64{
+	WidenReportInterval:       0.8,
+	DropGenericProperties:     0.9,
+	PauseLowPriorityInformers: 1.0,
+}
+
+const hysteresis = 0.1
+
+// Usage of the resources with a budget.
+type Usage struct {
+	HeapBytes  uint64
+	Goroutines int
+}
+
+// Monitor samples the heap and goroutines of the collector, and degrades the collection when it gets close to the
+// budget in HEAP_BUDGET_MB or GOROUTINE_BUDGET. This is to shed load, instead of getting OOMKilled and restarting
+// into another full resync. A budget of 0 is not enforced.
+type Monitor struct {
+	cfg        *config.Shared // Read on each check, so budget changes apply without a restart.
+	interval   time.Duration
+	sample     func() Usage // Returns the current usage. Replaced in tests.
+	mutex      sync.Mutex
+	level      Level
+	conditions []metav1.Condition
+	listeners  []func(Level)
+}
+
+// Creates a Monitor that checks the budget in cfg every interval.
+func NewMonitor(cfg *config.Shared, interval time.Duration) *Monitor {
+	m := &Monitor{
+		cfg:      cfg,
+		interval: interval,
+		sample:   currentUsage,
+	}
+	now := metav1.Now()
+	for _, t := range []string{ConditionReportIntervalWidened, ConditionGenericPropertiesDropped,
+		ConditionLowPriorityInformersPaused} {
+		m.conditions = append(m.conditions, metav1.Condition{
+			Type:               t,
+			Status:             metav1.ConditionFalse,
+			Reason:             "WithinBudget",
+			LastTransitionTime: now,
+		})
+	}
+	return m
+}
+
+// Reads the heap and goroutine count of this process.
+func currentUsage() Usage {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return Usage{HeapBytes: mem.HeapAlloc, Goroutines: runtime.NumGoroutine()}
+}
+
+// Registers a function to call with the new level when it changes. Listeners are called from the monitor goroutine.
+func (m *Monitor) OnChange(listener func(Level)) {
+	m.listeners = append(m.listeners, listener)
+}
+
+// Run checks the budget every interval until stopper is closed.
+func (m *Monitor) Run(stopper <-chan struct{}) {
+	glog.Info("Monitoring the collector resource budget.")
+	for {
+		m.check()
+		select {
+		case <-stopper:
+			return
+		case <-time.After(m.interval):
+		}
+	}
+}
+
+// Returns the current degradation level.
+func (m *Monitor) Level() Level {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.level
+}
+
+// Returns the status conditions for each degradation step.
+func (m *Monitor) Conditions() []metav1.Condition {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	ret := make([]metav1.Condition, len(m.conditions))
+	copy(ret, m.conditions)
+	return ret
+}
+
+// Samples the usage and updates the level.
+func (m *Monitor) check() {
+	usage := m.sample()
+	ratio, reason := m.usageRatio(usage)
+	glog.V(3).Infof("Resource budget check. heap=%dMB goroutines=%d usage=%.2f", usage.HeapBytes/(1024*1024),
+		usage.Goroutines, ratio)
+
+	m.mutex.Lock()
+	level := nextLevel(m.level, ratio)
+	if level == m.level {
+		m.mutex.Unlock()
+		return
+	}
+	glog.Warningf("Collector resource budget level changed from %d to %d. %s", m.level, level, reason)
+	m.level = level
+	m.updateConditions(level, reason)
+	m.mutex.Unlock()
+
+	for _, listener := range m.listeners {
+		listener(level)
+	}
+}
+
+// Returns the usage as a fraction of the budget, using the resource closest to its budget.
+func (m *Monitor) usageRatio(usage Usage) (float64, string) {
+	ratio := 0.0
+	reason := "Usage is within the budget."
+	cfg := m.cfg.Get()
+	if cfg.HeapBudgetMB > 0 {
+		heapMB := float64(usage.HeapBytes) / (1024 * 1024)
+		ratio = heapMB / float64(cfg.HeapBudgetMB)
+		reason = fmt.Sprintf("Heap is %.0fMB of the %dMB budget.", heapMB, cfg.HeapBudgetMB)
+	}
+	if cfg.GoroutineBudget > 0 {
+		if r := float64(usage.Goroutines) / float64(cfg.GoroutineBudget); r > ratio {
+			ratio = r
+			reason = fmt.Sprintf("Goroutines are %d of the %d budget.", usage.Goroutines, cfg.GoroutineBudget)
+		}
+	}
+	return ratio, reason
+}
+
+// Goes up to the highest level whose threshold is reached. Goes down one level at a time, once the usage is below
+// the threshold of the current level minus the hysteresis, so the collection doesn't flap around a threshold.
+func nextLevel(current Level, ratio float64) Level {
+	next := current
+	for l := current + 1; l <= PauseLowPriorityInformers; l++ {
+		if ratio >= thresholds[l] {
+			next = l
+		}
+	}
+	if next == current && current > Normal && ratio < thresholds[current]-hysteresis {
+		next = current - 1
+	}
+	return next
+}
+
+// Sets the condition for each degradation step included in level.
+func (m *Monitor) updateConditions(level Level, message string) {
+	now := metav1.Now()
+	for i := range m.conditions {
+		status := metav1.ConditionFalse
+		reason := "WithinBudget"
+		if level >= conditionLevel(m.conditions[i].Type) {
+			status = metav1.ConditionTrue
+			reason = "NearBudget"
+		}
+		if m.conditions[i].Status != status {
+			m.conditions[i].LastTransitionTime = now
+		}
+		m.conditions[i].Status = status
+		m.conditions[i].Reason = reason
+		m.conditions[i].Message = message
+	}
+}
+
+// Returns the level at which a condition becomes true.
+func conditionLevel(conditionType string) Level {
+	switch conditionType {
+	case ConditionReportIntervalWidened:
+		return WidenReportInterval
+	case ConditionGenericPropertiesDropped:
+		return DropGenericProperties
+	default:
+		return PauseLowPriorityInformers
+	}
+}
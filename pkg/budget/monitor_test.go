@@ -0,0 +1,85 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package budget
+
+import (
+	"testing"
+
+	"github.com/stolostron/search-collector/pkg/config"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Returns a monitor with a 100MB heap budget and 1000 goroutines budget, and a function to set the usage.
+func initTestMonitor() (*Monitor, func(heapMB uint64, goroutines int)) {
+	m := NewMonitor(config.NewShared(&config.Config{HeapBudgetMB: 100, GoroutineBudget: 1000}), 0)
+	usage := Usage{}
+	m.sample = func() Usage { return usage }
+	return m, func(heapMB uint64, goroutines int) {
+		usage = Usage{HeapBytes: heapMB * 1024 * 1024, Goroutines: goroutines}
+	}
+}
+
+func conditionStatus(m *Monitor, conditionType string) metav1.ConditionStatus {
+	for _, c := range m.Conditions() {
+		if c.Type == conditionType {
+			return c.Status
+		}
+	}
+	return metav1.ConditionUnknown
+}
+
+func Test_nextLevel(t *testing.T) {
+	assert.Equal(t, Normal, nextLevel(Normal, 0.5))
+	assert.Equal(t, WidenReportInterval, nextLevel(Normal, 0.85))
+	assert.Equal(t, PauseLowPriorityInformers, nextLevel(Normal, 1.2), "Should skip levels when usage jumps")
+
+	// Hysteresis: stays at the level until usage is 0.1 below its threshold, then goes down one level.
+	assert.Equal(t, PauseLowPriorityInformers, nextLevel(PauseLowPriorityInformers, 0.95))
+	assert.Equal(t, DropGenericProperties, nextLevel(PauseLowPriorityInformers, 0.85))
+	assert.Equal(t, DropGenericProperties, nextLevel(DropGenericProperties, 0.85))
+	assert.Equal(t, WidenReportInterval, nextLevel(DropGenericProperties, 0.5))
+}
+
+func TestMonitorDegradesAndRecovers(t *testing.T) {
+	m, setUsage := initTestMonitor()
+	levels := []Level{}
+	m.OnChange(func(l Level) { levels = append(levels, l) })
+
+	setUsage(50, 100)
+	m.check()
+	assert.Equal(t, Normal, m.Level())
+	assert.Empty(t, levels, "Should not notify if the level didn't change")
+
+	setUsage(50, 950) // Goroutines are at 95% of the budget.
+	m.check()
+	assert.Equal(t, DropGenericProperties, m.Level())
+	assert.Equal(t, metav1.ConditionTrue, conditionStatus(m, ConditionReportIntervalWidened))
+	assert.Equal(t, metav1.ConditionTrue, conditionStatus(m, ConditionGenericPropertiesDropped))
+	assert.Equal(t, metav1.ConditionFalse, conditionStatus(m, ConditionLowPriorityInformersPaused))
+
+	setUsage(120, 100) // Heap is over the budget.
+	m.check()
+	assert.Equal(t, PauseLowPriorityInformers, m.Level())
+	assert.Equal(t, metav1.ConditionTrue, conditionStatus(m, ConditionLowPriorityInformersPaused))
+
+	setUsage(10, 100)
+	m.check()
+	m.check()
+	m.check()
+	assert.Equal(t, Normal, m.Level())
+	assert.Equal(t, metav1.ConditionFalse, conditionStatus(m, ConditionReportIntervalWidened))
+	assert.Equal(t, []Level{DropGenericProperties, PauseLowPriorityInformers, DropGenericProperties,
+		WidenReportInterval, Normal}, levels)
+}
+
+func TestMonitorDisabled(t *testing.T) {
+	m, setUsage := initTestMonitor()
+	m.cfg.Apply([]config.Change{{Field: "HeapBudgetMB", New: 0, Applied: true},
+		{Field: "GoroutineBudget", New: 0, Applied: true}})
+
+	setUsage(10000, 100000)
+	m.check()
+
+	assert.Equal(t, Normal, m.Level())
+}
@@ -0,0 +1,105 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/golang/glog"
+)
+
+// A cluster to collect from in multi-cluster mode.
+type ClusterConfig struct {
+	Name       string // Name of the cluster. Used as prefix for the UIDs and to send to the aggregator.
+	KubeConfig string // Path to the kubeconfig to connect to the cluster.
+}
+
+// Reads the clusters for multi-cluster mode from the env. The format is a comma separated list of
+// name=kubeconfig pairs, for example: CLUSTERS=edge-1=/kube/edge-1.yaml,edge-2=/kube/edge-2.yaml
+// Clusters can also be set as a JSON list of {"Name": "edge-1", "KubeConfig": "/kube/edge-1.yaml"},
+// in the env or in config.json.
+func (s settings) setClusters(field *[]ClusterConfig, env string) error {
+	val, _ := s.lookup(env)
+	if val == "" {
+		return validateClusters(*field)
+	}
+	glog.Infof("Using %s from environment: %s", env, val)
+	clusters, err := parseClusters(val)
+	if err != nil {
+		return err
+	}
+	*field = clusters
+	return nil
+}
+
+// Parses a JSON list or a comma separated list of name=kubeconfig pairs.
+func parseClusters(val string) ([]ClusterConfig, error) {
+	clusters := []ClusterConfig{}
+	if strings.HasPrefix(strings.TrimSpace(val), "[") {
+		if err := json.Unmarshal([]byte(val), &clusters); err != nil {
+			return nil, fmt.Errorf("error parsing CLUSTERS. Original error: %w", err)
+		}
+		return clusters, validateClusters(clusters)
+	}
+	for _, pair := range strings.Split(val, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, kubeConfig, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid CLUSTERS entry [%s], expected name=kubeconfig", pair)
+		}
+		clusters = append(clusters, ClusterConfig{
+			Name:       strings.TrimSpace(name),
+			KubeConfig: strings.TrimSpace(kubeConfig),
+		})
+	}
+	return clusters, validateClusters(clusters)
+}
+
+// Returns an error if a cluster is missing the name or kubeconfig, or the name is used more than once.
+func validateClusters(clusters []ClusterConfig) error {
+	names := make(map[string]struct{}, len(clusters))
+	for _, c := range clusters {
+		if c.Name == "" || c.KubeConfig == "" {
+			return fmt.Errorf("invalid cluster [%s=%s], name and kubeconfig are required", c.Name, c.KubeConfig)
+		}
+		if _, ok := names[c.Name]; ok {
+			return fmt.Errorf("cluster [%s] is configured more than once", c.Name)
+		}
+		names[c.Name] = struct{}{}
+	}
+	return nil
+}
+
+// Returns true if the collector is configured to collect from multiple clusters.
+func (cfg *Config) MultiCluster() bool {
+	return len(cfg.Clusters) > 0
+}
+
+// Returns a config for each cluster to collect from. Without multi-cluster mode, returns this config.
+// Each cluster config is a copy with the cluster name and kubeconfig from the ClusterConfig, so each
+// pipeline can be configured independently. Use Shared.Apply to propagate live changes to the copies.
+func (cfg *Config) ForClusters() ([]*Config, error) {
+	if !cfg.MultiCluster() {
+		return []*Config{cfg}, nil
+	}
+	configs := make([]*Config, 0, len(cfg.Clusters))
+	for _, c := range cfg.Clusters {
+		clusterCfg := *cfg
+		clusterCfg.ClusterName = c.Name
+		clusterCfg.KubeConfig = c.KubeConfig
+		clusterCfg.Clusters = nil
+		if clusterCfg.AggregatorConfigFile != "" {
+			// The aggregator URL on the hub includes the cluster name.
+			if err := clusterCfg.ReloadHubConfig(); err != nil {
+				return nil, err
+			}
+		}
+		configs = append(configs, &clusterCfg)
+	}
+	return configs, nil
+}
@@ -0,0 +1,71 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseClusters(t *testing.T) {
+	clusters, err := parseClusters("edge-1=/kube/edge-1.yaml, edge-2=/kube/edge-2.yaml")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []ClusterConfig{
+		{Name: "edge-1", KubeConfig: "/kube/edge-1.yaml"},
+		{Name: "edge-2", KubeConfig: "/kube/edge-2.yaml"},
+	}, clusters)
+}
+
+func Test_parseClusters_json(t *testing.T) {
+	clusters, err := parseClusters(`[{"Name": "edge-1", "KubeConfig": "/kube/edge-1.yaml"}]`)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []ClusterConfig{{Name: "edge-1", KubeConfig: "/kube/edge-1.yaml"}}, clusters)
+}
+
+func Test_parseClusters_invalid(t *testing.T) {
+	for _, val := range []string{
+		"edge-1",             // Missing kubeconfig.
+		"=/kube/edge-1.yaml", // Missing name.
+		"edge-1=/kube/edge-1.yaml,edge-1=/kube/b", // Duplicate name.
+	} {
+		_, err := parseClusters(val)
+		assert.NotNil(t, err, val)
+	}
+}
+
+func Test_Load_clusters(t *testing.T) {
+	t.Setenv("DEPLOYED_IN_HUB", "true")
+	t.Setenv("CLUSTERS", "edge-1=/kube/edge-1.yaml,edge-2=/kube/edge-2.yaml")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	configs, err := cfg.ForClusters()
+
+	assert.Nil(t, err)
+	assert.True(t, cfg.MultiCluster())
+	assert.Len(t, configs, 2)
+	assert.Equal(t, "edge-1", configs[0].ClusterName)
+	assert.Equal(t, "/kube/edge-1.yaml", configs[0].KubeConfig)
+	assert.Equal(t, "edge-2", configs[1].ClusterName)
+	assert.Empty(t, configs[1].Clusters)
+
+	// Each cluster has its own copy, live changes are propagated with Apply.
+	shared := []*Shared{NewShared(configs[0]), NewShared(configs[1])}
+	shared[0].Apply([]Change{{Field: "ReportRateMS", New: 1000, Applied: true}})
+	assert.Equal(t, 1000, shared[0].Get().ReportRateMS)
+	assert.Equal(t, DEFAULT_REPORT_RATE_MS, shared[1].Get().ReportRateMS)
+}
+
+func Test_ForClusters_single(t *testing.T) {
+	cfg := &Config{ClusterName: "local-cluster"}
+
+	configs, err := cfg.ForClusters()
+
+	assert.Nil(t, err)
+	assert.Equal(t, []*Config{cfg}, configs)
+}
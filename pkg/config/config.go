@@ -11,12 +11,14 @@ Copyright (c) 2020, 2021 Red Hat, Inc.
 package config
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"net"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/golang/glog"
 	"github.com/tkanos/gonfig"
@@ -31,47 +33,110 @@ const (
 	DEFAULT_AGGREGATOR_HOST    = "https://localhost"
 	DEFAULT_AGGREGATOR_PORT    = "3010"
 	DEFAULT_CLUSTER_NAME       = "local-cluster"
+	DEFAULT_EVENTS_TTL_MS      = 3600000 // 1 hour. Time a Warning event is kept on the node of its object.
 	DEFAULT_POD_NAMESPACE      = "open-cluster-management"
+	DEFAULT_QUEUE_CAPACITY     = 100    // Events per resource buffered between the informers, transformer and reconciler
 	DEFAULT_HEARTBEAT_MS       = 300000 // 5 min
 	DEFAULT_MAX_BACKOFF_MS     = 600000 // 10 min
 	DEFAULT_REDISCOVER_RATE_MS = 120000 // 2 min
 	DEFAULT_REPORT_RATE_MS     = 5000   // 5 seconds
 	DEFAULT_RETRY_JITTER_MS    = 5000   // 5 seconds
 	DEFAULT_RUNTIME_MODE       = "production"
+	DEFAULT_TLS_CA_FILE        = "./sslcert/tls.crt"
+	DEFAULT_TLS_CERT_FILE      = "./sslcert/tls.crt"
+	DEFAULT_TLS_KEY_FILE       = "./sslcert/tls.key"
+	DEFAULT_TLS_MIN_VERSION    = "1.2"
+	DEFAULT_TLS_CIPHER_PROFILE = "intermediate"
+	// Resources whose informers start first, in the resource.group format.
+	DEFAULT_INFORMER_PRIORITY = "namespaces,nodes,pods,deployments.apps,replicasets.apps,services," +
+		"persistentvolumeclaims,persistentvolumes,applications.app.k8s.io," +
+		"channels.apps.open-cluster-management.io,subscriptions.apps.open-cluster-management.io," +
+		"placementrules.apps.open-cluster-management.io,helmreleases.apps.open-cluster-management.io," +
+		"applications.argoproj.io,placements.cluster.open-cluster-management.io"
+	// Minimum interval between updates of the same resource, for resources that update every few seconds.
+	DEFAULT_MIN_UPDATE_INTERVALS = "leases.coordination.k8s.io=60s,endpoints=30s," +
+		"endpointslices.discovery.k8s.io=30s,configmaps=10s"
 )
 
 // Configuration options for the search-collector.
 type Config struct {
-	AggregatorConfig     *rest.Config // Config object for hub. Used to get TLS credentials.
-	AggregatorConfigFile string       `env:"HUB_CONFIG"`         // Config file for hub. Will be mounted in a secret.
-	AggregatorURL        string       `env:"AGGREGATOR_URL"`     // URL of the Aggregator, includes port but not any path
-	AggregatorHost       string       `env:"AGGREGATOR_HOST"`    // Host of the Aggregator
-	AggregatorPort       string       `env:"AGGREGATOR_PORT"`    // Port of the Aggregator
-	ClusterName          string       `env:"CLUSTER_NAME"`       // The name of of the cluster where this pod is running
-	PodNamespace         string       `env:"POD_NAMESPACE"`      // The namespace of this pod
-	DeployedInHub        bool         `env:"DEPLOYED_IN_HUB"`    // Tracks if deployed in the Hub or Managed cluster
-	HeartbeatMS          int          `env:"HEARTBEAT_MS"`       // Interval(ms) to send empty payload to ensure connection
-	KubeConfig           string       `env:"KUBECONFIG"`         // Local kubeconfig path
-	MaxBackoffMS         int          `env:"MAX_BACKOFF_MS"`     // Maximum backoff in ms to wait after error
-	RediscoverRateMS     int          `env:"REDISCOVER_RATE_MS"` // Interval(ms) to poll for changes to CRDs
-	RetryJitterMS        int          `env:"RETRY_JITTER_MS"`    // Random jitter added to backoff wait.
-	ReportRateMS         int          `env:"REPORT_RATE_MS"`     // Interval(ms) to send changes to the aggregator
-	RuntimeMode          string       `env:"RUNTIME_MODE"`       // Running mode (development or production)
+	AggregatorConfig     *rest.Config    // Config object for hub. Used to get TLS credentials.
+	AggregatorConfigFile string          `env:"HUB_CONFIG"`           // Config file for hub. Will be mounted in a secret.
+	AggregatorURL        string          `env:"AGGREGATOR_URL"`       // URL of the Aggregator, includes port but not any path
+	AggregatorHost       string          `env:"AGGREGATOR_HOST"`      // Host of the Aggregator
+	AggregatorPort       string          `env:"AGGREGATOR_PORT"`      // Port of the Aggregator
+	ClusterName          string          `env:"CLUSTER_NAME"`         // The name of of the cluster where this pod is running
+	Clusters             []ClusterConfig `env:"CLUSTERS"`             // Clusters to collect from in multi-cluster mode
+	PodNamespace         string          `env:"POD_NAMESPACE"`        // The namespace of this pod
+	DeployedInHub        bool            `env:"DEPLOYED_IN_HUB"`      // Tracks if deployed in the Hub or Managed cluster
+	EventStreamFile      string          `env:"EVENT_STREAM_FILE"`    // File to publish node and edge changes. Disabled if empty.
+	EventsTTLMS          int             `env:"EVENTS_TTL_MS"`        // Time(ms) to keep Warning events on their object
+	GoroutineBudget      int             `env:"GOROUTINE_BUDGET"`     // Goroutines before shedding load. 0 to disable
+	HeapBudgetMB         int             `env:"HEAP_BUDGET_MB"`       // Heap(MB) before shedding load. 0 to disable
+	HeartbeatMS          int             `env:"HEARTBEAT_MS"`         // Interval(ms) to send empty payload to ensure connection
+	InformerPriority     string          `env:"INFORMER_PRIORITY"`    // Resources to collect first. Comma separated resource.group
+	KubeConfig           string          `env:"KUBECONFIG"`           // Local kubeconfig path
+	MinUpdateIntervals   string          `env:"MIN_UPDATE_INTERVALS"` // Rate-limit updates. Comma separated resource.group=duration
+	MaxBackoffMS         int             `env:"MAX_BACKOFF_MS"`       // Maximum backoff in ms to wait after error
+	QueueCapacity        int             `env:"QUEUE_CAPACITY"`       // Events per resource buffered between pipeline stages
+	RediscoverRateMS     int             `env:"REDISCOVER_RATE_MS"`   // Interval(ms) to poll for changes to CRDs
+	RetryJitterMS        int             `env:"RETRY_JITTER_MS"`      // Random jitter added to backoff wait.
+	ReportRateMS         int             `env:"REPORT_RATE_MS"`       // Interval(ms) to send changes to the aggregator
+	ProjectedConfigDir   string          `env:"PROJECTED_CONFIG_DIR"` // Directory with settings projected as files
+	RuntimeMode          string          `env:"RUNTIME_MODE"`         // Running mode (development or production)
+	TLSCAFile            string          `env:"TLS_CA_FILE"`          // CA bundle used to verify the aggregator (hub only)
+	TLSCertFile          string          `env:"TLS_CERT_FILE"`        // Client certificate (hub only)
+	TLSKeyFile           string          `env:"TLS_KEY_FILE"`         // Client certificate key (hub only)
+	TLSMinVersion        string          `env:"TLS_MIN_VERSION"`      // Minimum TLS version, 1.2 or 1.3
+	TLSCipherProfile     string          `env:"TLS_CIPHER_PROFILE"`   // Cipher suites to allow: intermediate or modern
+	TLSStrict            bool            `env:"TLS_STRICT"`           // Refuse to start without verifying the aggregator
 }
 
-var Cfg = Config{}
 var FilePath = flag.String("c", "./config.json", "Collector configuration file") // ./config.json is the default
 
-func init() {
-	InitConfig()
+// Load reads the config from the environment, ./config.json and the default values.
+// Returns an error if the config is not valid.
+func Load() (*Config, error) {
+	cfg, err := loadConfig(newSettings(nil))
+	if err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Where we read the settings from. The order of preference is:
+// projected file -> env -> search-collector-config ConfigMap -> config.json -> default constants
+type settings struct {
+	dir       string            // Directory with settings projected as files, named like the env variable.
+	configMap map[string]string // Data from the search-collector-config ConfigMap.
+}
+
+func newSettings(configMap map[string]string) settings {
+	return settings{dir: os.Getenv("PROJECTED_CONFIG_DIR"), configMap: configMap}
 }
 
-func InitConfig() {
+// Returns the value of a setting and whether it was found.
+func (s settings) lookup(env string) (string, bool) {
+	if s.dir != "" && env != "" {
+		if val, err := os.ReadFile(filepath.Join(s.dir, env)); err == nil {
+			return strings.TrimSpace(string(val)), true
+		}
+	}
+	if val, ok := os.LookupEnv(env); ok {
+		return val, true
+	}
+	val, ok := s.configMap[env]
+	return val, ok
+}
+
+// Reads the config from all the sources.
+func loadConfig(s settings) (Config, error) {
+	cfg := Config{}
 	glog.Info("Loading config from environment.")
 	// Load default config from ./config.json.
 	// These can be overridden in the next step if environment variables are set.
 	if _, err := os.Stat(filepath.Join(".", "config.json")); !os.IsNotExist(err) {
-		err = gonfig.GetConf(*FilePath, &Cfg)
+		err = gonfig.GetConf(*FilePath, &cfg)
 		if err != nil {
 			fmt.Println("Error reading config file:", err) // Uses fmt.Println in case something is wrong with glog
 		}
@@ -82,75 +147,112 @@ func InitConfig() {
 
 	// If environment variables are set, use those values instead of ./config.json
 	// Simply put, the order of preference is env -> config.json -> default constants (from left to right)
-	setDefault(&Cfg.RuntimeMode, "RUNTIME_MODE", DEFAULT_RUNTIME_MODE)
-	setDefault(&Cfg.ClusterName, "CLUSTER_NAME", DEFAULT_CLUSTER_NAME)
-	setDefault(&Cfg.PodNamespace, "POD_NAMESPACE", DEFAULT_POD_NAMESPACE)
+	s.setDefault(&cfg.RuntimeMode, "RUNTIME_MODE", DEFAULT_RUNTIME_MODE)
+	s.setDefault(&cfg.ClusterName, "CLUSTER_NAME", DEFAULT_CLUSTER_NAME)
+	s.setDefault(&cfg.PodNamespace, "POD_NAMESPACE", DEFAULT_POD_NAMESPACE)
+	s.setDefault(&cfg.EventStreamFile, "EVENT_STREAM_FILE", "")
+	s.setDefault(&cfg.ProjectedConfigDir, "PROJECTED_CONFIG_DIR", "")
 
-	setDefault(&Cfg.AggregatorHost, "AGGREGATOR_HOST", DEFAULT_AGGREGATOR_HOST)
-	setDefault(&Cfg.AggregatorPort, "AGGREGATOR_PORT", DEFAULT_AGGREGATOR_PORT)
-	aggHost, aggHostPresent := os.LookupEnv("AGGREGATOR_HOST")
-	aggPort, aggPortPresent := os.LookupEnv("AGGREGATOR_PORT")
+	s.setDefault(&cfg.AggregatorHost, "AGGREGATOR_HOST", DEFAULT_AGGREGATOR_HOST)
+	s.setDefault(&cfg.AggregatorPort, "AGGREGATOR_PORT", DEFAULT_AGGREGATOR_PORT)
+	aggHost, aggHostPresent := s.lookup("AGGREGATOR_HOST")
+	aggPort, aggPortPresent := s.lookup("AGGREGATOR_PORT")
 
 	//If environment variables are set for aggregator host and port, use those to set the AggregatorURL
 	if aggHostPresent && aggPortPresent && aggHost != "" && aggPort != "" {
-		Cfg.AggregatorURL = net.JoinHostPort(aggHost, aggPort)
-		setDefault(&Cfg.AggregatorURL, "", net.JoinHostPort(DEFAULT_AGGREGATOR_HOST, DEFAULT_AGGREGATOR_PORT))
+		cfg.AggregatorURL = net.JoinHostPort(aggHost, aggPort)
+		s.setDefault(&cfg.AggregatorURL, "", net.JoinHostPort(DEFAULT_AGGREGATOR_HOST, DEFAULT_AGGREGATOR_PORT))
 	} else { // Else use the default AggregatorURL
-		setDefault(&Cfg.AggregatorURL, "AGGREGATOR_URL", DEFAULT_AGGREGATOR_URL)
+		s.setDefault(&cfg.AggregatorURL, "AGGREGATOR_URL", DEFAULT_AGGREGATOR_URL)
 	}
 
-	setDefaultInt(&Cfg.HeartbeatMS, "HEARTBEAT_MS", DEFAULT_HEARTBEAT_MS)
-	setDefaultInt(&Cfg.MaxBackoffMS, "MAX_BACKOFF_MS", DEFAULT_MAX_BACKOFF_MS)
-	setDefaultInt(&Cfg.RediscoverRateMS, "REDISCOVER_RATE_MS", DEFAULT_REDISCOVER_RATE_MS)
-	setDefaultInt(&Cfg.ReportRateMS, "REPORT_RATE_MS", DEFAULT_REPORT_RATE_MS)
-	setDefaultInt(&Cfg.RetryJitterMS, "RETRY_JITTER_MS", DEFAULT_RETRY_JITTER_MS)
+	s.setDefaultInt(&cfg.EventsTTLMS, "EVENTS_TTL_MS", DEFAULT_EVENTS_TTL_MS)
+	s.setDefaultInt(&cfg.HeartbeatMS, "HEARTBEAT_MS", DEFAULT_HEARTBEAT_MS)
+	s.setDefaultInt(&cfg.HeapBudgetMB, "HEAP_BUDGET_MB", 0)
+	s.setDefaultInt(&cfg.GoroutineBudget, "GOROUTINE_BUDGET", 0)
+	s.setDefaultInt(&cfg.MaxBackoffMS, "MAX_BACKOFF_MS", DEFAULT_MAX_BACKOFF_MS)
+	s.setDefaultInt(&cfg.QueueCapacity, "QUEUE_CAPACITY", DEFAULT_QUEUE_CAPACITY)
+	s.setDefaultInt(&cfg.RediscoverRateMS, "REDISCOVER_RATE_MS", DEFAULT_REDISCOVER_RATE_MS)
+	s.setDefaultInt(&cfg.ReportRateMS, "REPORT_RATE_MS", DEFAULT_REPORT_RATE_MS)
+	s.setDefaultInt(&cfg.RetryJitterMS, "RETRY_JITTER_MS", DEFAULT_RETRY_JITTER_MS)
+
+	s.setDefault(&cfg.TLSCAFile, "TLS_CA_FILE", DEFAULT_TLS_CA_FILE)
+	s.setDefault(&cfg.TLSCertFile, "TLS_CERT_FILE", DEFAULT_TLS_CERT_FILE)
+	s.setDefault(&cfg.TLSKeyFile, "TLS_KEY_FILE", DEFAULT_TLS_KEY_FILE)
+	s.setDefault(&cfg.TLSMinVersion, "TLS_MIN_VERSION", DEFAULT_TLS_MIN_VERSION)
+	s.setDefault(&cfg.TLSCipherProfile, "TLS_CIPHER_PROFILE", DEFAULT_TLS_CIPHER_PROFILE)
+	s.setDefaultBool(&cfg.TLSStrict, "TLS_STRICT")
 
 	defaultKubePath := filepath.Join(os.Getenv("HOME"), ".kube", "config")
 	if _, err := os.Stat(defaultKubePath); os.IsNotExist(err) {
 		// set default to empty string if path does not reslove
 		defaultKubePath = ""
 	}
-	setDefault(&Cfg.KubeConfig, "KUBECONFIG", defaultKubePath)
+	s.setDefault(&cfg.KubeConfig, "KUBECONFIG", defaultKubePath)
+	s.setDefault(&cfg.InformerPriority, "INFORMER_PRIORITY", DEFAULT_INFORMER_PRIORITY)
+	s.setDefault(&cfg.MinUpdateIntervals, "MIN_UPDATE_INTERVALS", DEFAULT_MIN_UPDATE_INTERVALS)
+	if err := s.setClusters(&cfg.Clusters, "CLUSTERS"); err != nil {
+		return cfg, err
+	}
 
 	// Special logic for setting DEPLOYED_IN_HUB with default to false
-	if val := os.Getenv("DEPLOYED_IN_HUB"); val != "" {
+	if val, _ := s.lookup("DEPLOYED_IN_HUB"); val != "" {
 		glog.Infof("Using DEPLOYED_IN_HUB from environment: %s", val)
 		var err error
-		Cfg.DeployedInHub, err = strconv.ParseBool(val)
+		cfg.DeployedInHub, err = strconv.ParseBool(val)
 		if err != nil {
 			glog.Error("Error parsing env DEPLOYED_IN_HUB.  Expected a bool.  Original error: ", err)
 			glog.Info("Leaving flag unchanged, assuming it is a Klusterlet")
 		}
-	} else if !Cfg.DeployedInHub {
+	} else if !cfg.DeployedInHub {
 		glog.Info("No DEPLOY_IN_HUB from file or environment, assuming it is a Klusterlet")
 	}
-	setDefault(&Cfg.AggregatorConfigFile, "HUB_CONFIG", "")
+	s.setDefault(&cfg.AggregatorConfigFile, "HUB_CONFIG", "")
 
-	if Cfg.DeployedInHub && Cfg.AggregatorConfigFile != "" {
-		glog.Fatal("Config mismatch: DEPLOYED_IN_HUB is true, but HUB_CONFIG is set to connect to another hub")
-	} else if !Cfg.DeployedInHub && Cfg.AggregatorConfigFile == "" {
-		glog.Fatal("Config mismatch: DEPLOYED_IN_HUB is false, but no HUB_CONFIG is set to connect to another hub")
+	if cfg.DeployedInHub && cfg.AggregatorConfigFile != "" {
+		return cfg, errors.New(
+			"Config mismatch: DEPLOYED_IN_HUB is true, but HUB_CONFIG is set to connect to another hub")
+	} else if !cfg.DeployedInHub && cfg.AggregatorConfigFile == "" {
+		return cfg, errors.New(
+			"Config mismatch: DEPLOYED_IN_HUB is false, but no HUB_CONFIG is set to connect to another hub")
 	}
 
-	if Cfg.AggregatorConfigFile != "" {
-		hubConfig, err := clientcmd.BuildConfigFromFlags("", Cfg.AggregatorConfigFile)
-		if err != nil {
-			glog.Error("Error building K8s client from config file [", Cfg.AggregatorConfigFile, "]. Original error: ",
-				err)
+	if cfg.AggregatorConfigFile != "" {
+		if err := cfg.ReloadHubConfig(); err != nil {
+			return cfg, err
 		}
+		glog.Info("Running inside klusterlet. Aggregator URL: ", cfg.AggregatorURL)
+	}
+	return cfg, nil
+}
 
-		Cfg.AggregatorURL = hubConfig.Host + "/apis/proxy.open-cluster-management.io/v1beta1/namespaces/" +
-			Cfg.ClusterName + "/clusterstatuses"
-		Cfg.AggregatorConfig = hubConfig
-
-		glog.Info("Running inside klusterlet. Aggregator URL: ", Cfg.AggregatorURL)
+// Rebuilds the hub client config and the aggregator URL from HUB_CONFIG, to pick up rotated credentials.
+// Modifies cfg, so it's only used before the config is shared. Use Shared.ReloadHubConfig after that.
+func (cfg *Config) ReloadHubConfig() error {
+	hubConfig, err := clientcmd.BuildConfigFromFlags("", cfg.AggregatorConfigFile)
+	if err != nil {
+		return fmt.Errorf("error building K8s client from config file [%s]. Original error: %w",
+			cfg.AggregatorConfigFile, err)
 	}
+
+	cfg.AggregatorURL = hubConfig.Host + "/apis/proxy.open-cluster-management.io/v1beta1/namespaces/" +
+		cfg.ClusterName + "/clusterstatuses"
+	cfg.AggregatorConfig = hubConfig
+	return nil
 }
 
 // Sets config field to perfer the env over config file
 // If no config or env set to the default value
 func setDefault(field *string, env, defaultVal string) {
-	if val := os.Getenv(env); val != "" {
+	settings{}.setDefault(field, env, defaultVal)
+}
+
+func setDefaultInt(field *int, env string, defaultVal int) {
+	settings{}.setDefaultInt(field, env, defaultVal)
+}
+
+func (s settings) setDefault(field *string, env, defaultVal string) {
+	if val, _ := s.lookup(env); val != "" {
 		glog.Infof("Using %s from environment: %s", env, val)
 		*field = val
 	} else if *field == "" && defaultVal != "" {
@@ -159,8 +261,8 @@ func setDefault(field *string, env, defaultVal string) {
 	}
 }
 
-func setDefaultInt(field *int, env string, defaultVal int) {
-	if val := os.Getenv(env); val != "" {
+func (s settings) setDefaultInt(field *int, env string, defaultVal int) {
+	if val, _ := s.lookup(env); val != "" {
 		glog.Infof("Using %s from environment: %s", env, val)
 		var err error
 		*field, err = strconv.Atoi(val)
@@ -172,3 +274,16 @@ func setDefaultInt(field *int, env string, defaultVal int) {
 		*field = defaultVal
 	}
 }
+
+// Sets a bool config field from the env. Leaves the value from the config file (or false) if not set.
+func (s settings) setDefaultBool(field *bool, env string) {
+	if val, _ := s.lookup(env); val != "" {
+		glog.Infof("Using %s from environment: %s", env, val)
+		parsed, err := strconv.ParseBool(val)
+		if err != nil {
+			glog.Error("Error parsing env [", env, "].  Expected a bool.  Original error: ", err)
+			return
+		}
+		*field = parsed
+	}
+}
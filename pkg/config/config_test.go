@@ -52,3 +52,29 @@ func Test_SetDefaultInt_02(t *testing.T) {
 		t.Errorf("Failed testing setDefault() Expected: %d  Got: %d", 9999, property)
 	}
 }
+
+// Should return an error instead of exiting when the config is not valid.
+func Test_Load_mismatch(t *testing.T) {
+	t.Setenv("DEPLOYED_IN_HUB", "false")
+	t.Setenv("HUB_CONFIG", "")
+
+	cfg, err := Load()
+
+	if err == nil || cfg != nil {
+		t.Errorf("Failed testing Load() Expected an error for a klusterlet without HUB_CONFIG. Got: %v", cfg)
+	}
+}
+
+// Should load the config with default values.
+func Test_Load_hub(t *testing.T) {
+	t.Setenv("DEPLOYED_IN_HUB", "true")
+
+	cfg, err := Load()
+
+	if err != nil {
+		t.Fatalf("Failed testing Load() Unexpected error: %v", err)
+	}
+	if cfg.ReportRateMS != DEFAULT_REPORT_RATE_MS {
+		t.Errorf("Failed testing Load() Expected: %d  Got: %d", DEFAULT_REPORT_RATE_MS, cfg.ReportRateMS)
+	}
+}
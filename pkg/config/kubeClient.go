@@ -4,7 +4,8 @@
 package config
 
 import (
-	"sync"
+	"errors"
+	"fmt"
 
 	"github.com/golang/glog"
 	"k8s.io/client-go/discovery"
@@ -14,63 +15,52 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 )
 
-var mutex sync.Mutex
-var dynamicClient dynamic.Interface
-
 // Get the kubernetes dynamic client.
-func GetDynamicClient() dynamic.Interface {
-	mutex.Lock()
-	defer mutex.Unlock()
-	if dynamicClient != nil {
-		return dynamicClient
-	}
-	newDynamicClient, err := dynamic.NewForConfig(GetKubeConfig())
+func GetDynamicClient(config *rest.Config) (dynamic.Interface, error) {
+	dynamicClient, err := dynamic.NewForConfig(config)
 	if err != nil {
-		glog.Fatal("Cannot Construct Dynamic Client ", err)
+		return nil, fmt.Errorf("cannot construct dynamic client: %w", err)
 	}
-	dynamicClient = newDynamicClient
-
-	return dynamicClient
+	return dynamicClient, nil
 }
 
-func GetKubeConfig() *rest.Config {
+// Returns the config to connect to the cluster where the collector runs.
+// Uses the kubeconfig at kubeConfigPath, or the in-cluster config if the path is empty.
+func GetKubeConfig(kubeConfigPath string) (*rest.Config, error) {
 	var clientConfig *rest.Config
 	var clientConfigError error
 
-	if Cfg.KubeConfig != "" {
-		glog.Infof("Creating k8s client using KubeConfig at: %s", Cfg.KubeConfig)
-		clientConfig, clientConfigError = clientcmd.BuildConfigFromFlags("", Cfg.KubeConfig)
+	if kubeConfigPath != "" {
+		glog.Infof("Creating k8s client using KubeConfig at: %s", kubeConfigPath)
+		clientConfig, clientConfigError = clientcmd.BuildConfigFromFlags("", kubeConfigPath)
 	} else {
 		glog.V(2).Info("Creating k8s client using InClusterClientConfig()")
 		clientConfig, clientConfigError = rest.InClusterConfig()
 	}
 
 	if clientConfigError != nil {
-		glog.Fatal("Error getting Kube Config: ", clientConfigError)
+		return nil, fmt.Errorf("error getting Kube Config: %w", clientConfigError)
 	}
 
-	return clientConfig
+	return clientConfig, nil
 }
 
 // Get kubernetes client for discovering resource types.
-func GetDiscoveryClient() *discovery.DiscoveryClient {
-	discoveryClient, err := discovery.NewDiscoveryClientForConfig(GetKubeConfig())
+func GetDiscoveryClient(config *rest.Config) (*discovery.DiscoveryClient, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
 	if err != nil {
-		glog.Fatal("Cannot Construct Discovery Client From Config: ", err)
+		return nil, fmt.Errorf("cannot construct discovery client from config: %w", err)
 	}
-	return discoveryClient
+	return discoveryClient, nil
 }
 
-func GetKubeClient(config *rest.Config) *kubernetes.Clientset {
-	var kubeClient *kubernetes.Clientset
-	var err error
-	if config != nil {
-		kubeClient, err = kubernetes.NewForConfig(config)
-		if err != nil {
-			glog.Fatal("Cannot Construct Kube Client from Config: ", err)
-		}
-	} else {
-		glog.Error("Cannot Construct Kube Client as input Config is nil")
+func GetKubeClient(config *rest.Config) (*kubernetes.Clientset, error) {
+	if config == nil {
+		return nil, errors.New("cannot construct kube client as input config is nil")
+	}
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot construct kube client from config: %w", err)
 	}
-	return kubeClient
+	return kubeClient, nil
 }
@@ -0,0 +1,60 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Shared holds the config read by the goroutines of a pipeline while the config watcher applies live changes.
+// Get returns a snapshot that is never modified. Changes are made to a copy that replaces the snapshot, so the
+// readers don't need any locking. Keep the snapshot only while it's used, so the next read gets the changes.
+type Shared struct {
+	current atomic.Pointer[Config]
+	mutex   sync.Mutex // Serializes the updates, so concurrent changes aren't lost.
+}
+
+// Creates a Shared config with a copy of cfg.
+func NewShared(cfg *Config) *Shared {
+	s := &Shared{}
+	snapshot := *cfg
+	s.current.Store(&snapshot)
+	return s
+}
+
+// Returns the current config. The returned config must not be modified.
+func (s *Shared) Get() *Config {
+	return s.current.Load()
+}
+
+// Replaces the config with a copy updated by the update function. The config isn't replaced if update
+// returns an error.
+func (s *Shared) update(update func(cfg *Config) error) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	next := *s.current.Load()
+	if err := update(&next); err != nil {
+		return err
+	}
+	s.current.Store(&next)
+	return nil
+}
+
+// Applies the changes that can be applied live.
+// Used by the watcher, and to propagate the changes to the config of each cluster in multi-cluster mode.
+func (s *Shared) Apply(changes []Change) {
+	_ = s.update(func(cfg *Config) error {
+		cfg.apply(changes)
+		return nil
+	})
+}
+
+// Rebuilds the hub client config and the aggregator URL from HUB_CONFIG, to pick up rotated credentials.
+// Keeps the current config if HUB_CONFIG can't be loaded.
+func (s *Shared) ReloadHubConfig() error {
+	return s.update(func(cfg *Config) error {
+		return cfg.ReloadHubConfig()
+	})
+}
@@ -0,0 +1,228 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Interval to check the config sources for changes.
+const DEFAULT_CONFIG_WATCH_MS = 30000 // 30 seconds
+
+// Name of the ConfigMap with the allow/deny lists and settings.
+const COLLECTOR_CONFIGMAP = "search-collector-config"
+
+// Keys in the ConfigMap that aren't config fields. Changes are passed to the listeners, but not stored in the Config.
+var configMapOnlyKeys = []string{"AllowedResources", "DeniedResources"}
+
+// Fields that can be changed without restarting the collector. Changes to other fields are logged and ignored.
+var liveFields = map[string]bool{
+	"GoroutineBudget":  true,
+	"HeapBudgetMB":     true,
+	"HeartbeatMS":      true,
+	"MaxBackoffMS":     true,
+	"RediscoverRateMS": true,
+	"ReportRateMS":     true,
+	"RetryJitterMS":    true,
+	"TLSCAFile":        true,
+	"TLSCertFile":      true,
+	"TLSKeyFile":       true,
+	"TLSMinVersion":    true,
+	"TLSCipherProfile": true,
+	"TLSStrict":        true,
+}
+
+// A setting that changed when the config was reloaded.
+type Change struct {
+	Field   string
+	Old     interface{}
+	New     interface{}
+	Applied bool // False if the change requires a restart.
+}
+
+// Returns the Change for a field, and whether it was found.
+func FindChange(changes []Change, field string) (Change, bool) {
+	for _, c := range changes {
+		if c.Field == field {
+			return c, true
+		}
+	}
+	return Change{}, false
+}
+
+// Watcher polls config.json, the projected config files and the search-collector-config ConfigMap.
+// When any of them changes, it reloads the config, applies the changes that are safe to apply live,
+// and notifies the listeners.
+type Watcher struct {
+	cfg        *Shared // Config updated with the changes that are safe to apply live.
+	interval   time.Duration
+	kubeClient kubernetes.Interface // Used to read the ConfigMap. The ConfigMap is ignored if nil.
+	modTimes   map[string]time.Time // Modification times of the config files.
+	configMap  map[string]string    // Last data read from the ConfigMap.
+	listeners  []func([]Change)
+}
+
+// Creates a Watcher that applies live changes to cfg. Reads the ConfigMap with kubeClient, which can be nil.
+func NewWatcher(cfg *Shared, interval time.Duration, kubeClient kubernetes.Interface) *Watcher {
+	return &Watcher{
+		cfg:        cfg,
+		interval:   interval,
+		kubeClient: kubeClient,
+		modTimes:   make(map[string]time.Time),
+		configMap:  map[string]string{},
+	}
+}
+
+// Registers a function to call with the changes after the config is reloaded.
+// Listeners are called from the watcher goroutine.
+func (w *Watcher) OnChange(listener func([]Change)) {
+	w.listeners = append(w.listeners, listener)
+}
+
+// Run checks for changes every interval until stopper is closed.
+func (w *Watcher) Run(stopper <-chan struct{}) {
+	glog.Info("Watching config for changes.")
+	for {
+		w.check()
+		select {
+		case <-stopper:
+			return
+		case <-time.After(w.interval):
+		}
+	}
+}
+
+// Reloads the config if any of the sources changed.
+func (w *Watcher) check() {
+	filesChanged := w.filesChanged()
+	configMap, configMapChanged := w.readConfigMap()
+	if !filesChanged && !configMapChanged {
+		return
+	}
+
+	newCfg, err := loadConfig(newSettings(configMap))
+	if err != nil {
+		glog.Error("Ignoring config change. ", err)
+		return
+	}
+	changes := diffConfig(*w.cfg.Get(), newCfg)
+	for _, key := range configMapOnlyKeys {
+		if w.configMap[key] != configMap[key] {
+			changes = append(changes, Change{Field: key, Old: w.configMap[key], New: configMap[key], Applied: true})
+		}
+	}
+	w.configMap = configMap
+	if len(changes) == 0 {
+		return
+	}
+
+	w.cfg.Apply(changes)
+	for _, c := range changes {
+		if c.Applied {
+			glog.Infof("Config changed. field=%s old=%v new=%v", c.Field, c.Old, c.New)
+		} else {
+			glog.Warningf("Config changed but requires a restart to apply. field=%s old=%v new=%v",
+				c.Field, c.Old, c.New)
+		}
+	}
+	for _, listener := range w.listeners {
+		listener(changes)
+	}
+}
+
+// Returns true if config.json or any of the projected config files changed since the last check.
+func (w *Watcher) filesChanged() bool {
+	files := []string{*FilePath}
+	projectedConfigDir := w.cfg.Get().ProjectedConfigDir
+	if projectedConfigDir != "" {
+		entries, err := os.ReadDir(projectedConfigDir)
+		if err != nil {
+			glog.V(2).Info("Unable to read projected config dir. ", err)
+		}
+		for _, e := range entries {
+			// Kubernetes projects files as symlinks to hidden directories, we only care about the settings.
+			if e.Name()[0] != '.' {
+				files = append(files, filepath.Join(projectedConfigDir, e.Name()))
+			}
+		}
+	}
+
+	changed := false
+	current := make(map[string]time.Time, len(files))
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			current[file] = info.ModTime()
+		}
+		if !current[file].Equal(w.modTimes[file]) {
+			changed = true
+		}
+	}
+	// Also detect deleted files.
+	if len(current) != len(w.modTimes) {
+		changed = true
+	}
+	w.modTimes = current
+	return changed
+}
+
+// Returns the data in the ConfigMap and whether it changed since the last check.
+func (w *Watcher) readConfigMap() (map[string]string, bool) {
+	if w.kubeClient == nil {
+		return w.configMap, false
+	}
+	cm, err := w.kubeClient.CoreV1().ConfigMaps(w.cfg.Get().PodNamespace).Get(context.TODO(), COLLECTOR_CONFIGMAP,
+		metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return map[string]string{}, len(w.configMap) > 0
+	} else if err != nil {
+		glog.V(2).Infof("Unable to read ConfigMap %s. %v", COLLECTOR_CONFIGMAP, err)
+		return w.configMap, false
+	}
+	data := cm.Data
+	if data == nil {
+		data = map[string]string{}
+	}
+	return data, !reflect.DeepEqual(data, w.configMap)
+}
+
+// Compares the fields of two configs. The rest.Config for the hub isn't compared, it's rebuilt from HUB_CONFIG.
+func diffConfig(old, new Config) []Change {
+	changes := []Change{}
+	oldVal := reflect.ValueOf(old)
+	newVal := reflect.ValueOf(new)
+	for i := 0; i < oldVal.NumField(); i++ {
+		field := oldVal.Type().Field(i)
+		if field.Type.Kind() == reflect.Ptr {
+			continue
+		}
+		o := oldVal.Field(i).Interface()
+		n := newVal.Field(i).Interface()
+		if !reflect.DeepEqual(o, n) {
+			changes = append(changes, Change{Field: field.Name, Old: o, New: n, Applied: liveFields[field.Name]})
+		}
+	}
+	return changes
+}
+
+// Sets the new value of the changes that can be applied live.
+// Only used on the copy made by Shared.Apply, the config read by other goroutines is never modified.
+func (cfg *Config) apply(changes []Change) {
+	cfgVal := reflect.ValueOf(cfg).Elem()
+	for _, c := range changes {
+		if !c.Applied {
+			continue
+		}
+		if f := cfgVal.FieldByName(c.Field); f.IsValid() {
+			f.Set(reflect.ValueOf(c.New))
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// Loads the config used by the watcher tests.
+func loadTestConfig(t *testing.T) *Config {
+	t.Setenv("DEPLOYED_IN_HUB", "true")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func Test_diffConfig(t *testing.T) {
+	old := Config{ReportRateMS: 5000, ClusterName: "a"}
+	new := Config{ReportRateMS: 1000, ClusterName: "b"}
+
+	changes := diffConfig(old, new)
+
+	assert.Equal(t, []Change{
+		{Field: "ClusterName", Old: "a", New: "b", Applied: false},
+		{Field: "ReportRateMS", Old: 5000, New: 1000, Applied: true},
+	}, changes)
+
+	old.apply(changes)
+	assert.Equal(t, 1000, old.ReportRateMS)
+	assert.Equal(t, "a", old.ClusterName, "Should not apply changes that require a restart")
+}
+
+func Test_SharedApply(t *testing.T) {
+	cfg := NewShared(&Config{ReportRateMS: 5000})
+	snapshot := cfg.Get()
+
+	cfg.Apply([]Change{{Field: "ReportRateMS", Old: 5000, New: 1000, Applied: true}})
+
+	assert.Equal(t, 1000, cfg.Get().ReportRateMS)
+	assert.Equal(t, 5000, snapshot.ReportRateMS, "Should not modify a config that was already read")
+}
+
+func Test_SharedReloadHubConfig(t *testing.T) {
+	hubConfig := filepath.Join(t.TempDir(), "kubeconfig")
+	kubeConfig := `apiVersion: v1
+kind: Config
+clusters:
+- name: hub
+  cluster:
+    server: https://hub.example.com:6443
+contexts:
+- name: hub
+  context:
+    cluster: hub
+current-context: hub
+`
+	if err := os.WriteFile(hubConfig, []byte(kubeConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := NewShared(&Config{ClusterName: "edge-1", AggregatorConfigFile: hubConfig})
+	snapshot := cfg.Get()
+
+	err := cfg.ReloadHubConfig()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://hub.example.com:6443/apis/proxy.open-cluster-management.io/v1beta1/namespaces/"+
+		"edge-1/clusterstatuses", cfg.Get().AggregatorURL)
+	assert.Empty(t, snapshot.AggregatorURL, "Should not modify a config that was already read")
+	assert.Nil(t, snapshot.AggregatorConfig)
+}
+
+func Test_WatcherProjectedFiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PROJECTED_CONFIG_DIR", dir)
+	cfg := NewShared(loadTestConfig(t))
+
+	w := NewWatcher(cfg, time.Second, nil)
+	w.check() // Records the initial state.
+
+	received := []Change{}
+	w.OnChange(func(changes []Change) { received = append(received, changes...) })
+
+	if err := os.WriteFile(filepath.Join(dir, "REPORT_RATE_MS"), []byte("1234\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	w.check()
+
+	assert.Equal(t, 1234, cfg.Get().ReportRateMS)
+	change, ok := FindChange(received, "ReportRateMS")
+	assert.True(t, ok)
+	assert.Equal(t, 1234, change.New)
+}
+
+func Test_WatcherConfigMap(t *testing.T) {
+	if _, ok := os.LookupEnv("CLUSTER_NAME"); ok {
+		t.Skip("CLUSTER_NAME is set in the environment")
+	}
+	cfg := NewShared(loadTestConfig(t))
+	client := fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: COLLECTOR_CONFIGMAP, Namespace: cfg.Get().PodNamespace},
+		Data: map[string]string{
+			"HEARTBEAT_MS":     "60000",
+			"CLUSTER_NAME":     "renamed",
+			"AllowedResources": "- apiGroups: [\"*\"]\n  resources: [\"pods\"]",
+		},
+	})
+	clusterName := cfg.Get().ClusterName
+
+	received := []Change{}
+	w := NewWatcher(cfg, time.Second, client)
+	w.OnChange(func(changes []Change) { received = append(received, changes...) })
+	w.check()
+
+	if _, ok := os.LookupEnv("HEARTBEAT_MS"); !ok {
+		assert.Equal(t, 60000, cfg.Get().HeartbeatMS)
+	}
+	assert.Equal(t, clusterName, cfg.Get().ClusterName, "Should not change the cluster name without a restart")
+	change, ok := FindChange(received, "ClusterName")
+	assert.True(t, ok)
+	assert.False(t, change.Applied)
+	_, ok = FindChange(received, "AllowedResources")
+	assert.True(t, ok)
+}
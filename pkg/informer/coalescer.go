@@ -0,0 +1,172 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package informer
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	tr "github.com/stolostron/search-collector/pkg/transforms"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// How often the coalescer checks for pending updates that are due.
+const coalesceTick = time.Second
+
+// Coalescer rate-limits the updates of high-churn resources (i.e. Leases, Endpoints) before the transformer.
+// For each UID, it forwards at most one update per minimum interval. Updates received within the interval are
+// held and replaced by newer ones, so only the newest update is forwarded when the interval passes.
+// Resources without a minimum interval are forwarded immediately.
+type Coalescer struct {
+	intervals map[schema.GroupResource]time.Duration // Minimum interval between updates for each resource.
+	output    func(*tr.Event)                        // Sends the event to the transformer.
+	mutex     sync.Mutex
+	pending   map[string]*tr.Event // Newest update held for each UID.
+	lastSent  map[string]time.Time // Last time an event was forwarded for each UID.
+	coalesced int64                // Number of updates replaced by a newer update before being forwarded.
+}
+
+// Creates a Coalescer that forwards events to output.
+func NewCoalescer(intervals map[schema.GroupResource]time.Duration, output func(*tr.Event)) *Coalescer {
+	return &Coalescer{
+		intervals: intervals,
+		output:    output,
+		pending:   make(map[string]*tr.Event),
+		lastSent:  make(map[string]time.Time),
+	}
+}
+
+// Parses a comma separated list of resource.group=duration, i.e. leases.coordination.k8s.io=60s,endpoints=30s
+// Invalid entries are logged and ignored.
+func parseIntervals(val string) map[schema.GroupResource]time.Duration {
+	intervals := make(map[schema.GroupResource]time.Duration)
+	for _, entry := range strings.Split(val, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		res, durationStr, found := strings.Cut(entry, "=")
+		duration, err := time.ParseDuration(strings.TrimSpace(durationStr))
+		if !found || err != nil {
+			glog.Errorf("Ignoring invalid minimum update interval [%s]. Expected resource.group=duration", entry)
+			continue
+		}
+		intervals[schema.ParseGroupResource(strings.TrimSpace(res))] = duration
+	}
+	return intervals
+}
+
+// Returns the minimum interval between updates for the resource in the event, or 0 if it isn't rate-limited.
+func (c *Coalescer) interval(event *tr.Event) time.Duration {
+	gr := schema.GroupResource{Group: event.Resource.GroupVersionKind().Group, Resource: event.ResourceString}
+	return c.intervals[gr]
+}
+
+// Add forwards a new resource immediately. Starts the minimum interval for its updates.
+func (c *Coalescer) Add(event *tr.Event) {
+	if c.interval(event) > 0 {
+		c.mutex.Lock()
+		uid := string(event.Resource.GetUID())
+		delete(c.pending, uid) // The add has the newest state.
+		c.lastSent[uid] = time.Now()
+		c.mutex.Unlock()
+	}
+	c.output(event)
+}
+
+// Update forwards the update if the minimum interval passed since the last event for the UID, otherwise holds it
+// until the interval passes.
+func (c *Coalescer) Update(event *tr.Event) {
+	interval := c.interval(event)
+	if interval == 0 {
+		c.output(event)
+		return
+	}
+	uid := string(event.Resource.GetUID())
+	now := time.Now()
+
+	c.mutex.Lock()
+	if _, held := c.pending[uid]; held {
+		c.coalesced++
+		c.pending[uid] = event
+		c.mutex.Unlock()
+		return
+	}
+	if now.Sub(c.lastSent[uid]) < interval {
+		c.pending[uid] = event
+		c.mutex.Unlock()
+		return
+	}
+	c.lastSent[uid] = now
+	c.mutex.Unlock()
+	c.output(event)
+}
+
+// Forget drops the pending update for a deleted resource, so it doesn't get created again.
+func (c *Coalescer) Forget(uid string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.pending, uid)
+	delete(c.lastSent, uid)
+}
+
+// Run forwards the pending updates as they become due, until stopper is closed.
+func (c *Coalescer) Run(stopper <-chan struct{}) {
+	ticker := time.NewTicker(coalesceTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopper:
+			return
+		case now := <-ticker.C:
+			for _, event := range c.due(now) {
+				c.output(event)
+			}
+		}
+	}
+}
+
+// Returns the pending updates whose minimum interval passed, and forgets the UIDs that are idle.
+func (c *Coalescer) due(now time.Time) []*tr.Event {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	events := []*tr.Event{}
+	for uid, event := range c.pending {
+		if now.Sub(c.lastSent[uid]) >= c.interval(event) {
+			events = append(events, event)
+			c.lastSent[uid] = now
+			delete(c.pending, uid)
+		}
+	}
+	// A UID without a pending update and with its interval passed behaves like a new UID, no need to keep it.
+	for uid, sent := range c.lastSent {
+		if _, held := c.pending[uid]; !held && now.Sub(sent) >= c.maxInterval() {
+			delete(c.lastSent, uid)
+		}
+	}
+	if len(events) > 0 {
+		glog.V(3).Infof("Forwarding %d coalesced updates. Total updates coalesced: %d", len(events), c.coalesced)
+	}
+	return events
+}
+
+// Returns the longest minimum interval.
+func (c *Coalescer) maxInterval() time.Duration {
+	max := time.Duration(0)
+	for _, d := range c.intervals {
+		if d > max {
+			max = d
+		}
+	}
+	return max
+}
+
+// Returns the number of updates that were replaced by a newer update before being forwarded.
+func (c *Coalescer) Coalesced() int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.coalesced
+}
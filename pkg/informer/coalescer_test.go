@@ -0,0 +1,84 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package informer
+
+import (
+	"testing"
+	"time"
+
+	tr "github.com/stolostron/search-collector/pkg/transforms"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func newLeaseEvent(uid, version string, op tr.Operation) *tr.Event {
+	lease := newTestUnstructured("coordination.k8s.io/v1", "Lease", "ns-foo", "lease-"+uid, uid)
+	lease.SetResourceVersion(version)
+	return &tr.Event{Operation: op, Resource: lease, ResourceString: "leases"}
+}
+
+func initTestCoalescer() (*Coalescer, chan *tr.Event) {
+	output := make(chan *tr.Event, 10)
+	intervals := map[schema.GroupResource]time.Duration{{Group: "coordination.k8s.io", Resource: "leases"}: time.Minute}
+	return NewCoalescer(intervals, func(e *tr.Event) { output <- e }), output
+}
+
+func Test_parseIntervals(t *testing.T) {
+	intervals := parseIntervals("leases.coordination.k8s.io=60s, endpoints=30s,invalid,configmaps=abc")
+
+	assert.Equal(t, map[schema.GroupResource]time.Duration{
+		{Group: "coordination.k8s.io", Resource: "leases"}: time.Minute,
+		{Resource: "endpoints"}:                            30 * time.Second,
+	}, intervals)
+}
+
+func TestCoalescerKeepsNewestUpdate(t *testing.T) {
+	c, output := initTestCoalescer()
+
+	c.Add(newLeaseEvent("id-1", "1", tr.Create))
+	c.Update(newLeaseEvent("id-1", "2", tr.Update))
+	c.Update(newLeaseEvent("id-1", "3", tr.Update))
+	assert.Len(t, output, 1, "Should hold the updates within the interval")
+	<-output
+
+	assert.Empty(t, c.due(time.Now()), "Should not forward before the interval passes")
+	due := c.due(time.Now().Add(time.Minute))
+	assert.Len(t, due, 1)
+	assert.Equal(t, "3", due[0].Resource.GetResourceVersion(), "Should forward the newest update")
+	assert.Equal(t, int64(1), c.Coalesced())
+}
+
+func TestCoalescerForwardsOtherResources(t *testing.T) {
+	c, output := initTestCoalescer()
+	pod := &tr.Event{
+		Operation:      tr.Update,
+		Resource:       newTestUnstructured("v1", "Pod", "ns-foo", "pod", "id-2"),
+		ResourceString: "pods",
+	}
+
+	c.Update(pod)
+	c.Update(pod)
+
+	assert.Len(t, output, 2)
+}
+
+func TestCoalescerFirstUpdate(t *testing.T) {
+	c, output := initTestCoalescer()
+
+	// The informer could have been restarted, so an update for an unknown UID is forwarded.
+	c.Update(newLeaseEvent("id-1", "2", tr.Update))
+
+	assert.Len(t, output, 1)
+}
+
+func TestCoalescerForget(t *testing.T) {
+	c, output := initTestCoalescer()
+
+	c.Add(newLeaseEvent("id-1", "1", tr.Create))
+	c.Update(newLeaseEvent("id-1", "2", tr.Update))
+	c.Forget("id-1")
+
+	assert.Len(t, output, 1)
+	assert.Empty(t, c.due(time.Now().Add(time.Minute)), "Should not forward an update for a deleted resource")
+	assert.Empty(t, c.lastSent)
+}
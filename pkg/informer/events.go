@@ -0,0 +1,236 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package informer
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/stolostron/search-collector/pkg/config"
+	rec "github.com/stolostron/search-collector/pkg/reconciler"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	maxWarningsPerObject = 10  // Most recent Warning events kept for each object.
+	maxWarningMessage    = 512 // Characters of the message kept in the lastWarningMessage property.
+)
+
+// Interval to check if the events watcher must be paused or resumed to shed load.
+const eventsPauseCheckInterval = 10 * time.Second
+
+// Events aren't collected as nodes because they are too noisy. Instead, the recent Warning events of each object
+// are summarized in properties of the object's node, so we can search for the resources with problems.
+type warningEvents struct {
+	mutex       sync.Mutex
+	clusterName string
+	ttl         time.Duration                     // Time after the last occurrence when a Warning is forgotten.
+	byObject    map[string]map[string]warningInfo // Keyed by the node UID of the involved object, then event UID.
+	objectUIDs  map[string]string                 // Node UID of the involved object, keyed by event UID.
+	// Sets the summary properties on the node of the involved object. Called with nil to remove them.
+	setProperties func(uid string, props map[string]interface{})
+}
+
+// The parts of a Warning event we keep.
+type warningInfo struct {
+	Reason, Message string
+	Count           int64
+	Last            time.Time
+}
+
+func newWarningEvents(clusterName string, ttl time.Duration,
+	setProperties func(string, map[string]interface{})) *warningEvents {
+	return &warningEvents{
+		clusterName:   clusterName,
+		ttl:           ttl,
+		byObject:      make(map[string]map[string]warningInfo),
+		objectUIDs:    make(map[string]string),
+		setProperties: setProperties,
+	}
+}
+
+// Watches the Warning events in the cluster and adds them to the nodes of the involved objects. Doesn't return.
+// Events are low priority, the watcher is stopped while the control pauses the low priority informers to shed load.
+func RunEventsWatcher(cfg *config.Config, clients Clients, control *Control, reconciler *rec.Reconciler) {
+	ttl := time.Duration(cfg.EventsTTLMS) * time.Millisecond
+	w := newWarningEvents(cfg.ClusterName, ttl, reconciler.SetEventProperties)
+
+	var stopper, stopped chan struct{} // Nil while the watcher is paused.
+	lastExpire := time.Now()
+	for {
+		paused := control.isLowPriorityPaused()
+		if paused && stopper != nil {
+			glog.Info("Pausing the events watcher to shed load.")
+			// Stopping the informer deletes the listed and watched events, which removes their warnings.
+			// Wait for it to finish, so it doesn't remove the warnings added after resuming.
+			close(stopper)
+			<-stopped
+			stopper, stopped = nil, nil
+		} else if !paused && stopper == nil {
+			informer, err := newEventsInformer(clients.Dynamic, w)
+			if err != nil {
+				glog.Error("Unable to create the events informer. ", err)
+			} else {
+				glog.V(2).Info("Watching Warning events.")
+				stopper, stopped = make(chan struct{}), make(chan struct{})
+				go func(stopper, stopped chan struct{}) {
+					informer.Run(stopper)
+					close(stopped)
+				}(stopper, stopped)
+			}
+		}
+
+		if now := time.Now(); now.Sub(lastExpire) >= time.Minute {
+			w.expire(now)
+			lastExpire = now
+		}
+		time.Sleep(eventsPauseCheckInterval)
+	}
+}
+
+// Creates the informer for the Warning events. Normal events are filtered by the API server.
+func newEventsInformer(client dynamic.Interface, w *warningEvents) (GenericInformer, error) {
+	informer, err := InformerForResource(schema.GroupVersionResource{Version: "v1", Resource: "events"}, client)
+	if err != nil {
+		return informer, err
+	}
+	informer.fieldSelector = fields.OneTermEqualSelector("type", v1.EventTypeWarning).String()
+	informer.AddFunc = w.upsert
+	informer.UpdateFunc = func(_, obj interface{}) { w.upsert(obj) }
+	informer.DeleteFunc = w.delete
+	return informer, nil
+}
+
+// Adds or updates a Warning event. Normal events are ignored, in case the API server doesn't filter them.
+func (w *warningEvents) upsert(obj interface{}) {
+	event := v1.Event{}
+	err := runtime.DefaultUnstructuredConverter.
+		FromUnstructured(obj.(*unstructured.Unstructured).UnstructuredContent(), &event)
+	if err != nil {
+		glog.Warningf("Error converting event %s. %s", obj.(*unstructured.Unstructured).GetName(), err)
+		return
+	}
+	if event.Type != v1.EventTypeWarning || event.InvolvedObject.UID == "" {
+		return
+	}
+	info := warningInfo{Reason: event.Reason, Message: event.Message, Count: int64(event.Count)}
+	switch {
+	case event.Series != nil && !event.Series.LastObservedTime.IsZero():
+		info.Last, info.Count = event.Series.LastObservedTime.Time, int64(event.Series.Count)
+	case !event.LastTimestamp.IsZero():
+		info.Last = event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		info.Last = event.EventTime.Time
+	default:
+		info.Last = event.CreationTimestamp.Time
+	}
+	if info.Count < 1 {
+		info.Count = 1
+	}
+	if message := []rune(info.Message); len(message) > maxWarningMessage {
+		info.Message = string(message[:maxWarningMessage])
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	objectUID := strings.Join([]string{w.clusterName, string(event.InvolvedObject.UID)}, "/")
+	if w.byObject[objectUID] == nil {
+		w.byObject[objectUID] = make(map[string]warningInfo)
+	}
+	w.byObject[objectUID][string(event.UID)] = info
+	w.objectUIDs[string(event.UID)] = objectUID
+	w.evictOldest(objectUID)
+	w.update(objectUID)
+}
+
+// Removes a deleted event. The informer only has the UID when the event was deleted while it wasn't watching.
+func (w *warningEvents) delete(obj interface{}) {
+	eventUID := string(obj.(*unstructured.Unstructured).GetUID())
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if objectUID, ok := w.objectUIDs[eventUID]; ok {
+		w.remove(objectUID, eventUID)
+		w.update(objectUID)
+	}
+}
+
+// Forgets the Warnings that didn't happen again within the TTL.
+func (w *warningEvents) expire(now time.Time) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	for objectUID, warnings := range w.byObject {
+		expired := false
+		for eventUID, info := range warnings {
+			if now.Sub(info.Last) > w.ttl {
+				w.remove(objectUID, eventUID)
+				expired = true
+			}
+		}
+		if expired {
+			w.update(objectUID)
+		}
+	}
+}
+
+// Keeps the most recent Warnings of the object, so an object with many different events doesn't use too much memory.
+func (w *warningEvents) evictOldest(objectUID string) {
+	for len(w.byObject[objectUID]) > maxWarningsPerObject {
+		oldestUID := ""
+		for eventUID, info := range w.byObject[objectUID] {
+			if oldestUID == "" || info.Last.Before(w.byObject[objectUID][oldestUID].Last) {
+				oldestUID = eventUID
+			}
+		}
+		w.remove(objectUID, oldestUID)
+	}
+}
+
+func (w *warningEvents) remove(objectUID, eventUID string) {
+	delete(w.objectUIDs, eventUID)
+	delete(w.byObject[objectUID], eventUID)
+	if len(w.byObject[objectUID]) == 0 {
+		delete(w.byObject, objectUID)
+	}
+}
+
+// Sets the properties of the object from its Warnings: the reason, message and time of the last one, the number
+// of times they happened, and their distinct reasons.
+func (w *warningEvents) update(objectUID string) {
+	warnings := w.byObject[objectUID]
+	if len(warnings) == 0 {
+		w.setProperties(objectUID, nil)
+		return
+	}
+	last := warningInfo{}
+	count := int64(0)
+	reasons := make(map[string]struct{})
+	for _, info := range warnings {
+		if info.Last.After(last.Last) || last.Last.IsZero() {
+			last = info
+		}
+		count += info.Count
+		reasons[info.Reason] = struct{}{}
+	}
+	reasonList := make([]string, 0, len(reasons))
+	for reason := range reasons {
+		reasonList = append(reasonList, reason)
+	}
+	sort.Strings(reasonList)
+
+	w.setProperties(objectUID, map[string]interface{}{
+		"lastWarningReason":    last.Reason,
+		"lastWarningMessage":   last.Message,
+		"lastWarningTimestamp": last.Last.UTC().Format(time.RFC3339),
+		"warningCount":         count,
+		"warningReasons":       reasonList,
+	})
+}
@@ -0,0 +1,133 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package informer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func newTestEvent(uid, eventType, reason string, count int32, last time.Time) *unstructured.Unstructured {
+	event := v1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "event-" + uid, Namespace: "default", UID: types.UID(uid)},
+		InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: "pod-1", Namespace: "default", UID: "pod-uid"},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        reason + " message",
+		Count:          count,
+		LastTimestamp:  metav1.NewTime(last),
+	}
+	obj, _ := runtime.DefaultUnstructuredConverter.ToUnstructured(&event)
+	return &unstructured.Unstructured{Object: obj}
+}
+
+func initTestWarningEvents() (*warningEvents, map[string]map[string]interface{}) {
+	props := make(map[string]map[string]interface{})
+	return newWarningEvents("local-cluster", time.Hour, func(uid string, p map[string]interface{}) {
+		props[uid] = p
+	}), props
+}
+
+func TestWarningEventsSummary(t *testing.T) {
+	w, props := initTestWarningEvents()
+	now := time.Now().Truncate(time.Second)
+
+	w.upsert(newTestEvent("e-1", v1.EventTypeWarning, "BackOff", 5, now.Add(-time.Minute)))
+	w.upsert(newTestEvent("e-2", v1.EventTypeWarning, "Unhealthy", 2, now))
+	w.upsert(newTestEvent("e-3", v1.EventTypeNormal, "Pulled", 1, now))
+
+	assert.Equal(t, map[string]interface{}{
+		"lastWarningReason":    "Unhealthy",
+		"lastWarningMessage":   "Unhealthy message",
+		"lastWarningTimestamp": now.UTC().Format(time.RFC3339),
+		"warningCount":         int64(7),
+		"warningReasons":       []string{"BackOff", "Unhealthy"},
+	}, props["local-cluster/pod-uid"])
+
+	// When the informer deletes an event, it may only have the UID.
+	w.delete(newUnstructured("events", "e-2"))
+	assert.Equal(t, "BackOff", props["local-cluster/pod-uid"]["lastWarningReason"])
+	assert.Equal(t, int64(5), props["local-cluster/pod-uid"]["warningCount"])
+}
+
+func TestWarningEventsExpire(t *testing.T) {
+	w, props := initTestWarningEvents()
+	now := time.Now()
+
+	w.upsert(newTestEvent("e-1", v1.EventTypeWarning, "BackOff", 1, now.Add(-2*time.Hour)))
+	w.upsert(newTestEvent("e-2", v1.EventTypeWarning, "Unhealthy", 1, now.Add(-time.Minute)))
+
+	w.expire(now)
+	assert.Equal(t, []string{"Unhealthy"}, props["local-cluster/pod-uid"]["warningReasons"])
+
+	w.expire(now.Add(time.Hour))
+	assert.Contains(t, props, "local-cluster/pod-uid")
+	assert.Nil(t, props["local-cluster/pod-uid"])
+	assert.Empty(t, w.byObject)
+	assert.Empty(t, w.objectUIDs)
+}
+
+func TestWarningEventsBounded(t *testing.T) {
+	w, props := initTestWarningEvents()
+	now := time.Now()
+
+	for i := 0; i < maxWarningsPerObject+5; i++ {
+		reason := "Reason" + strings.Repeat("x", i)
+		w.upsert(newTestEvent("e-"+reason, v1.EventTypeWarning, reason, 1, now.Add(time.Duration(i)*time.Second)))
+	}
+	assert.Len(t, w.byObject["local-cluster/pod-uid"], maxWarningsPerObject)
+	assert.Equal(t, int64(maxWarningsPerObject), props["local-cluster/pod-uid"]["warningCount"])
+	assert.NotContains(t, props["local-cluster/pod-uid"]["warningReasons"], "Reason")
+
+	long := newTestEvent("e-long", v1.EventTypeWarning, "Failed", 1, now.Add(time.Hour))
+	unstructured.SetNestedField(long.Object, strings.Repeat("é", 1000), "message")
+	w.upsert(long)
+	assert.Len(t, []rune(props["local-cluster/pod-uid"]["lastWarningMessage"].(string)), maxWarningMessage)
+}
+
+func TestEventsInformerWarningsOnly(t *testing.T) {
+	w, props := initTestWarningEvents()
+
+	informer, err := newEventsInformer(fakeDynamicClient(), w)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "type=Warning", informer.fieldSelector)
+	informer.AddFunc(newTestEvent("event-1", v1.EventTypeWarning, "BackOff", 1, time.Now()))
+	assert.Equal(t, "BackOff", props["local-cluster/pod-uid"]["lastWarningReason"])
+}
+
+func TestEventsInformerStopRemovesWarnings(t *testing.T) {
+	w, props := initTestWarningEvents()
+	eventsGVR := schema.GroupVersionResource{Version: "v1", Resource: "events"}
+	event := newTestEvent("event-1", v1.EventTypeWarning, "BackOff", 1, time.Now())
+	event.SetAPIVersion("v1")
+	event.SetKind("Event")
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{eventsGVR: "EventList"}, event)
+
+	informer, err := newEventsInformer(client, w)
+	assert.Nil(t, err)
+	stopper := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		informer.Run(stopper)
+		close(stopped)
+	}()
+	informer.WaitUntilInitialized(time.Second)
+	assert.Equal(t, "BackOff", props["local-cluster/pod-uid"]["lastWarningReason"])
+
+	// Stopping the informer, i.e. when the events watcher is paused, removes the warnings of the listed events.
+	close(stopper)
+	<-stopped
+	assert.Empty(t, props["local-cluster/pod-uid"])
+}
@@ -8,7 +8,6 @@ import (
 	"time"
 
 	"github.com/golang/glog"
-	"github.com/stolostron/search-collector/pkg/config"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -25,19 +24,21 @@ type GenericInformer struct {
 	AddFunc       func(interface{})
 	DeleteFunc    func(interface{})
 	UpdateFunc    func(prev interface{}, next interface{}) // We don't use prev, but matching client-go informer.
-	initialized   bool
-	resourceIndex map[string]string // Index of curr resources [key=UUID value=resourceVersion]
-	retries       int64             // Counts times we have tried without establishing a watch.
+	synced        chan struct{}                            // Closed once the first listAndResync() completes.
+	fieldSelector string                                   // Optional. Only list and watch the matching resources.
+	resourceIndex map[string]string                        // Index of curr resources [key=UUID value=resourceVersion]
+	retries       int64                                    // Counts times we have tried without establishing a watch.
 }
 
-// InformerForResource initialize a Generic Informer for a resource (GVR).
-func InformerForResource(res schema.GroupVersionResource) (GenericInformer, error) {
+// InformerForResource initialize a Generic Informer for a resource (GVR), using client to list and watch.
+func InformerForResource(res schema.GroupVersionResource, client dynamic.Interface) (GenericInformer, error) {
 	i := GenericInformer{
+		client:        client,
 		gvr:           res,
 		AddFunc:       (func(interface{}) { glog.Warning("AddFunc not initialized for ", res.String()) }),
 		DeleteFunc:    (func(interface{}) { glog.Warning("DeleteFunc not initialized for ", res.String()) }),
 		UpdateFunc:    (func(interface{}, interface{}) { glog.Warning("UpdateFunc not init for ", res.String()) }),
-		initialized:   false,
+		synced:        make(chan struct{}),
 		retries:       0,
 		resourceIndex: make(map[string]string),
 	}
@@ -65,13 +66,10 @@ func (inform *GenericInformer) Run(stopper chan struct{}) {
 				time.Sleep(wait)
 			}
 			glog.V(3).Info("(Re)starting informer: ", inform.gvr.String())
-			if inform.client == nil {
-				inform.client = config.GetDynamicClient()
-			}
 
 			err := inform.listAndResync()
 			if err == nil {
-				inform.initialized = true
+				inform.markSynced()
 				inform.watch(stopper)
 			}
 		}
@@ -107,7 +105,7 @@ func (inform *GenericInformer) listAndResync() error {
 
 	// We need this limit to avoid a memory spike. Smaller chunks allows us to release memory faster, however
 	// it generates more requests to the kube api server.
-	opts := metav1.ListOptions{Limit: 250}
+	opts := metav1.ListOptions{Limit: 250, FieldSelector: inform.fieldSelector}
 	for {
 		resources, listError := inform.client.Resource(inform.gvr).List(context.TODO(), opts)
 		if listError != nil {
@@ -142,16 +140,18 @@ func (inform *GenericInformer) listAndResync() error {
 			glog.V(3).Infof("Resource does not exist. Deleting resource: %s with UID: %s", inform.gvr.Resource, key)
 			obj := newUnstructured(inform.gvr.Resource, key)
 			inform.DeleteFunc(obj)
-			delete(inform.resourceIndex, key)
 		}
 	}
+	// Keep the listed resources, so they are deleted when the informer stops.
+	inform.resourceIndex = newResourceIndex
 	return nil
 }
 
 // Watch resources and process events.
 func (inform *GenericInformer) watch(stopper chan struct{}) {
 
-	watch, watchError := inform.client.Resource(inform.gvr).Watch(context.TODO(), metav1.ListOptions{})
+	watch, watchError := inform.client.Resource(inform.gvr).Watch(context.TODO(),
+		metav1.ListOptions{FieldSelector: inform.fieldSelector})
 	if watchError != nil {
 		glog.Warningf("Error watching resources for %s.  Error: %s", inform.gvr.String(), watchError)
 		inform.retries++
@@ -220,15 +220,21 @@ func (inform *GenericInformer) watch(stopper chan struct{}) {
 	}
 }
 
+// Closes the synced channel after the first listAndResync(). Only called from the Run goroutine.
+func (inform *GenericInformer) markSynced() {
+	select {
+	case <-inform.synced:
+	default:
+		close(inform.synced)
+	}
+}
+
 // Waits until informer has completed the initial listAndSync() of resources
 // or until timeout.
 func (inform *GenericInformer) WaitUntilInitialized(timeout time.Duration) {
-	start := time.Now()
-	for !inform.initialized {
-		if time.Since(start) > timeout {
-			glog.V(2).Infof("Informer [%s] timed out after %s waiting for initialization.", inform.gvr.String(), timeout)
-			break
-		}
-		time.Sleep(time.Duration(10) * time.Millisecond)
+	select {
+	case <-inform.synced:
+	case <-time.After(timeout):
+		glog.V(2).Infof("Informer [%s] timed out after %s waiting for initialization.", inform.gvr.String(), timeout)
 	}
 }
@@ -5,9 +5,12 @@ package informer
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -33,7 +36,7 @@ func fakeDynamicClient() *fake.FakeDynamicClient {
 		newTestUnstructured("open-cluster-management.io/v1", "TheKind", "ns-foo", "name-bar3", "id-005"))
 }
 
-func generateSimpleEvent(informer GenericInformer, t *testing.T) {
+func generateSimpleEvent(informer *GenericInformer, t *testing.T) {
 	// Add resource. Generates ADDED event.
 	newResource := newTestUnstructured("open-cluster-management.io/v1", "TheKind", "ns-foo", "name-new", "id-999")
 	_, err1 := informer.client.Resource(gvr).Namespace("ns-foo").Create(context.TODO(), newResource, v1.CreateOptions{})
@@ -63,18 +66,15 @@ func newTestUnstructured(apiVersion, kind, namespace, name, uid string) *unstruc
 	}
 }
 
-func initInformer() (informer GenericInformer, _ *int, _ *int, _ *int) {
+func initInformer() (informer GenericInformer, _ *int32, _ *int32, _ *int32) {
 	// Create informer instance to test.
-	informer, _ = InformerForResource(gvr)
+	informer, _ = InformerForResource(gvr, fakeDynamicClient())
 
-	// Add the fake client to be used by informer.
-	informer.client = fakeDynamicClient()
-
-	// Add mock functions
-	var addFuncCount, updateFuncCount, deleteFuncCount int
-	informer.AddFunc = func(interface{}) { addFuncCount++ }
-	informer.DeleteFunc = func(interface{}) { deleteFuncCount++ }
-	informer.UpdateFunc = func(interface{}, interface{}) { updateFuncCount++ }
+	// Add mock functions. The counts are updated atomically, the functions are called from the informer goroutine.
+	var addFuncCount, updateFuncCount, deleteFuncCount int32
+	informer.AddFunc = func(interface{}) { atomic.AddInt32(&addFuncCount, 1) }
+	informer.DeleteFunc = func(interface{}) { atomic.AddInt32(&deleteFuncCount, 1) }
+	informer.UpdateFunc = func(interface{}, interface{}) { atomic.AddInt32(&updateFuncCount, 1) }
 
 	return informer, &addFuncCount, &deleteFuncCount, &updateFuncCount
 }
@@ -82,7 +82,7 @@ func initInformer() (informer GenericInformer, _ *int, _ *int, _ *int) {
 // Verify that a generic informer can be created.
 func Test_InformerForResource_create(t *testing.T) {
 	// Create generic informer
-	informer, _ := InformerForResource(gvr)
+	informer, _ := InformerForResource(gvr, fakeDynamicClient())
 
 	// Verify that the informer event functions have not been initialized.
 	informer.AddFunc(nil)
@@ -102,8 +102,8 @@ func Test_listAndResync(t *testing.T) {
 	}
 
 	// Verify that informer.AddFunc is called for each of the mocked resources (5 times).
-	if *addFuncCount != 5 {
-		t.Errorf("Expected informer.AddFunc to be called 5 times, but got %d.", *addFuncCount)
+	if atomic.LoadInt32(addFuncCount) != 5 {
+		t.Errorf("Expected informer.AddFunc to be called 5 times, but got %d.", atomic.LoadInt32(addFuncCount))
 	}
 }
 
@@ -123,8 +123,8 @@ func Test_listAndResync_syncWithPrevState(t *testing.T) {
 	}
 
 	// Verify that informer.DeleteFunc is called once for resource with "fake-uid"
-	if *deleteFuncCount != 1 {
-		t.Errorf("Expected informer.DeleteFunc to be called 1 time, but got %d.", *deleteFuncCount)
+	if atomic.LoadInt32(deleteFuncCount) != 1 {
+		t.Errorf("Expected informer.DeleteFunc to be called 1 time, but got %d.", atomic.LoadInt32(deleteFuncCount))
 	}
 }
 
@@ -137,36 +137,75 @@ func Test_StoppedInformer_ValidateDeleteFunc(t *testing.T) {
 
 	informer.resourceIndex = resources
 	//keep track of calls made to mock informer.DeleteFunc below
+	var mutex sync.Mutex
 	deleteFuncCalls := make([]string, 0)
 
 	// mock DeleteFunc and get uids
 	informer.DeleteFunc = func(obj interface{}) {
-		for key := range informer.resourceIndex {
-			obj := newUnstructured(informer.gvr.Resource, key)
-			uid := string(obj.GetUID())
-			deleteFuncCalls = append(deleteFuncCalls, uid)
-		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		deleteFuncCalls = append(deleteFuncCalls, string(obj.(*unstructured.Unstructured).GetUID()))
 	}
 
 	// start informer
 	stopper := make(chan struct{})
-	go informer.Run(stopper)
+	stopped := make(chan struct{})
+	go func() {
+		informer.Run(stopper)
+		close(stopped)
+	}()
 	time.Sleep(10 * time.Millisecond)
 
 	//exist informer to trigger DeleteFunc
 	close(stopper)
 
 	//allow test to process
-	time.Sleep(10 * time.Millisecond)
+	<-stopped
 
 	// Verify that the informer.DeleteFunc was called with uid=id-999 and uid=id-100
-	for _, uid := range deleteFuncCalls {
-		if _, ok := resources[uid]; ok {
-			t.Errorf("Expected uid id-999, but got %s", resources[uid])
-		}
+	mutex.Lock()
+	defer mutex.Unlock()
+	for uid := range resources {
+		assert.Contains(t, deleteFuncCalls, uid)
 	}
 }
 
+// Verify that stopping an informer, i.e. to pause the low priority informers, deletes all its resources.
+func Test_StoppedInformer_DeletesListedResources(t *testing.T) {
+	informer, _ := InformerForResource(gvr, fakeDynamicClient())
+	// Resources kept downstream, like the nodes in the reconciler.
+	var mutex sync.Mutex
+	nodes := map[string]struct{}{}
+	informer.AddFunc = func(obj interface{}) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		nodes[string(obj.(*unstructured.Unstructured).GetUID())] = struct{}{}
+	}
+	informer.DeleteFunc = func(obj interface{}) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		delete(nodes, string(obj.(*unstructured.Unstructured).GetUID()))
+	}
+	nodeCount := func() int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(nodes)
+	}
+
+	stopper := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		informer.Run(stopper)
+		close(stopped)
+	}()
+	informer.WaitUntilInitialized(time.Second)
+	assert.Equal(t, 5, nodeCount())
+
+	close(stopper)
+	<-stopped
+	assert.Equal(t, 0, nodeCount(), "Expected the listed resources to be deleted when the informer stops")
+}
+
 // Verify the informer's Run function.
 func Test_Run(t *testing.T) {
 	// Create informer instance to test.
@@ -177,22 +216,22 @@ func Test_Run(t *testing.T) {
 	go informer.Run(stopper)
 	time.Sleep(10 * time.Millisecond)
 
-	generateSimpleEvent(informer, t)
+	generateSimpleEvent(&informer, t)
 	time.Sleep(10 * time.Millisecond)
 
 	close(stopper)
 
 	// Verify that informer.AddFunc is called for each of the mocked resources (6 times).
-	if *addFuncCount != 6 {
-		t.Errorf("Expected informer.AddFunc to be called 6 times, but got %d.", *addFuncCount)
+	if atomic.LoadInt32(addFuncCount) != 6 {
+		t.Errorf("Expected informer.AddFunc to be called 6 times, but got %d.", atomic.LoadInt32(addFuncCount))
 	}
 	// Verify informer.UpdateFunc is called once.
-	if *updateFuncCount != 1 {
-		t.Errorf("Expected informer.UpdateFunc to be called 1 times, but got %d.", *updateFuncCount)
+	if atomic.LoadInt32(updateFuncCount) != 1 {
+		t.Errorf("Expected informer.UpdateFunc to be called 1 times, but got %d.", atomic.LoadInt32(updateFuncCount))
 	}
 	// Verify informer.DeleteFunc is called once.
-	if *deleteFuncCount != 1 {
-		t.Errorf("Expected informer.DeleteFunc to be called 1 times, but got %d.", *deleteFuncCount)
+	if atomic.LoadInt32(deleteFuncCount) != 1 {
+		t.Errorf("Expected informer.DeleteFunc to be called 1 times, but got %d.", atomic.LoadInt32(deleteFuncCount))
 	}
 }
 
@@ -203,15 +242,16 @@ func Test_Run_retryBackoff(t *testing.T) {
 
 	informer.retries = 1
 	startTime := time.Now()
-	retryTime := time.Now() // Initializing to now ensures that the test fail if AddFunc is not called in the expected time.
-	informer.AddFunc = func(interface{}) { retryTime = time.Now() }
+	var retryTime atomic.Value
+	retryTime.Store(time.Now()) // Initializing to now ensures that the test fail if AddFunc is not called in the expected time.
+	informer.AddFunc = func(interface{}) { retryTime.Store(time.Now()) }
 
 	// Execute function
 	go informer.Run(make(chan struct{}))
 	time.Sleep(2010 * time.Millisecond)
 
 	// Verify backoff logic waits 2 seconds before retrying.
-	if startTime.Add(2 * time.Second).After(retryTime) {
+	if startTime.Add(2 * time.Second).After(retryTime.Load().(time.Time)) {
 		t.Errorf("Backoff logic failed to wait for 2 seconds.")
 	}
 }
@@ -275,7 +315,7 @@ func Test_WaitUntilInitialized_timeout(t *testing.T) {
 	start := time.Now()
 	informer.WaitUntilInitialized(time.Duration(2) * time.Millisecond)
 
-	// WaitUntilInitialized() polls every 10ms, so need to confirm that the timeout was triggered within 15ms.
+	// Confirm that the timeout was triggered within 15ms, allowing some scheduling delay.
 	if time.Since(start) > time.Duration(15)*time.Millisecond {
 		t.Errorf("Expected WaitUntilInitialized to time out within 15 milliseconds, but got %s", time.Since(start))
 	}
@@ -0,0 +1,60 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package informer
+
+import (
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Resources whose informers start first, in order. These are the resources users search for the most.
+// Their informers keep running when the low priority informers are paused to stay within the resource budget.
+type priorityList []schema.GroupResource
+
+// Parses a comma separated list of resources in the resource.group format, i.e. pods,deployments.apps
+func parsePriority(val string) priorityList {
+	list := priorityList{}
+	for _, res := range strings.Split(val, ",") {
+		res = strings.TrimSpace(res)
+		if res == "" {
+			continue
+		}
+		list = append(list, schema.ParseGroupResource(res))
+	}
+	return list
+}
+
+// Returns the position of the resource in the list, or the length of the list if it isn't in the list.
+func (p priorityList) rank(gvr schema.GroupVersionResource) int {
+	for i, gr := range p {
+		if gr == gvr.GroupResource() {
+			return i
+		}
+	}
+	return len(p)
+}
+
+// Returns true if the informer for the resource should start first and keep running when low priority informers
+// are paused.
+func (p priorityList) isHighPriority(gvr schema.GroupVersionResource) bool {
+	return p.rank(gvr) < len(p)
+}
+
+// Returns the resources sorted by priority. Resources that aren't in the list are sorted by name, so the order of
+// the startup is predictable.
+func (p priorityList) sort(gvrs map[schema.GroupVersionResource]struct{}) []schema.GroupVersionResource {
+	sorted := make([]schema.GroupVersionResource, 0, len(gvrs))
+	for gvr := range gvrs {
+		sorted = append(sorted, gvr)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		ri, rj := p.rank(sorted[i]), p.rank(sorted[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return sorted[i].String() < sorted[j].String()
+	})
+	return sorted
+}
@@ -4,6 +4,8 @@ package informer
 
 import (
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang/glog"
@@ -13,10 +15,89 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
+// Clients used by the informers to discover and watch the resources in a cluster.
+type Clients struct {
+	Discovery discovery.DiscoveryInterface
+	Dynamic   dynamic.Interface
+	Kube      kubernetes.Interface // Used to read the allow/deny lists from the ConfigMap.
+}
+
+// Creates the clients to connect to the cluster at restConfig.
+func NewClients(restConfig *rest.Config) (Clients, error) {
+	discoveryClient, err := config.GetDiscoveryClient(restConfig)
+	if err != nil {
+		return Clients{}, err
+	}
+	dynamicClient, err := config.GetDynamicClient(restConfig)
+	if err != nil {
+		return Clients{}, err
+	}
+	kubeClient, err := config.GetKubeClient(restConfig)
+	if err != nil {
+		return Clients{}, err
+	}
+	return Clients{Discovery: discoveryClient, Dynamic: dynamicClient, Kube: kubeClient}, nil
+}
+
+// Control lets other components change how RunInformers manages the informers.
+// Each cluster has its own Control in multi-cluster mode.
+type Control struct {
+	resync            chan struct{} // Signals RunInformers to synchronize without waiting for the rediscover interval.
+	lowPriorityPaused int32         // Set to 1 to stop the low priority informers. Accessed atomically.
+}
+
+// Creates a Control with room for one pending resync request.
+func NewControl() *Control {
+	return &Control{resync: make(chan struct{}, 1)}
+}
+
+// RequestResync synchronizes the informers as soon as possible. Used when the allow/deny lists change.
+func (c *Control) RequestResync() {
+	select {
+	case c.resync <- struct{}{}:
+	default: // A resync is already pending.
+	}
+}
+
+// ConfigChanged is called by the config watcher. Starts and stops informers to match the new allow/deny lists.
+func (c *Control) ConfigChanged(changes []config.Change) {
+	for _, field := range []string{"AllowedResources", "DeniedResources", "RediscoverRateMS"} {
+		if _, ok := config.FindChange(changes, field); ok {
+			c.RequestResync()
+			return
+		}
+	}
+}
+
+// PauseLowPriority stops the informers for low priority resources, or starts them again when paused is false.
+// Used to shed load when the collector is close to its resource budget.
+func (c *Control) PauseLowPriority(paused bool) {
+	val := int32(0)
+	if paused {
+		val = 1
+	}
+	if atomic.SwapInt32(&c.lowPriorityPaused, val) != val {
+		c.RequestResync()
+	}
+}
+
+func (c *Control) isLowPriorityPaused() bool {
+	return atomic.LoadInt32(&c.lowPriorityPaused) == 1
+}
+
 // Start and manages informers for resources in the cluster.
-func RunInformers(initialized chan interface{}, upsertTransformer tr.Transformer, reconciler *rec.Reconciler) {
+func RunInformers(initialized chan interface{}, shared *config.Shared, clients Clients, control *Control,
+	upsertTransformer tr.Transformer, reconciler *rec.Reconciler) {
+	cfg := shared.Get() // The settings read here require a restart to change.
+
+	// Rate-limits the updates of high-churn resources before they are sent to the transformer.
+	coalescer := NewCoalescer(parseIntervals(cfg.MinUpdateIntervals), upsertTransformer.Enqueue)
+	go coalescer.Run(make(chan struct{}))
 
 	// These functions return handler functions, which are then used in creation of the informers.
 	createInformAddHandler := func(resourceName string) func(interface{}) {
@@ -28,7 +109,7 @@ func RunInformers(initialized chan interface{}, upsertTransformer tr.Transformer
 				Resource:       resource,
 				ResourceString: resourceName,
 			}
-			upsertTransformer.Input <- &upsert // Send resource into the transformer input channel
+			coalescer.Add(&upsert) // Send resource into the transformer input channel
 		}
 	}
 
@@ -41,42 +122,70 @@ func RunInformers(initialized chan interface{}, upsertTransformer tr.Transformer
 				Resource:       resource,
 				ResourceString: resourceName,
 			}
-			upsertTransformer.Input <- &upsert // Send resource into the transformer input channel
+			coalescer.Update(&upsert) // Send resource into the transformer input channel, or hold it if it's too soon
 		}
 	}
 
 	informDeleteHandler := func(obj interface{}) {
 		resource := obj.(*unstructured.Unstructured)
+		coalescer.Forget(string(resource.GetUID()))
 		// We don't actually have anything to transform in the case of a deletion, so we manually construct the NodeEvent
 		ne := tr.NodeEvent{
 			Time:      time.Now().Unix(),
 			Operation: tr.Delete,
 			Node: tr.Node{
-				UID: strings.Join([]string{config.Cfg.ClusterName, string(resource.GetUID())}, "/"),
+				UID: strings.Join([]string{cfg.ClusterName, string(resource.GetUID())}, "/"),
 			},
 		}
-		reconciler.Input <- ne
+		reconciler.Enqueue(ne)
 	}
 
-	// Get kubernetes client for discovering resource types
-	discoveryClient := config.GetDiscoveryClient()
-
 	// We keep each of the informer's stopper channel in a map, so we can stop them if the resource is no longer valid.
 	stoppers := make(map[schema.GroupVersionResource]chan struct{})
 
+	// Close the initialized channel so that we can start the sender, once the high priority informers
+	// are initialized. Lower priority resources are sent as diffs after the first sync.
+	var initializedOnce sync.Once
+	opts := syncOptions{
+		namespace:   cfg.PodNamespace,
+		clusterName: cfg.ClusterName,
+		priority:    parsePriority(cfg.InformerPriority),
+		highPriorityReady: func() {
+			initializedOnce.Do(func() {
+				glog.Info("High priority informers initialized.")
+				close(initialized)
+			})
+		},
+	}
+
 	// Initialize the informers
-	syncInformers(*discoveryClient, stoppers, createInformAddHandler, createInformUpdateHandler, informDeleteHandler)
-	// Close the initialized channel so that we can start the sender.
-	close(initialized)
+	opts.lowPriorityPaused = control.isLowPriorityPaused()
+	syncInformers(clients, opts, stoppers, createInformAddHandler, createInformUpdateHandler, informDeleteHandler)
+	opts.highPriorityReady()
 	// Continue polling to keep the informers synchronized when CRDs are added or deleted in the cluster.
 	for {
-		time.Sleep(time.Duration(config.Cfg.RediscoverRateMS) * time.Millisecond)
-		syncInformers(*discoveryClient, stoppers, createInformAddHandler, createInformUpdateHandler, informDeleteHandler)
+		select {
+		case <-time.After(time.Duration(shared.Get().RediscoverRateMS) * time.Millisecond):
+		case <-control.resync:
+			glog.Info("Synchronizing informers after a config or resource budget change.")
+		}
+		opts.lowPriorityPaused = control.isLowPriorityPaused()
+		syncInformers(clients, opts, stoppers, createInformAddHandler, createInformUpdateHandler, informDeleteHandler)
 	}
 }
 
+// Options for syncInformers.
+type syncOptions struct {
+	namespace         string       // Namespace of the ConfigMap with the allow/deny lists.
+	clusterName       string       // Name of the cluster, to track its non-namespaced resources.
+	priority          priorityList // Resources whose informers start first.
+	lowPriorityPaused bool         // Only keep the informers for the resources in the priority list running.
+	highPriorityReady func()       // Optional. Called once the informers in the priority list are initialized.
+}
+
 // Start or stop informers to match the resources (CRDs) available in the cluster.
-func syncInformers(client discovery.DiscoveryClient,
+// New informers are started in priority order.
+func syncInformers(clients Clients, opts syncOptions,
 	stoppers map[schema.GroupVersionResource]chan struct{},
 	createInformerAddHandler func(string) func(interface{}),
 	createInformerUpdateHandler func(string) func(interface{}, interface{}),
@@ -84,11 +193,19 @@ func syncInformers(client discovery.DiscoveryClient,
 
 	glog.V(2).Infof("Synchronizing informers. Informers running: %d", len(stoppers))
 
-	gvrList, err := SupportedResources(client)
+	gvrList, err := SupportedResources(clients.Discovery, clients.Kube, opts.namespace, opts.clusterName)
 	if err != nil {
 		glog.Error("Failed to get complete list of supported resources: ", err)
 	}
 
+	if opts.lowPriorityPaused {
+		for gvr := range gvrList {
+			if !opts.priority.isHighPriority(gvr) {
+				delete(gvrList, gvr)
+			}
+		}
+	}
+
 	// Sometimes a partial list will be returned even if there is an error.
 	// This could happen during install when a CRD hasn't fully initialized.
 	if gvrList != nil {
@@ -108,10 +225,13 @@ func syncInformers(client discovery.DiscoveryClient,
 		}
 		// Now, loop through the new list, which after the above deletions, contains only stuff that needs to
 		// have a new informer created for it.
-		for gvr := range gvrList {
+		for _, gvr := range opts.priority.sort(gvrList) {
+			if !opts.priority.isHighPriority(gvr) && opts.highPriorityReady != nil {
+				opts.highPriorityReady()
+			}
 			glog.V(2).Infof("Starting informer: %s", gvr.String())
 			// Using our custom informer.
-			informer, _ := InformerForResource(gvr)
+			informer, _ := InformerForResource(gvr, clients.Dynamic)
 
 			// Set up handler to pass this informer's resources into transformer
 			informer.AddFunc = createInformerAddHandler(gvr.Resource)
@@ -8,10 +8,14 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/stolostron/search-collector/pkg/config"
 	"github.com/stretchr/testify/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
 	restclient "k8s.io/client-go/rest"
 )
 
@@ -24,7 +28,12 @@ var mockUpdateFn = func(s string) func(interface{}, interface{}) {
 
 var mockDeleteHandler = func(obj interface{}) {}
 
-func fakeDiscoveryClient() (*httptest.Server, discovery.DiscoveryClient) {
+var testSyncOptions = syncOptions{
+	namespace: "open-cluster-management",
+	priority:  parsePriority(config.DEFAULT_INFORMER_PRIORITY),
+}
+
+func fakeDiscoveryClient() (*httptest.Server, *discovery.DiscoveryClient) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		var obj interface{}
 		switch req.URL.Path {
@@ -60,7 +69,21 @@ func fakeDiscoveryClient() (*httptest.Server, discovery.DiscoveryClient) {
 	}))
 	client := discovery.NewDiscoveryClientForConfigOrDie(&restclient.Config{Host: server.URL})
 
-	return server, *client
+	return server, client
+}
+
+// Returns clients for a cluster with pods, services and namespaces, and no search-collector-config ConfigMap.
+func fakeClients(discoveryClient *discovery.DiscoveryClient) Clients {
+	return Clients{
+		Discovery: discoveryClient,
+		Dynamic: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{
+				{Version: "v1", Resource: "pods"}:       "PodList",
+				{Version: "v1", Resource: "services"}:   "ServiceList",
+				{Version: "v1", Resource: "namespaces"}: "NamespaceList",
+			}),
+		Kube: fake.NewSimpleClientset(),
+	}
 }
 
 func Test_syncInformers(t *testing.T) {
@@ -70,7 +93,8 @@ func Test_syncInformers(t *testing.T) {
 	fakeServer, fakeClient := fakeDiscoveryClient()
 	defer fakeServer.Close()
 
-	syncInformers(fakeClient, mockStoppers, mockAddFn, mockUpdateFn, mockDeleteHandler)
+	syncInformers(fakeClients(fakeClient), testSyncOptions, mockStoppers, mockAddFn, mockUpdateFn,
+		mockDeleteHandler)
 
 	assert.Equal(t, 3, len(mockStoppers))
 
@@ -88,7 +112,8 @@ func Test_syncInformers_removeInformers(t *testing.T) {
 	fakeServer, fakeClient := fakeDiscoveryClient()
 	defer fakeServer.Close()
 
-	syncInformers(fakeClient, mockStoppers, mockAddFn, mockUpdateFn, mockDeleteHandler)
+	syncInformers(fakeClients(fakeClient), testSyncOptions, mockStoppers, mockAddFn, mockUpdateFn,
+		mockDeleteHandler)
 
 	assert.Equal(t, 3, len(mockStoppers))
 
@@ -97,3 +122,78 @@ func Test_syncInformers_removeInformers(t *testing.T) {
 	assert.False(t, exists)
 	assert.Nil(t, informStopper)
 }
+
+// Validate that low priority informers are stopped when paused, and started again when resumed.
+func Test_syncInformers_pauseLowPriority(t *testing.T) {
+	mockStoppers := make(map[schema.GroupVersionResource]chan struct{})
+
+	fakeServer, fakeClient := fakeDiscoveryClient()
+	defer fakeServer.Close()
+
+	discoveredGVRs := []schema.GroupVersionResource{
+		{Version: "v1", Resource: "pods"}, {Version: "v1", Resource: "services"}, {Version: "v1", Resource: "namespaces"},
+	}
+	// Pods is a low priority resource for this test.
+	opts := syncOptions{namespace: "open-cluster-management", priority: parsePriority("namespaces,services"),
+		lowPriorityPaused: true}
+
+	syncInformers(fakeClients(fakeClient), opts, mockStoppers, mockAddFn, mockUpdateFn, mockDeleteHandler)
+	assert.Equal(t, 2, len(mockStoppers))
+	_, exists := mockStoppers[discoveredGVRs[0]]
+	assert.False(t, exists, "Should not start low priority informers while paused")
+
+	syncInformers(fakeClients(fakeClient), testSyncOptions, mockStoppers, mockAddFn, mockUpdateFn,
+		mockDeleteHandler)
+	assert.Equal(t, 3, len(mockStoppers))
+}
+
+// Validate that informers start in priority order, and the high priority set is reported ready before the rest.
+func Test_syncInformers_priorityOrder(t *testing.T) {
+	mockStoppers := make(map[schema.GroupVersionResource]chan struct{})
+
+	fakeServer, fakeClient := fakeDiscoveryClient()
+	defer fakeServer.Close()
+
+	started := []string{}
+	opts := syncOptions{
+		namespace:         "open-cluster-management",
+		priority:          parsePriority("services,namespaces"),
+		highPriorityReady: func() { started = append(started, "ready") },
+	}
+	addFn := func(resource string) func(interface{}) {
+		started = append(started, resource)
+		return func(o interface{}) {}
+	}
+
+	syncInformers(fakeClients(fakeClient), opts, mockStoppers, addFn, mockUpdateFn, mockDeleteHandler)
+
+	assert.Equal(t, []string{"services", "namespaces", "ready", "pods"}, started)
+}
+
+func Test_parsePriority(t *testing.T) {
+	p := parsePriority(" pods, deployments.apps,,subscriptions.apps.open-cluster-management.io")
+
+	assert.Equal(t, priorityList{
+		{Resource: "pods"},
+		{Group: "apps", Resource: "deployments"},
+		{Group: "apps.open-cluster-management.io", Resource: "subscriptions"},
+	}, p)
+	assert.Equal(t, 1, p.rank(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}))
+	assert.False(t, p.isHighPriority(schema.GroupVersionResource{Version: "v1", Resource: "secrets"}))
+}
+
+func Test_Control_PauseLowPriority(t *testing.T) {
+	c := NewControl()
+
+	c.PauseLowPriority(true)
+	assert.True(t, c.isLowPriorityPaused())
+	assert.Len(t, c.resync, 1, "Should request a resync when paused")
+
+	<-c.resync
+	c.PauseLowPriority(true)
+	assert.Len(t, c.resync, 0, "Should not request a resync if nothing changed")
+
+	c.PauseLowPriority(false)
+	assert.False(t, c.isLowPriorityPaused())
+	assert.Len(t, c.resync, 1)
+}
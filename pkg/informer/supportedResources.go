@@ -15,6 +15,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
 )
 
 type Resource struct {
@@ -44,6 +45,7 @@ func GetAllowDenyData(cm *v1.ConfigMap) ([]Resource, []Resource, error, error) {
 func isResourceAllowed(group, kind string, allowedList []Resource, deniedList []Resource) bool {
 	// Ignore clusters and clusterstatus resources because these are handled by the aggregator.
 	// Ignore oauthaccesstoken resources because those cause too much noise on OpenShift clusters.
+	// Ignore events because they are too noisy. The Warning events are added to their objects by RunEventsWatcher.
 	// Ignore projects as namespaces are overwritten to be projects on Openshift clusters - they tend to share
 	// the same uid.
 	list := []string{"events", "projects", "clusters", "clusterstatuses", "oauthaccesstokens"}
@@ -100,7 +102,10 @@ func isResourceMatchingList(resourceList []Resource, group, kind string) (string
 }
 
 // Returns a map containing all the GVRs on the cluster of resources that support WATCH (ignoring clusters and events).
-func SupportedResources(discoveryClient discovery.DiscoveryClient) (map[schema.GroupVersionResource]struct{}, error) {
+// The allow/deny lists are read from the search-collector-config ConfigMap in namespace.
+// Also updates the kinds of the non-namespaced resources in the cluster named clusterName.
+func SupportedResources(discoveryClient discovery.DiscoveryInterface, kubeClient kubernetes.Interface,
+	namespace, clusterName string) (map[schema.GroupVersionResource]struct{}, error) {
 	ctx := context.TODO()
 	// Next step is to discover all the gettable resource types that the kuberenetes api server knows about.
 	supportedResources := []*machineryV1.APIResourceList{}
@@ -112,21 +117,21 @@ func SupportedResources(discoveryClient discovery.DiscoveryClient) (map[schema.G
 	} else if err != nil {
 		glog.Warning("ServerPreferredResources could not list all available resources: ", err)
 	}
-
-	// create client to get configmap
-	kubeClient := config.GetKubeClient(config.GetKubeConfig())
+	discoveryErr := err
 
 	// locate the search-collector-config ConfigMap
-	cm, cmErr := kubeClient.CoreV1().ConfigMaps(config.Cfg.PodNamespace).
-		Get(ctx, "search-collector-config", metav1.GetOptions{})
+	cm, cmErr := kubeClient.CoreV1().ConfigMaps(namespace).
+		Get(ctx, config.COLLECTOR_CONFIGMAP, metav1.GetOptions{})
 	if cmErr != nil {
 		glog.Info("Collecting all resources. ConfigMap search-collector-config is not present.", cmErr)
+		cm = &v1.ConfigMap{}
 	}
 
 	// parse alloy/deny from config
 	allowedList, deniedList, _, _ := GetAllowDenyData(cm)
 
-	tr.NonNSResourceMap = make(map[string]struct{}) //map to store non-namespaced resources
+	// Kinds of the non-namespaced resources, replaces the ones from the previous discovery.
+	nonNamespaced := make(map[string]struct{})
 
 	// Filter down to only resources which support WATCH operations
 	for _, apiList := range apiResources { // This comes out in a nested list, so loop through a couple things
@@ -143,14 +148,8 @@ func SupportedResources(discoveryClient discovery.DiscoveryClient) (map[schema.G
 				continue // Skip the resource before starting the informer
 			}
 
-			// add non-namespaced resource to NonNSResourceMap
 			if !apiResource.Namespaced {
-				tr.NonNSResMapMutex.Lock()
-				if _, ok := tr.NonNSResourceMap[apiResource.Kind]; !ok {
-					tr.NonNSResourceMap[apiResource.Kind] = struct{}{}
-				}
-				tr.NonNSResMapMutex.Unlock()
-
+				nonNamespaced[apiResource.Kind] = struct{}{}
 			}
 			for _, verb := range apiResource.Verbs {
 				if verb == "watch" {
@@ -163,6 +162,16 @@ func SupportedResources(discoveryClient discovery.DiscoveryClient) (map[schema.G
 		supportedResources = append(supportedResources, &watchList)
 	}
 
+	// When some groups couldn't be discovered, keep their kinds from the previous discovery.
+	if discoveryErr != nil {
+		tr.NonNSResMapMutex.RLock()
+		for kind := range tr.NonNSResourceMap[clusterName] {
+			nonNamespaced[kind] = struct{}{}
+		}
+		tr.NonNSResMapMutex.RUnlock()
+	}
+	tr.SetNonNSResources(clusterName, nonNamespaced)
+
 	// Use handy converter function to convert into GroupVersionResource objects, which we need in order to make informers
 	gvrList, err := discovery.GroupVersionResources(supportedResources)
 
@@ -4,6 +4,7 @@ package lease
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"time"
 
@@ -15,6 +16,9 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
+// Annotation on the lease with the status conditions of the collector resource budget, as a JSON list.
+const BudgetConditionsAnnotation = "search.open-cluster-management.io/resource-budget-conditions"
+
 // LeaseReconciler reconciles a Secret object
 type LeaseReconciler struct {
 	HubKubeClient        kubernetes.Interface
@@ -22,6 +26,8 @@ type LeaseReconciler struct {
 	LeaseName            string
 	LeaseDurationSeconds int32
 	ClusterName          string
+	Config               *config.Shared            // Used to rebuild the kube clients when the lease can't be updated.
+	Conditions           func() []metav1.Condition // Optional. Published in the BudgetConditionsAnnotation.
 	componentNamespace   string
 }
 
@@ -68,6 +74,7 @@ func (r *LeaseReconciler) updateLease(namespace string, client kubernetes.Interf
 				},
 			},
 		}
+		r.annotateConditions(lease)
 		if _, err := client.CoordinationV1().Leases(namespace).Create(context, lease, metav1.CreateOptions{}); err != nil {
 			glog.Errorf("Unable to create addon lease %q/%q . error:%v", namespace, r.LeaseName, err)
 
@@ -84,6 +91,7 @@ func (r *LeaseReconciler) updateLease(namespace string, client kubernetes.Interf
 	default:
 		// update lease
 		lease.Spec.RenewTime = &metav1.MicroTime{Time: time.Now()}
+		r.annotateConditions(lease)
 		if _, err = client.CoordinationV1().Leases(namespace).Update(context, lease, metav1.UpdateOptions{}); err != nil {
 			glog.Errorf("Unable to update cluster lease %q/%q . error:%v", namespace, r.LeaseName, err)
 
@@ -96,6 +104,22 @@ func (r *LeaseReconciler) updateLease(namespace string, client kubernetes.Interf
 	}
 }
 
+// Sets the annotation with the status conditions of the resource budget, if the reconciler has Conditions.
+func (r *LeaseReconciler) annotateConditions(lease *coordinationv1.Lease) {
+	if r.Conditions == nil {
+		return
+	}
+	conditions, err := json.Marshal(r.Conditions())
+	if err != nil {
+		glog.Error("Unable to encode the resource budget conditions. ", err)
+		return
+	}
+	if lease.Annotations == nil {
+		lease.Annotations = map[string]string{}
+	}
+	lease.Annotations[BudgetConditionsAnnotation] = string(conditions)
+}
+
 func getPodNamespace() string {
 	if collectorPodNamespace, ok := os.LookupEnv("POD_NAMESPACE"); ok {
 		return collectorPodNamespace
@@ -104,7 +128,30 @@ func getPodNamespace() string {
 }
 
 func (r *LeaseReconciler) reloadClient() {
-	config.InitConfig()
-	r.HubKubeClient = config.GetKubeClient(config.Cfg.AggregatorConfig)
-	r.LocalKubeClient = config.GetKubeClient(config.GetKubeConfig())
+	if r.Config == nil {
+		return
+	}
+	if err := r.Config.ReloadHubConfig(); err != nil {
+		glog.Error("Unable to reload the hub config. ", err)
+		return
+	}
+	cfg := r.Config.Get()
+	hubClient, err := config.GetKubeClient(cfg.AggregatorConfig)
+	if err != nil {
+		glog.Error("Unable to create the hub kube client. ", err)
+		return
+	}
+	r.HubKubeClient = hubClient
+
+	localConfig, err := config.GetKubeConfig(cfg.KubeConfig)
+	if err != nil {
+		glog.Error("Unable to load the local kube config. ", err)
+		return
+	}
+	localClient, err := config.GetKubeClient(localConfig)
+	if err != nil {
+		glog.Error("Unable to create the local kube client. ", err)
+		return
+	}
+	r.LocalKubeClient = localClient
 }
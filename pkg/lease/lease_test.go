@@ -57,3 +57,27 @@ func TestUpdateLeaseAddon(t *testing.T) {
 	assert.True(t, createdTime.Before(updatedTime), "Expected lease renewtime to be updated and 'true' value to be returned. Got %b.", createdTime.Before(updatedTime))
 
 }
+
+func TestLeaseBudgetConditions(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	status := metav1.ConditionFalse
+
+	leaseReconciler := LeaseReconciler{
+		LocalKubeClient:      client,
+		LeaseName:            AddonName,
+		LeaseDurationSeconds: int32(LeaseDurationSeconds),
+		Conditions: func() []metav1.Condition {
+			return []metav1.Condition{{Type: "ReportIntervalWidened", Status: status, Reason: "WithinBudget"}}
+		},
+	}
+	leaseReconciler.Reconcile()
+	lease, err := client.CoordinationV1().Leases(namespace).Get(contextVar, AddonName, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Contains(t, lease.Annotations[BudgetConditionsAnnotation], `"status":"False"`)
+
+	status = metav1.ConditionTrue
+	leaseReconciler.Reconcile()
+	lease, err = client.CoordinationV1().Leases(namespace).Get(contextVar, AddonName, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Contains(t, lease.Annotations[BudgetConditionsAnnotation], `"status":"True"`)
+}
@@ -0,0 +1,191 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package queue
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// Queue is a bounded queue between two stages of the collector pipeline (i.e. informers to transformer).
+// Items are kept in a lane for each key (the resource, i.e. pods or deployments.apps), each lane holding up
+// to the lane capacity. Put blocks while the lane for the key is full, so a resource that floods the queue
+// only slows down its own producer. Items are handed out from the lanes in round-robin, so the other resources
+// aren't starved waiting behind the flooding one.
+type Queue[T any] struct {
+	name         string
+	laneCapacity int
+	out          chan T
+	forwardOnce  sync.Once
+	mutex        sync.Mutex
+	notEmpty     *sync.Cond // Signaled when an item is added.
+	notFull      *sync.Cond // Broadcast when an item is removed.
+	lanes        map[string][]T
+	keys         []string // Keys of the non-empty lanes, in round-robin order.
+	stats        Stats
+	blocked      map[string]time.Duration // Time the producers of each key spent blocked.
+}
+
+// Metrics of the queue since it was created.
+type Stats struct {
+	Name         string
+	Depth        int            // Items currently in the queue.
+	LaneCapacity int            // Maximum items in each lane.
+	LaneDepth    map[string]int // Items currently in each lane.
+	Puts         int64          // Items added.
+	Gets         int64          // Items handed out.
+	BlockedPuts  int64          // Puts that waited for room in a full lane.
+	BlockedTime  time.Duration  // Total time the producers spent blocked on full lanes.
+	// Time the producers spent blocked for each key. Only keys that blocked are included.
+	BlockedTimeByKey map[string]time.Duration
+}
+
+// Creates a queue with laneCapacity items per key.
+func New[T any](name string, laneCapacity int) *Queue[T] {
+	if laneCapacity < 1 {
+		glog.Warningf("%d is an invalid capacity for queue %s. Using 1 instead.", laneCapacity, name)
+		laneCapacity = 1
+	}
+	q := &Queue[T]{
+		name:         name,
+		laneCapacity: laneCapacity,
+		out:          make(chan T),
+		lanes:        make(map[string][]T),
+		blocked:      make(map[string]time.Duration),
+	}
+	q.notEmpty = sync.NewCond(&q.mutex)
+	q.notFull = sync.NewCond(&q.mutex)
+	return q
+}
+
+// Adds the item to the lane for key. Blocks while the lane is full.
+func (q *Queue[T]) Put(key string, item T) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if len(q.lanes[key]) >= q.laneCapacity {
+		start := time.Now()
+		for len(q.lanes[key]) >= q.laneCapacity {
+			q.notFull.Wait()
+		}
+		waited := time.Since(start)
+		q.stats.BlockedPuts++
+		q.stats.BlockedTime += waited
+		q.blocked[key] += waited
+		glog.V(4).Infof("Queue %s lane %s was full. Blocked for %s", q.name, key, waited)
+	}
+	if len(q.lanes[key]) == 0 {
+		q.keys = append(q.keys, key)
+	}
+	q.lanes[key] = append(q.lanes[key], item)
+	q.stats.Puts++
+	q.notEmpty.Signal()
+}
+
+// Returns the channel where the items are handed out. Starts handing out the items on the first call.
+func (q *Queue[T]) Out() chan T {
+	q.forwardOnce.Do(func() { go q.forward() })
+	return q.out
+}
+
+// Removes the next item, taking one item from each non-empty lane in turn. Blocks while the queue is empty.
+func (q *Queue[T]) get() T {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for len(q.keys) == 0 {
+		q.notEmpty.Wait()
+	}
+	key := q.keys[0]
+	q.keys = q.keys[1:]
+	lane := q.lanes[key]
+	item := lane[0]
+	var zero T
+	lane[0] = zero // Release the reference for the garbage collector.
+	if len(lane) == 1 {
+		delete(q.lanes, key)
+	} else {
+		q.lanes[key] = lane[1:]
+		q.keys = append(q.keys, key) // Go to the back of the round-robin.
+	}
+	q.stats.Gets++
+	q.notFull.Broadcast()
+	return item
+}
+
+// Hands out the items on the out channel. Runs for the life of the queue.
+func (q *Queue[T]) forward() {
+	for {
+		q.out <- q.get()
+	}
+}
+
+// Returns a snapshot of the queue metrics.
+func (q *Queue[T]) Stats() Stats {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	stats := q.stats
+	stats.Name = q.name
+	stats.LaneCapacity = q.laneCapacity
+	stats.LaneDepth = make(map[string]int, len(q.lanes))
+	for key, lane := range q.lanes {
+		stats.LaneDepth[key] = len(lane)
+		stats.Depth += len(lane)
+	}
+	stats.BlockedTimeByKey = make(map[string]time.Duration, len(q.blocked))
+	for key, d := range q.blocked {
+		stats.BlockedTimeByKey[key] = d
+	}
+	return stats
+}
+
+// Logs the queue metrics. The lanes are listed from the deepest, to show which resources cause back-pressure.
+// Logs a warning when producers were blocked since the previous log, otherwise logs with verbosity 2.
+func (q *Queue[T]) logStats(previous Stats) Stats {
+	stats := q.Stats()
+	keys := make([]string, 0, len(stats.LaneDepth))
+	for key := range stats.LaneDepth {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if stats.LaneDepth[keys[i]] != stats.LaneDepth[keys[j]] {
+			return stats.LaneDepth[keys[i]] > stats.LaneDepth[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > 5 {
+		keys = keys[:5]
+	}
+	deepest := make(map[string]int, len(keys))
+	for _, key := range keys {
+		deepest[key] = stats.LaneDepth[key]
+	}
+	if stats.BlockedPuts > previous.BlockedPuts {
+		glog.Warningf("Queue %s is applying back-pressure. %d puts blocked for %s since the last check. "+
+			"depth=%d deepestLanes=%v", stats.Name, stats.BlockedPuts-previous.BlockedPuts,
+			stats.BlockedTime-previous.BlockedTime, stats.Depth, deepest)
+	} else {
+		glog.V(2).Infof("Queue %s: depth=%d puts=%d gets=%d blockedPuts=%d blockedTime=%s deepestLanes=%v",
+			stats.Name, stats.Depth, stats.Puts, stats.Gets, stats.BlockedPuts, stats.BlockedTime, deepest)
+	}
+	return stats
+}
+
+// Logs the queue metrics every interval until stopper is closed.
+func (q *Queue[T]) RunStats(interval time.Duration, stopper <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	previous := Stats{}
+	for {
+		select {
+		case <-stopper:
+			return
+		case <-ticker.C:
+			previous = q.logStats(previous)
+		}
+	}
+}
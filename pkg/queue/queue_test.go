@@ -0,0 +1,84 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Verify items are handed out in round-robin across keys, so a flooding key doesn't starve the others.
+func Test_Queue_fairness(t *testing.T) {
+	q := New[string]("test", 10)
+	for i := 0; i < 5; i++ {
+		q.Put("pods", "pod")
+	}
+	q.Put("services", "service")
+	q.Put("nodes", "node")
+
+	got := []string{}
+	for i := 0; i < 4; i++ {
+		got = append(got, q.get())
+	}
+
+	assert.Equal(t, []string{"pod", "service", "node", "pod"}, got)
+}
+
+// Verify Put blocks while the lane is full, without blocking the other keys, and the blocked time is recorded.
+func Test_Queue_backPressure(t *testing.T) {
+	q := New[int]("test", 2)
+	q.Put("pods", 1)
+	q.Put("pods", 2)
+
+	blocked := make(chan struct{})
+	go func() {
+		q.Put("pods", 4)
+		close(blocked)
+	}()
+
+	q.Put("services", 10) // Another key isn't blocked by the full lane.
+
+	select {
+	case <-blocked:
+		t.Fatal("Expected Put to block while the lane is full.")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	assert.Equal(t, 1, q.get()) // Make room in the pods lane.
+	select {
+	case <-blocked:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Put to continue after the lane had room.")
+	}
+
+	stats := q.Stats()
+	assert.Equal(t, int64(4), stats.Puts)
+	assert.Equal(t, int64(1), stats.BlockedPuts)
+	assert.GreaterOrEqual(t, stats.BlockedTime, 50*time.Millisecond)
+	assert.Equal(t, stats.BlockedTime, stats.BlockedTimeByKey["pods"])
+	assert.Equal(t, 2, stats.LaneCapacity)
+	assert.Equal(t, map[string]int{"pods": 2, "services": 1}, stats.LaneDepth)
+	assert.Equal(t, 3, stats.Depth)
+}
+
+// Verify the items are handed out on the Out channel.
+func Test_Queue_Out(t *testing.T) {
+	q := New[string]("test", 10)
+	q.Put("pods", "pod")
+
+	select {
+	case item := <-q.Out():
+		assert.Equal(t, "pod", item)
+	case <-time.After(time.Second):
+		t.Fatal("Expected the item on the Out channel.")
+	}
+}
+
+// Verify an invalid capacity defaults to 1.
+func Test_Queue_invalidCapacity(t *testing.T) {
+	q := New[int]("test", 0)
+
+	assert.Equal(t, 1, q.Stats().LaneCapacity)
+}
@@ -0,0 +1,66 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package reconciler
+
+import (
+	"reflect"
+
+	tr "github.com/stolostron/search-collector/pkg/transforms"
+)
+
+// Properties from the k8s events of an object, like its last Warning, aren't part of the resource. They are
+// kept apart and added to each version of the node we receive.
+
+// Sets the properties from the events of the node with the UID, replacing the previous ones. Removes them when
+// props is empty. If we already have the node, it's updated in the next diff.
+func (r *Reconciler) SetEventProperties(uid string, props map[string]interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	previous := r.eventProperties[uid]
+	if len(props) == 0 {
+		delete(r.eventProperties, uid)
+	} else {
+		r.eventProperties[uid] = props
+	}
+
+	node, ok := r.currentNodes[uid]
+	if !ok {
+		return // The properties are added when we receive the node.
+	}
+	// Copy the properties, the previous state shares the map.
+	updated := node
+	updated.Properties = make(map[string]interface{}, len(node.Properties)+len(props))
+	for key, value := range node.Properties {
+		if _, ok := previous[key]; !ok {
+			updated.Properties[key] = value
+		}
+	}
+	for key, value := range props {
+		updated.Properties[key] = value
+	}
+	if reflect.DeepEqual(updated.Properties, node.Properties) {
+		return
+	}
+
+	r.currentNodes[uid] = updated
+	if ne, inDiff := r.diffNodes[uid]; inDiff {
+		ne.Node = updated
+		r.diffNodes[uid] = ne
+		return
+	}
+	operation := tr.Create
+	if _, inPrevious := r.previousNodes[uid]; inPrevious {
+		operation = tr.Update
+	}
+	// Without a time, so it doesn't hide the next version of the resource.
+	r.diffNodes[uid] = tr.NodeEvent{Node: updated, ComputeEdges: r.edgeFuncs[uid], Operation: operation}
+}
+
+// Adds the properties from the events to a new version of the node.
+// NOT THREADSAFE, locking left up to the caller.
+func (r *Reconciler) addEventProperties(node tr.Node) {
+	for key, value := range r.eventProperties[node.UID] {
+		node.Properties[key] = value
+	}
+}
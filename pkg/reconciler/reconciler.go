@@ -14,9 +14,11 @@ package reconciler
 import (
 	"reflect"
 	"sync"
+	"time"
 
 	"github.com/golang/glog"
 	lru "github.com/golang/groupcache/lru"
+	"github.com/stolostron/search-collector/pkg/queue"
 	tr "github.com/stolostron/search-collector/pkg/transforms"
 )
 
@@ -78,16 +80,44 @@ type Reconciler struct {
 	previousEventEdges map[string]tr.Edge                         // Keyed by UID
 	edgeFuncs          map[string]func(ns tr.NodeStore) []tr.Edge // Edge building functions, keyed by UID
 
-	previousEdges map[string]map[string]tr.Edge // Keyed by source then dest so we can quickly compare the new list
-	totalEdges    int                           // Save the total count as we build to avoid looping when needed
+	previousEdges   map[string]map[string]tr.Edge // Keyed by source then destKey so we can quickly compare the new list
+	recomputedNodes []string                      // UIDs of the nodes with computed properties changed by the edges
+	totalEdges      int                           // Save the total count as we build to avoid looping when needed
+
+	syntheticRefs     map[string]map[string]struct{}    // UIDs of the nodes referencing each synthetic node
+	syntheticBySource map[string]map[string]struct{}    // UIDs of the synthetic nodes referenced by each node
+	eventProperties   map[string]map[string]interface{} // Properties from the k8s events, keyed by node UID
 
 	Input       chan tr.NodeEvent
-	mutex       sync.Mutex // Used to protect currentState and diffState as they are accessed by multiple goroutines
-	purgedNodes *lru.Cache // Tracks deleted nodes, so the reconciler can prevent out of order processing of events
+	Queue       *queue.Queue[tr.NodeEvent] // Optional bounded queue that feeds Input. Use Enqueue to send the events.
+	mutex       sync.Mutex                 // Used to protect currentState and diffState as they are accessed by multiple goroutines
+	purgedNodes *lru.Cache                 // Tracks deleted nodes, so the reconciler can prevent out of order processing of events
+
+	quarantinedNodes map[string]QuarantinedNode // Nodes rejected by the aggregator, keyed by UID
+	retries          map[string]RetryStatus     // Nodes and edges the aggregator failed to apply
+	maxSyncRetries   int                        // Times to resend a node or edge the aggregator failed to apply
+}
+
+// Options to tune the reconciler. Zero values use the defaults.
+type Options struct {
+	PurgedCacheSize int // Number of deleted nodes to track to detect out of order events. Defaults to CACHE_SIZE.
+	MaxSyncRetries  int // Times to resend a node or edge the aggregator failed to apply. Defaults to MAX_SYNC_RETRIES.
+}
+
+// A node rejected by the aggregator. It won't be sent again until the resource changes.
+type QuarantinedNode struct {
+	Node   tr.Node // The version of the node that was rejected.
+	Reason string  // Message from the aggregator.
 }
 
 // Creates a new Reconciler with a nil Input. To use it, set the Input and then start sending things through.
-func NewReconciler() *Reconciler {
+func NewReconciler(opts Options) *Reconciler {
+	if opts.PurgedCacheSize <= 0 {
+		opts.PurgedCacheSize = CACHE_SIZE
+	}
+	if opts.MaxSyncRetries <= 0 {
+		opts.MaxSyncRetries = MAX_SYNC_RETRIES
+	}
 	r := &Reconciler{
 		currentNodes:       make(map[string]tr.Node),
 		previousNodes:      make(map[string]tr.Node),
@@ -95,9 +125,16 @@ func NewReconciler() *Reconciler {
 		k8sEventNodes:      make(map[string]tr.NodeEvent),
 		previousEventEdges: make(map[string]tr.Edge),
 		edgeFuncs:          make(map[string]func(ns tr.NodeStore) []tr.Edge),
+		syntheticRefs:      make(map[string]map[string]struct{}),
+		syntheticBySource:  make(map[string]map[string]struct{}),
+		eventProperties:    make(map[string]map[string]interface{}),
 
 		mutex:       sync.Mutex{},
-		purgedNodes: lru.New(CACHE_SIZE),
+		purgedNodes: lru.New(opts.PurgedCacheSize),
+
+		quarantinedNodes: make(map[string]QuarantinedNode),
+		retries:          make(map[string]RetryStatus),
+		maxSyncRetries:   opts.MaxSyncRetries,
 	}
 
 	go r.receive() // start it listening on input channel
@@ -105,6 +142,20 @@ func NewReconciler() *Reconciler {
 	return r
 }
 
+// Sends the event to the reconciler. With a Queue, blocks while the lane for the resource is full.
+// Deletions don't have the resource, so they share a lane.
+func (r *Reconciler) Enqueue(ne tr.NodeEvent) {
+	if r.Queue == nil {
+		r.Input <- ne
+		return
+	}
+	key := ne.ResourceString
+	if ne.Operation == tr.Delete {
+		key = "deletions"
+	}
+	r.Queue.Put(key, ne)
+}
+
 // Returns the diff between the current and previous states, and resets the diff.
 // TODO the latter half of this function got pretty messy, it could use a refactor/rewrite
 func (r *Reconciler) Diff() Diff {
@@ -113,6 +164,9 @@ func (r *Reconciler) Diff() Diff {
 
 	ret := Diff{}
 
+	// Fill out edges. Builds the edges first, the diff nodes get the properties computed with the edges.
+	newEdges := r.allEdges()
+
 	// Fill out nodes
 	for _, ne := range r.diffNodes {
 		if ne.Operation == tr.Create {
@@ -124,8 +178,12 @@ func (r *Reconciler) Diff() Diff {
 		}
 	}
 
-	// Fill out edges
-	newEdges := r.allEdges()
+	// Update the nodes with properties computed while building the edges, i.e. the pods running on a Node.
+	for _, uid := range r.recomputedNodes {
+		if _, inDiff := r.diffNodes[uid]; !inDiff {
+			ret.UpdateNodes = append(ret.UpdateNodes, r.currentNodes[uid])
+		}
+	}
 
 	// TODO combine the following 2 loops?
 
@@ -134,10 +192,10 @@ func (r *Reconciler) Diff() Diff {
 	// TODO shortcut this by checking whether one of the src/dest doesn't exist any more
 	// (could delete the whole map in the case of srcUID missing)
 	for srcUID, destMap := range newEdges {
-		for destUID, newEdge := range destMap {
+		for key, newEdge := range destMap {
 			// If it's present in this loop it's obviously in the new set, so check the old.
-			if _, ok := r.previousEdges[srcUID][destUID]; ok {
-				delete(r.previousEdges[srcUID], destUID)
+			if _, ok := r.previousEdges[srcUID][key]; ok {
+				delete(r.previousEdges[srcUID], key)
 			} else { // If it's in the new and NOT the old, it's an edge that's been added
 				ret.AddEdges = append(ret.AddEdges, newEdge)
 			}
@@ -147,7 +205,7 @@ func (r *Reconciler) Diff() Diff {
 	// Now go back through the remains of the previous and coerce to slice of edges to be deleted
 	for srcUID, destMap := range r.previousEdges {
 		srcDeleted := false // flag to check if the sourceNode is in ret.DeleteNodes
-		for destUID, oldEdge := range destMap {
+		for key, oldEdge := range destMap {
 			destDeleted := false // flag to check if the destNode is in ret.DeleteNodes
 			// Loop through ret.DeleteNodes and check if the source or destination nodes are up for delete.
 			// Since the associated edges gets deleted automatically when the node is deleted,
@@ -158,9 +216,9 @@ func (r *Reconciler) Diff() Diff {
 					delete(r.previousEdges, srcUID)
 					srcDeleted = true
 					break
-				} else if destUID == delNode.UID {
-					// If the srcUID is in ret.DeleteNodes, delete the edge from previousEdges
-					delete(r.previousEdges[srcUID], destUID)
+				} else if oldEdge.DestUID == delNode.UID {
+					// If the destUID is in ret.DeleteNodes, delete the edge from previousEdges
+					delete(r.previousEdges[srcUID], key)
 					destDeleted = true
 				}
 			}
@@ -190,6 +248,9 @@ func (r *Reconciler) Complete() CompleteState {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
+	// Builds the edges first, the nodes get the properties computed with the edges.
+	newEdges := r.allEdges()
+
 	allNodes := make([]tr.Node, 0, len(r.currentNodes)) // We know the size ahead of time
 	for _, n := range r.currentNodes {
 		allNodes = append(allNodes, n)
@@ -199,8 +260,6 @@ func (r *Reconciler) Complete() CompleteState {
 		Nodes: allNodes,
 	}
 
-	newEdges := r.allEdges()
-
 	// Coerce to array
 	for _, destMap := range newEdges {
 		for _, newEdge := range destMap {
@@ -220,15 +279,19 @@ func (r *Reconciler) Complete() CompleteState {
 }
 
 // Builds all edges for all the nodes.
-// Keyed by srcUID then destUID for fast comparison with previous.
-// This function reads from the state, locking left up to caller (complete and diff methods)
+// Keyed by srcUID then destKey for fast comparison with previous.
+// Applies the properties computed while building the edges to the nodes.
+// Locking left up to caller (complete and diff methods)
 func (r *Reconciler) allEdges() map[string]map[string]tr.Edge {
 	ret := make(map[string]map[string]tr.Edge)
 
 	ns := tr.NodeStore{
 		ByUID:               r.currentNodes,
 		ByKindNamespaceName: nodeTripleMap(r.currentNodes),
+		PodsByNodeName:      tr.IndexPodsByNodeName(r.currentNodes),
+		Computed:            make(map[string]map[string]interface{}),
 	}
+	r.recomputedNodes = r.recomputedNodes[:0]
 
 	// After building the nodestore, get all the application UIDs in appUIDs and others in otherUIDs.
 	// Process the application nodes first while building edges so that _hostingApplication metadata
@@ -262,10 +325,14 @@ func (r *Reconciler) allEdges() map[string]map[string]tr.Edge {
 			if _, ok := ret[edge.SourceUID]; !ok { // Init if it's not there
 				ret[edge.SourceUID] = make(map[string]tr.Edge)
 			}
-			ret[edge.SourceUID][edge.DestUID] = edge
+			ret[edge.SourceUID][destKey(edge)] = edge
 		}
 	}
 
+	for uid, props := range ns.Computed {
+		r.applyComputedProperties(uid, props)
+	}
+
 	totalEdges := 0
 	// loop over double map to get the total number we added
 	for _, destUID := range ret {
@@ -276,6 +343,32 @@ func (r *Reconciler) allEdges() map[string]map[string]tr.Edge {
 	return ret
 }
 
+// Updates the node with the properties computed while building the edges. The node is copied, the previous state
+// shares its maps. Nodes with a change are added to recomputedNodes, or updated in the diff if they are already there.
+// NOT THREADSAFE, locking left up to the caller.
+func (r *Reconciler) applyComputedProperties(uid string, props map[string]interface{}) {
+	node, ok := r.currentNodes[uid]
+	if !ok {
+		return
+	}
+	updated, changed := tr.WithComputedProperties(node, props)
+	r.currentNodes[uid] = updated
+	if !changed {
+		return
+	}
+	if ne, inDiff := r.diffNodes[uid]; inDiff && ne.Operation != tr.Delete {
+		ne.Node = updated
+		r.diffNodes[uid] = ne
+	}
+	r.recomputedNodes = append(r.recomputedNodes, uid)
+}
+
+// Key of an edge in the map of edges from its source. Includes the edge type, so edges of different types between
+// the same nodes (i.e. ownedBy and propagatedFrom) are all kept.
+func destKey(edge tr.Edge) string {
+	return edge.DestUID + "/" + string(edge.EdgeType)
+}
+
 // This method takes a channel and constantly receives from it, reconciling the input with whatever is currently stored
 func (r *Reconciler) receive() {
 	glog.Info("Reconciler Routine Started")
@@ -313,10 +406,29 @@ func (r *Reconciler) reconcileNode() {
 
 	previousNode, inPrevious := r.previousNodes[ne.Node.UID]
 
+	if ne.Operation != tr.Delete {
+		r.addEventProperties(ne.Node)
+	}
+
+	// Skip quarantined nodes until the resource changes. The aggregator already rejected this version.
+	if quarantined, ok := r.quarantinedNodes[ne.UID]; ok {
+		if ne.Operation != tr.Delete {
+			// The quarantined node has the computed properties, the new version gets them with the edges.
+			tr.CopyComputedProperties(quarantined.Node, ne.Node)
+			if reflect.DeepEqual(ne.Node.Properties, quarantined.Node.Properties) {
+				return
+			}
+		}
+		glog.V(2).Infof("Releasing node %s from quarantine.", ne.UID)
+		delete(r.quarantinedNodes, ne.UID)
+	}
+
 	if ne.Operation == tr.Delete {
 		delete(r.currentNodes, ne.UID) // Get rid of it from our currentState, if it was ever there.
 		delete(r.edgeFuncs, ne.UID)
 		r.purgedNodes.Add(ne.UID, ne) // Add this to the list of node purged resources
+		r.releaseSyntheticNodes(ne.UID, ne.Time)
+		delete(r.eventProperties, ne.UID)
 
 		if inPrevious {
 			r.diffNodes[ne.UID] = ne // Since it was in the previous, we need to have a deletion diff.
@@ -325,8 +437,11 @@ func (r *Reconciler) reconcileNode() {
 		}
 	} else { // This is either an update or create, which look very similar. TODO actually combine the two.
 		ne.Operation = tr.Create
+		// Synthetic nodes have their own diff, so they are synced even if the update to this node is redundant.
+		r.syncSyntheticNodes(ne)
 		if inPrevious { // If this was in the previous, our operation for diffs is update, not create
 			ne.Operation = tr.Update
+			tr.CopyComputedProperties(previousNode, ne.Node)
 
 			// skip updates if new event is redundant to our previous state
 			// (a property that we don't care about triggered an update)
@@ -369,6 +484,49 @@ func (r *Reconciler) reconcileNode() {
 	}
 }
 
+// Removes a node rejected by the aggregator from our state, along with all its edges. The node stays in
+// quarantine until we receive a new version of the resource. Returns the number of edges removed.
+func (r *Reconciler) Quarantine(uid, reason string) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	node, ok := r.currentNodes[uid]
+	if !ok {
+		return 0
+	}
+	r.quarantinedNodes[uid] = QuarantinedNode{Node: node, Reason: reason}
+	delete(r.currentNodes, uid)
+	delete(r.previousNodes, uid)
+	delete(r.diffNodes, uid)
+	delete(r.edgeFuncs, uid)
+	r.releaseSyntheticNodes(uid, time.Now().Unix())
+
+	removedEdges := len(r.previousEdges[uid])
+	delete(r.previousEdges, uid)
+	for _, destMap := range r.previousEdges {
+		for key, edge := range destMap {
+			if edge.DestUID == uid {
+				delete(destMap, key)
+				removedEdges++
+			}
+		}
+	}
+	r.totalEdges -= removedEdges
+	return removedEdges
+}
+
+// Returns the quarantined nodes, keyed by UID.
+func (r *Reconciler) Quarantined() map[string]QuarantinedNode {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	ret := make(map[string]QuarantinedNode, len(r.quarantinedNodes))
+	for uid, q := range r.quarantinedNodes {
+		ret[uid] = q
+	}
+	return ret
+}
+
 // Clears out diffState and copies currentState into previousState.
 // (has to actually make a copy, maps are normally pass by reference)
 // NOT THREADSAFE with anything that edits structures in s, locking left up to the caller.
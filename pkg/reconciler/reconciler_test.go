@@ -21,8 +21,10 @@ import (
 	"github.com/golang/glog"
 	lru "github.com/golang/groupcache/lru"
 	tr "github.com/stolostron/search-collector/pkg/transforms"
+	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/helm/pkg/proto/hapi/release"
 )
 
@@ -31,6 +33,9 @@ type NodeEdge struct {
 	BuildEdges []func(tr.NodeStore) []tr.Edge
 }
 
+// Options used to transform the test data.
+var transformOptions = tr.Options{ClusterName: "local-cluster", DeployedInHub: true}
+
 func initTestReconciler() *Reconciler {
 	return &Reconciler{
 		currentNodes:       make(map[string]tr.Node),
@@ -39,9 +44,16 @@ func initTestReconciler() *Reconciler {
 		k8sEventNodes:      make(map[string]tr.NodeEvent),
 		previousEventEdges: make(map[string]tr.Edge),
 		edgeFuncs:          make(map[string]func(ns tr.NodeStore) []tr.Edge),
+		syntheticRefs:      make(map[string]map[string]struct{}),
+		syntheticBySource:  make(map[string]map[string]struct{}),
+		eventProperties:    make(map[string]map[string]interface{}),
 
 		Input:       make(chan tr.NodeEvent),
 		purgedNodes: lru.New(CACHE_SIZE),
+
+		quarantinedNodes: make(map[string]QuarantinedNode),
+		retries:          make(map[string]RetryStatus),
+		maxSyncRetries:   MAX_SYNC_RETRIES,
 	}
 }
 
@@ -58,8 +70,8 @@ func createNodeEvents() []tr.NodeEvent {
 			},
 		},
 	}
-	unstructuredNode := tr.GenericResourceBuilder(&unstructuredInput)
-	bEdges := tr.GenericResourceBuilder(&unstructuredInput).BuildEdges
+	unstructuredNode := tr.GenericResourceBuilder(&unstructuredInput, transformOptions)
+	bEdges := tr.GenericResourceBuilder(&unstructuredInput, transformOptions).BuildEdges
 	events.BuildNode = append(events.BuildNode, unstructuredNode.BuildNode())
 	events.BuildEdges = append(events.BuildEdges, bEdges)
 
@@ -70,9 +82,9 @@ func createNodeEvents() []tr.NodeEvent {
 	p.Kind = "Pod"
 	p.Namespace = "default"
 	p.UID = "5678"
-	podNode := tr.PodResourceBuilder(&p).BuildNode()
+	podNode := tr.PodResourceBuilder(&p, transformOptions).BuildNode()
 	podNode.Metadata["OwnerUID"] = "local-cluster/1234"
-	podEdges := tr.PodResourceBuilder(&p).BuildEdges
+	podEdges := tr.PodResourceBuilder(&p, transformOptions).BuildEdges
 
 	events.BuildNode = append(events.BuildNode, podNode)
 	events.BuildEdges = append(events.BuildEdges, podEdges)
@@ -235,6 +247,79 @@ func TestReconcilerRedundant(t *testing.T) {
 	}
 }
 
+func TestReconcilerQuarantine(t *testing.T) {
+	s := initTestReconciler()
+	s.previousEdges = make(map[string]map[string]tr.Edge)
+	node := tr.Node{UID: "test-event", Properties: map[string]interface{}{"very": "important"}}
+	s.currentNodes["test-event"] = node
+	s.previousNodes["test-event"] = node
+	s.previousEdges["test-event"] = map[string]tr.Edge{"other": {SourceUID: "test-event", DestUID: "other"}}
+	s.previousEdges["other"] = map[string]tr.Edge{"test-event": {SourceUID: "other", DestUID: "test-event"}}
+	s.totalEdges = 2
+
+	if removed := s.Quarantine("test-event", "rejected"); removed != 2 {
+		t.Fatalf("expected 2 edges removed, got %d", removed)
+	}
+	if _, ok := s.currentNodes["test-event"]; ok {
+		t.Fatal("failed to remove quarantined node from current state")
+	}
+	if s.Quarantined()["test-event"].Reason != "rejected" {
+		t.Fatal("failed to record quarantine reason")
+	}
+
+	// The same version of the resource is ignored.
+	go func() {
+		s.Input <- tr.NodeEvent{Time: time.Now().Unix(), Operation: tr.Update, Node: node}
+	}()
+	s.reconcileNode()
+	if _, ok := s.diffNodes["test-event"]; ok {
+		t.Fatal("failed to ignore quarantined node")
+	}
+
+	// A new version of the resource lifts the quarantine.
+	go func() {
+		s.Input <- tr.NodeEvent{
+			Time:      time.Now().Unix(),
+			Operation: tr.Update,
+			Node:      tr.Node{UID: "test-event", Properties: map[string]interface{}{"very": "fixed"}},
+		}
+	}()
+	s.reconcileNode()
+	if _, ok := s.diffNodes["test-event"]; !ok {
+		t.Fatal("failed to release node from quarantine")
+	}
+	if len(s.Quarantined()) != 0 {
+		t.Fatal("quarantine not lifted")
+	}
+}
+
+// Verify a quarantined node with properties from its Warning events and computed with the edges stays in
+// quarantine when the same version of the resource is received again.
+func TestReconcilerQuarantineEventAndComputedProperties(t *testing.T) {
+	testReconciler := initTestReconciler()
+	k8sNode := v1.Node{}
+	k8sNode.Kind = "Node"
+	k8sNode.Name = "worker-1"
+	k8sNode.UID = "node-1"
+	send := func() {
+		go func() {
+			testReconciler.Input <- tr.NewNodeEvent(&tr.Event{Time: time.Now().Unix(), Operation: tr.Update},
+				tr.NodeResourceBuilder(&k8sNode, transformOptions), "nodes")
+		}()
+		testReconciler.reconcileNode()
+	}
+
+	send()
+	testReconciler.SetEventProperties("local-cluster/node-1", map[string]interface{}{"lastWarningReason": "NotReady"})
+	testReconciler.Diff() // Computes the podCount.
+	testReconciler.Quarantine("local-cluster/node-1", "rejected")
+
+	send()
+	_, inDiff := testReconciler.diffNodes["local-cluster/node-1"]
+	assert.False(t, inDiff, "failed to ignore quarantined node")
+	assert.Len(t, testReconciler.Quarantined(), 1)
+}
+
 func TestReconcilerAddEdges(t *testing.T) {
 	testReconciler := initTestReconciler()
 	//Add events
@@ -257,7 +342,7 @@ func TestReconcilerAddEdges(t *testing.T) {
 	edgeMap2 := make(map[string]map[string]tr.Edge, 1)
 	edge := tr.Edge{EdgeType: "ownedBy", SourceUID: "local-cluster/5678", DestUID: "local-cluster/1234", SourceKind: "Pod", DestKind: "testowner"}
 	edgeMap2["local-cluster/5678"] = map[string]tr.Edge{}
-	edgeMap2["local-cluster/5678"]["local-cluster/1234"] = edge
+	edgeMap2["local-cluster/5678"]["local-cluster/1234/ownedBy"] = edge
 
 	//Check if the actual and expected edges are the same
 	if !reflect.DeepEqual(edgeMap1, edgeMap2) {
@@ -302,6 +387,149 @@ func TestReconcilerDiff(t *testing.T) {
 	}
 }
 
+// Verify a Node is updated when the pods running on it change, and a Node update keeps the computed properties.
+func TestReconcilerDiffComputedProperties(t *testing.T) {
+	testReconciler := initTestReconciler()
+
+	k8sNode := v1.Node{}
+	k8sNode.Kind = "Node"
+	k8sNode.Name = "worker-1"
+	k8sNode.UID = "node-1"
+	nodeResource := tr.NodeResourceBuilder(&k8sNode, transformOptions)
+
+	pod := v1.Pod{}
+	pod.Kind = "Pod"
+	pod.Name = "testpod"
+	pod.Namespace = "default"
+	pod.UID = "pod-1"
+	pod.Spec.NodeName = "worker-1"
+	podResource := tr.PodResourceBuilder(&pod, transformOptions)
+
+	go func() {
+		testReconciler.Input <- tr.NewNodeEvent(&tr.Event{Time: time.Now().Unix(), Operation: tr.Create},
+			nodeResource, "nodes")
+	}()
+	testReconciler.reconcileNode()
+	first := testReconciler.Diff()
+	assert.Equal(t, int64(0), first.AddNodes[0].Properties["podCount"])
+
+	go func() {
+		testReconciler.Input <- tr.NewNodeEvent(&tr.Event{Time: time.Now().Unix(), Operation: tr.Create},
+			podResource, "pods")
+	}()
+	testReconciler.reconcileNode()
+	diff := testReconciler.Diff()
+
+	assert.Len(t, diff.AddNodes, 1)
+	assert.Len(t, diff.UpdateNodes, 1)
+	assert.Equal(t, int64(1), diff.UpdateNodes[0].Properties["podCount"])
+	// The node sent before isn't modified when the properties are computed again.
+	assert.Equal(t, int64(0), first.AddNodes[0].Properties["podCount"])
+
+	// A Node update without changes is redundant, the computed properties are carried over.
+	go func() {
+		testReconciler.Input <- tr.NewNodeEvent(&tr.Event{Time: time.Now().Unix(), Operation: tr.Update},
+			tr.NodeResourceBuilder(&k8sNode, transformOptions), "nodes")
+	}()
+	testReconciler.reconcileNode()
+	diff = testReconciler.Diff()
+
+	assert.Len(t, diff.UpdateNodes, 0)
+}
+
+func TestReconcilerSyntheticNodes(t *testing.T) {
+	testReconciler := initTestReconciler()
+	send := func(pod v1.Pod, operation tr.Operation) {
+		go func() {
+			testReconciler.Input <- tr.NewNodeEvent(&tr.Event{Time: time.Now().Unix(), Operation: operation},
+				tr.PodResourceBuilder(&pod, transformOptions), "pods")
+		}()
+		testReconciler.reconcileNode()
+	}
+	newPod := func(uid, image string) v1.Pod {
+		pod := v1.Pod{}
+		pod.Kind = "Pod"
+		pod.Name = "pod-" + uid
+		pod.Namespace = "default"
+		pod.UID = types.UID(uid)
+		pod.Spec.Containers = []v1.Container{{Name: "app", Image: image}}
+		return pod
+	}
+
+	// Two pods using the same image add a single ContainerImage.
+	send(newPod("pod-1", "nginx:1.25"), tr.Create)
+	send(newPod("pod-2", "nginx:1.25"), tr.Create)
+	diff := testReconciler.Diff()
+
+	assert.Len(t, diff.AddNodes, 3)
+	assert.Len(t, diff.AddEdges, 2)
+	imageUID := ""
+	for _, n := range diff.AddNodes {
+		if n.Properties["kind"] == "ContainerImage" {
+			imageUID = n.UID
+		}
+	}
+	assert.NotEmpty(t, imageUID)
+
+	// The image is kept while a pod uses it.
+	go func() {
+		testReconciler.Input <- tr.NodeEvent{Time: time.Now().Unix(), Operation: tr.Delete,
+			Node: tr.Node{UID: "local-cluster/pod-1"}}
+	}()
+	testReconciler.reconcileNode()
+	diff = testReconciler.Diff()
+
+	assert.Equal(t, []tr.Deletion{{UID: "local-cluster/pod-1"}}, diff.DeleteNodes)
+
+	// Updating the last pod to another image replaces the ContainerImage.
+	send(newPod("pod-2", "nginx:1.26"), tr.Update)
+	diff = testReconciler.Diff()
+
+	assert.Len(t, diff.AddNodes, 1)
+	assert.Equal(t, "docker.io/library/nginx:1.26", diff.AddNodes[0].Properties["name"])
+	assert.Len(t, diff.UpdateNodes, 1)
+	assert.Equal(t, []tr.Deletion{{UID: imageUID}}, diff.DeleteNodes)
+	assert.Len(t, diff.AddEdges, 1)
+}
+
+func TestReconcilerEventProperties(t *testing.T) {
+	testReconciler := initTestReconciler()
+	pod := v1.Pod{}
+	pod.Kind = "Pod"
+	pod.Name = "testpod"
+	pod.Namespace = "default"
+	pod.UID = "pod-1"
+	send := func(operation tr.Operation) {
+		go func() {
+			testReconciler.Input <- tr.NewNodeEvent(&tr.Event{Time: time.Now().Unix(), Operation: operation},
+				tr.PodResourceBuilder(&pod, transformOptions), "pods")
+		}()
+		testReconciler.reconcileNode()
+	}
+	send(tr.Create)
+	testReconciler.Diff()
+
+	// The properties are sent as an update of the node.
+	testReconciler.SetEventProperties("local-cluster/pod-1", map[string]interface{}{"lastWarningReason": "BackOff"})
+	diff := testReconciler.Diff()
+
+	assert.Len(t, diff.UpdateNodes, 1)
+	assert.Equal(t, "BackOff", diff.UpdateNodes[0].Properties["lastWarningReason"])
+
+	// New versions of the node keep the properties, so an update without changes is redundant.
+	send(tr.Update)
+	diff = testReconciler.Diff()
+
+	assert.Len(t, diff.UpdateNodes, 0)
+
+	// Removing the properties updates the node again.
+	testReconciler.SetEventProperties("local-cluster/pod-1", nil)
+	diff = testReconciler.Diff()
+
+	assert.Len(t, diff.UpdateNodes, 1)
+	assert.NotContains(t, diff.UpdateNodes[0].Properties, "lastWarningReason")
+}
+
 func TestReconcilerComplete(t *testing.T) {
 	input := make(chan *tr.Event)
 	output := make(chan tr.NodeEvent)
@@ -348,7 +576,7 @@ func TestReconcilerComplete(t *testing.T) {
 		}
 	}
 	testReconciler := initTestReconciler()
-	go tr.TransformRoutine(input, output)
+	go tr.TransformRoutine(input, output, transformOptions)
 
 	//Convert events to Node events
 	go func() {
@@ -365,7 +593,7 @@ func TestReconcilerComplete(t *testing.T) {
 	}
 	// The rlsFileCount will ensure that both the release configmap and the helm release files are read - so that the release event can be added to reconciler
 	if rlsFileCount == 2 {
-		releaseTrans := tr.HelmReleaseResource{ConfigMap: &c, Release: &rls}
+		releaseTrans := tr.HelmReleaseResource{ConfigMap: &c, Release: &rls, Options: transformOptions}
 		go func() {
 			testReconciler.Input <- tr.NewNodeEvent(rlsEvnt, releaseTrans, "releases")
 		}()
@@ -378,8 +606,8 @@ func TestReconcilerComplete(t *testing.T) {
 	// Checks the count of nodes and edges based on the JSON files in pkg/test-data
 	// Update counts when the test data is changed
 	// We don't create Nodes for kind = Event
-	const Nodes = 36
-	const Edges = 51
+	const Nodes = 55
+	const Edges = 70
 	if len(com.Edges) != Edges || com.TotalEdges != Edges || len(com.Nodes) != Nodes || com.TotalNodes != Nodes {
 		ns := tr.NodeStore{
 			ByUID:               testReconciler.currentNodes,
@@ -0,0 +1,152 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package reconciler
+
+import (
+	"time"
+
+	"github.com/golang/glog"
+	tr "github.com/stolostron/search-collector/pkg/transforms"
+)
+
+// Default number of times we resend a node or edge the aggregator failed to apply before giving up.
+const MAX_SYNC_RETRIES = 3
+
+// A node the aggregator failed to add, update or delete.
+type NodeFailure struct {
+	UID     string
+	Message string // Message from the aggregator.
+}
+
+// An edge the aggregator failed to add or delete.
+type EdgeFailure struct {
+	Edge    tr.Edge
+	Message string // Message from the aggregator.
+}
+
+// Operations the aggregator reported as failed in a sync response.
+type SyncFailures struct {
+	AddNodes, UpdateNodes, DeleteNodes []NodeFailure
+	AddEdges, DeleteEdges              []EdgeFailure
+}
+
+// Tracks the retries for a node or edge the aggregator failed to apply.
+type RetryStatus struct {
+	Attempts int    // Number of consecutive syncs where the aggregator failed to apply it.
+	Message  string // Last message from the aggregator.
+}
+
+func (f SyncFailures) empty() bool {
+	return len(f.AddNodes) == 0 && len(f.UpdateNodes) == 0 && len(f.DeleteNodes) == 0 &&
+		len(f.AddEdges) == 0 && len(f.DeleteEdges) == 0
+}
+
+// Key used to track retries for an edge.
+func edgeRetryKey(e tr.Edge) string {
+	return e.SourceUID + "-" + string(e.EdgeType) + "->" + e.DestUID
+}
+
+// Marks the failed nodes and edges so they are included in the next diff.
+// Each node or edge is retried up to Options.MaxSyncRetries consecutive times. After that, nodes that the
+// aggregator can't add or update are quarantined, and other failures are dropped. Anything that isn't in the
+// failures is considered successful, so it gets a fresh retry budget if it fails again later.
+func (r *Reconciler) RetryFailures(failures SyncFailures) {
+	r.mutex.Lock()
+	if failures.empty() && len(r.retries) == 0 {
+		r.mutex.Unlock()
+		return
+	}
+	quarantine := []NodeFailure{}
+
+	previous := r.retries
+	r.retries = make(map[string]RetryStatus)
+
+	// Returns true if the item is within its retry budget.
+	track := func(key, message string) bool {
+		status := RetryStatus{Attempts: previous[key].Attempts + 1, Message: message}
+		if status.Attempts > r.maxSyncRetries {
+			return false
+		}
+		r.retries[key] = status
+		return true
+	}
+	now := time.Now().Unix()
+
+	nodeFailures := make([]NodeFailure, 0, len(failures.AddNodes)+len(failures.UpdateNodes))
+	nodeFailures = append(nodeFailures, failures.AddNodes...)
+	for _, f := range append(nodeFailures, failures.UpdateNodes...) {
+		node, ok := r.currentNodes[f.UID]
+		if !ok {
+			continue // The resource was deleted since we sent it, nothing to retry.
+		}
+		if !track(f.UID, f.Message) {
+			quarantine = append(quarantine, f)
+			continue
+		}
+		glog.Warningf("Aggregator failed to apply node %s, retrying on next sync. Message: %s", f.UID, f.Message)
+		// We don't know whether the aggregator has an older version of the node, so we resend it as an add.
+		delete(r.previousNodes, f.UID)
+		if ne, inDiff := r.diffNodes[f.UID]; inDiff {
+			ne.Operation = tr.Create
+			r.diffNodes[f.UID] = ne
+		} else {
+			r.diffNodes[f.UID] = tr.NodeEvent{
+				Time:         now,
+				Operation:    tr.Create,
+				Node:         node,
+				ComputeEdges: r.edgeFuncs[f.UID],
+			}
+		}
+	}
+
+	for _, f := range failures.DeleteNodes {
+		if _, ok := r.currentNodes[f.UID]; ok {
+			continue // The resource was created again, the aggregator will get the new version.
+		}
+		if !track(f.UID, f.Message) {
+			glog.Errorf("Aggregator failed to delete node %s after %d attempts. Message: %s", f.UID,
+				r.maxSyncRetries, f.Message)
+			continue
+		}
+		glog.Warningf("Aggregator failed to delete node %s, retrying on next sync. Message: %s", f.UID, f.Message)
+		if _, inDiff := r.diffNodes[f.UID]; !inDiff {
+			r.diffNodes[f.UID] = tr.NodeEvent{Time: now, Operation: tr.Delete, Node: tr.Node{UID: f.UID}}
+		}
+	}
+
+	// Edges are computed from previousEdges on the next diff. Removing a failed add makes it get added again, and
+	// putting back a failed delete makes it get deleted again.
+	for _, f := range failures.AddEdges {
+		if !track(edgeRetryKey(f.Edge), f.Message) {
+			glog.Errorf("Aggregator failed to add edge %s after %d attempts. Message: %s", edgeRetryKey(f.Edge),
+				r.maxSyncRetries, f.Message)
+			continue
+		}
+		glog.Warningf("Aggregator failed to add edge %s, retrying on next sync. Message: %s", edgeRetryKey(f.Edge),
+			f.Message)
+		delete(r.previousEdges[f.Edge.SourceUID], destKey(f.Edge))
+	}
+	if r.previousEdges == nil {
+		r.previousEdges = make(map[string]map[string]tr.Edge)
+	}
+	for _, f := range failures.DeleteEdges {
+		if !track(edgeRetryKey(f.Edge), f.Message) {
+			glog.Errorf("Aggregator failed to delete edge %s after %d attempts. Message: %s",
+				edgeRetryKey(f.Edge), r.maxSyncRetries, f.Message)
+			continue
+		}
+		glog.Warningf("Aggregator failed to delete edge %s, retrying on next sync. Message: %s",
+			edgeRetryKey(f.Edge), f.Message)
+		if _, ok := r.previousEdges[f.Edge.SourceUID]; !ok {
+			r.previousEdges[f.Edge.SourceUID] = make(map[string]tr.Edge)
+		}
+		r.previousEdges[f.Edge.SourceUID][destKey(f.Edge)] = f.Edge
+	}
+	r.mutex.Unlock()
+
+	for _, f := range quarantine {
+		glog.Errorf("Aggregator failed to apply node %s after %d attempts. Message: %s", f.UID, r.maxSyncRetries,
+			f.Message)
+		r.Quarantine(f.UID, f.Message)
+	}
+}
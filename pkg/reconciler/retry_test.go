@@ -0,0 +1,61 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package reconciler
+
+import (
+	"testing"
+
+	tr "github.com/stolostron/search-collector/pkg/transforms"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryFailures(t *testing.T) {
+	r := initTestReconciler()
+	r.currentNodes["added"] = tr.Node{UID: "added", Properties: map[string]interface{}{"kind": "Pod"}}
+	r.previousNodes["added"] = r.currentNodes["added"]
+	edge := tr.Edge{SourceUID: "added", DestUID: "gone", EdgeType: "runsOn"}
+	r.previousEdges = map[string]map[string]tr.Edge{"added": {"other/": {SourceUID: "added", DestUID: "other"}}}
+
+	r.RetryFailures(SyncFailures{
+		AddNodes:    []NodeFailure{{UID: "added", Message: "bad node"}},
+		DeleteNodes: []NodeFailure{{UID: "gone", Message: "delete failed"}},
+		AddEdges:    []EdgeFailure{{Edge: tr.Edge{SourceUID: "added", DestUID: "other"}, Message: "bad edge"}},
+		DeleteEdges: []EdgeFailure{{Edge: edge, Message: "delete failed"}},
+	})
+
+	assert.Equal(t, tr.Create, r.diffNodes["added"].Operation)
+	assert.Equal(t, tr.Delete, r.diffNodes["gone"].Operation)
+	_, inPrevious := r.previousEdges["added"]["other/"]
+	assert.False(t, inPrevious, "failed edge add should be removed from previous edges")
+	assert.Equal(t, edge, r.previousEdges["added"]["gone/runsOn"])
+	assert.Equal(t, RetryStatus{Attempts: 1, Message: "bad node"}, r.retries["added"])
+	assert.Equal(t, 4, len(r.retries))
+}
+
+func TestRetryFailuresBudget(t *testing.T) {
+	r := initTestReconciler()
+	r.currentNodes["bad"] = tr.Node{UID: "bad", Properties: map[string]interface{}{"kind": "Pod"}}
+	failures := SyncFailures{AddNodes: []NodeFailure{{UID: "bad", Message: "invalid"}}}
+
+	for i := 1; i <= MAX_SYNC_RETRIES; i++ {
+		r.RetryFailures(failures)
+		assert.Equal(t, i, r.retries["bad"].Attempts)
+	}
+	r.RetryFailures(failures)
+
+	assert.Empty(t, r.retries)
+	assert.Equal(t, "invalid", r.Quarantined()["bad"].Reason)
+	_, inCurrent := r.currentNodes["bad"]
+	assert.False(t, inCurrent)
+}
+
+func TestRetryFailuresResetOnSuccess(t *testing.T) {
+	r := initTestReconciler()
+	r.currentNodes["flaky"] = tr.Node{UID: "flaky", Properties: map[string]interface{}{"kind": "Pod"}}
+
+	r.RetryFailures(SyncFailures{UpdateNodes: []NodeFailure{{UID: "flaky", Message: "timeout"}}})
+	assert.Equal(t, 1, len(r.retries))
+
+	r.RetryFailures(SyncFailures{})
+	assert.Empty(t, r.retries)
+}
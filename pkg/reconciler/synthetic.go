@@ -0,0 +1,86 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package reconciler
+
+import (
+	"reflect"
+
+	"github.com/golang/glog"
+	tr "github.com/stolostron/search-collector/pkg/transforms"
+)
+
+// Synthetic nodes, like the ContainerImages used by the pods, don't have a k8s resource of their own. They are
+// added with the first node that references them, and deleted with the last one.
+
+// Updates the synthetic nodes referenced by the node in the event. Adds the new ones and releases the ones
+// the node doesn't reference anymore.
+// NOT THREADSAFE, locking left up to the caller.
+func (r *Reconciler) syncSyntheticNodes(ne tr.NodeEvent) {
+	referenced := make(map[string]struct{}, len(ne.SyntheticNodes))
+	for _, node := range ne.SyntheticNodes {
+		referenced[node.UID] = struct{}{}
+		r.addSyntheticNode(ne.UID, node, ne.Time)
+	}
+	for uid := range r.syntheticBySource[ne.UID] {
+		if _, ok := referenced[uid]; !ok {
+			r.releaseSyntheticNode(ne.UID, uid, ne.Time)
+		}
+	}
+}
+
+// Releases all the synthetic nodes referenced by a node that was deleted.
+// NOT THREADSAFE, locking left up to the caller.
+func (r *Reconciler) releaseSyntheticNodes(sourceUID string, time int64) {
+	for uid := range r.syntheticBySource[sourceUID] {
+		r.releaseSyntheticNode(sourceUID, uid, time)
+	}
+}
+
+// Adds the reference from the source node, and the synthetic node if it's new.
+func (r *Reconciler) addSyntheticNode(sourceUID string, node tr.Node, time int64) {
+	if r.syntheticRefs[node.UID] == nil {
+		r.syntheticRefs[node.UID] = make(map[string]struct{})
+	}
+	r.syntheticRefs[node.UID][sourceUID] = struct{}{}
+	if r.syntheticBySource[sourceUID] == nil {
+		r.syntheticBySource[sourceUID] = make(map[string]struct{})
+	}
+	r.syntheticBySource[sourceUID][node.UID] = struct{}{}
+
+	if current, ok := r.currentNodes[node.UID]; ok && reflect.DeepEqual(current.Properties, node.Properties) {
+		return
+	}
+	if quarantined, ok := r.quarantinedNodes[node.UID]; ok &&
+		reflect.DeepEqual(node.Properties, quarantined.Node.Properties) {
+		return
+	}
+	delete(r.quarantinedNodes, node.UID)
+
+	operation := tr.Create
+	if _, inPrevious := r.previousNodes[node.UID]; inPrevious {
+		operation = tr.Update
+	}
+	r.currentNodes[node.UID] = node
+	r.diffNodes[node.UID] = tr.NodeEvent{Node: node, Time: time, Operation: operation}
+}
+
+// Removes the reference from the source node, and deletes the synthetic node if it was the last one.
+func (r *Reconciler) releaseSyntheticNode(sourceUID, uid string, time int64) {
+	delete(r.syntheticBySource[sourceUID], uid)
+	if len(r.syntheticBySource[sourceUID]) == 0 {
+		delete(r.syntheticBySource, sourceUID)
+	}
+	delete(r.syntheticRefs[uid], sourceUID)
+	if len(r.syntheticRefs[uid]) > 0 {
+		return
+	}
+	delete(r.syntheticRefs, uid)
+
+	glog.V(4).Infof("Deleting synthetic node %s, it isn't referenced anymore.", uid)
+	delete(r.currentNodes, uid)
+	if _, inPrevious := r.previousNodes[uid]; inPrevious {
+		r.diffNodes[uid] = tr.NodeEvent{Node: tr.Node{UID: uid}, Time: time, Operation: tr.Delete}
+	} else {
+		delete(r.diffNodes, uid)
+	}
+}
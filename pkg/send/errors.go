@@ -0,0 +1,118 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package send
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Maximum size of an error response body that we attempt to decode.
+const MAX_ERROR_BODY_BYTES = 1 << 20 // 1 MB
+
+// AggregatorError is returned by send() when the aggregator responds with a status other than 200 OK.
+type AggregatorError struct {
+	URL        string
+	StatusCode int
+	Status     string
+	RetryAfter time.Duration // Wait requested by the aggregator with the Retry-After header. Zero if not set.
+	Response   *SyncResponse // Resources rejected by the aggregator. Only set for 4xx responses that include them.
+}
+
+func (e *AggregatorError) Error() string {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests:
+		return "Aggregator busy"
+	case http.StatusUnauthorized:
+		return "401 Unauthorized"
+	}
+	return fmt.Sprintf("POST to: %s responded with error. StatusCode: %d  Message: %s", e.URL, e.StatusCode, e.Status)
+}
+
+// Busy returns true if the aggregator asked us to come back later. The same payload can be resent.
+func (e *AggregatorError) Busy() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode == http.StatusServiceUnavailable
+}
+
+// ServerError returns true for 5xx responses. The same payload can be resent after waiting.
+func (e *AggregatorError) ServerError() bool {
+	return e.StatusCode >= 500
+}
+
+// Rejected returns the resources the aggregator refused to process.
+// Empty unless the aggregator responded with a 4xx error listing the offending resources.
+func (e *AggregatorError) Rejected() []SyncError {
+	if e.StatusCode < 400 || e.StatusCode >= 500 || e.Response == nil {
+		return nil
+	}
+	rejected := make([]SyncError, 0, len(e.Response.AddErrors)+len(e.Response.UpdateErrors))
+	rejected = append(rejected, e.Response.AddErrors...)
+	return append(rejected, e.Response.UpdateErrors...)
+}
+
+// CountMismatchError is returned when the totals reported by the aggregator don't match our state.
+// The aggregator state can't be trusted after this, so we need to resend the complete state.
+type CountMismatchError struct {
+	Kind     string // resources or intra edges
+	Expected int
+	Actual   int
+}
+
+func (e *CountMismatchError) Error() string {
+	return fmt.Sprintf("Aggregator reported wrong number of total %s. Expected %d, got %d", e.Kind, e.Expected,
+		e.Actual)
+}
+
+// UnavailableError is returned by sendWithRetry when the aggregator is still busy or failing after
+// MAX_BUSY_RETRIES. The aggregator state is still valid, so the same payload is resent on the next send cycle.
+type UnavailableError struct {
+	Err *AggregatorError
+}
+
+func (e *UnavailableError) Error() string {
+	return fmt.Sprintf("Aggregator unavailable after %d retries. %s", MAX_BUSY_RETRIES, e.Err.Error())
+}
+
+func (e *UnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// Builds an AggregatorError from an unsuccessful response.
+func newAggregatorError(url string, resp *http.Response) *AggregatorError {
+	aggErr := &AggregatorError{
+		URL:        url,
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+
+	// On validation errors the aggregator lists the resources that it couldn't process.
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.Body != nil {
+		r := SyncResponse{}
+		if err := json.NewDecoder(io.LimitReader(resp.Body, MAX_ERROR_BODY_BYTES)).Decode(&r); err == nil {
+			aggErr.Response = &r
+		}
+	}
+	return aggErr
+}
+
+// Parses the Retry-After header, which is either a number of seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
@@ -13,8 +13,11 @@ package send
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
+	"fmt"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/stolostron/search-collector/pkg/config"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured/unstructuredscheme"
@@ -23,49 +26,124 @@ import (
 	"github.com/golang/glog"
 )
 
-func getHTTPSClient() (client http.Client) {
+// Cipher suites allowed by each TLS_CIPHER_PROFILE. Only applies to TLS 1.2, TLS 1.3 suites aren't configurable.
+var cipherProfiles = map[string][]uint16{
+	"intermediate": {
+		tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+		tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+		tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+		tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+	},
+	"modern": {
+		tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+		tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	},
+}
+
+// Maps TLS_MIN_VERSION to the tls package constants.
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Returns the client used to send data to the aggregator.
+// Returns an error if TLS_STRICT is set and we aren't able to verify the aggregator.
+func getHTTPSClient(cfg *config.Config) (client http.Client, err error) {
 
 	// Klusterlet deployment: Get httpClient using the mounted kubeconfig.
-	if !config.Cfg.DeployedInHub {
-		config.Cfg.AggregatorConfig.NegotiatedSerializer = unstructuredscheme.NewUnstructuredNegotiatedSerializer()
-		aggregatorRESTClient, err := rest.UnversionedRESTClientFor(config.Cfg.AggregatorConfig)
+	if !cfg.DeployedInHub {
+		if cfg.TLSStrict && cfg.AggregatorConfig.Insecure {
+			return client, errors.New("TLS_STRICT is set, but the hub kubeconfig skips TLS verification")
+		}
+		// Copy the hub config, it's shared with the other goroutines.
+		restConfig := rest.CopyConfig(cfg.AggregatorConfig)
+		restConfig.NegotiatedSerializer = unstructuredscheme.NewUnstructuredNegotiatedSerializer()
+		aggregatorRESTClient, err := rest.UnversionedRESTClientFor(restConfig)
 		if err != nil {
-			// Exit because this is an unrecoverable configuration problem.
-			glog.Fatal("Error getting httpClient from kubeconfig. Original error: ", err)
+			return client, fmt.Errorf("error getting httpClient from kubeconfig: %w", err)
 		}
 		client = *(aggregatorRESTClient.Client)
-		return client
+		return client, nil
 	} else {
 		// Hub deployment:
-		// Generate TLS config using the mounted certificates. If certificates aren't found wee use
-		// insecure TLS connection (InsecureSkipVerify). This should only happen during development.
-		tlsCfg := &tls.Config{
-			MinVersion:               tls.VersionTLS12,
-			CurvePreferences:         []tls.CurveID{tls.CurveP521, tls.CurveP384, tls.CurveP256},
-			PreferServerCipherSuites: true,
-			CipherSuites: []uint16{
-				tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
-			},
-			// RootCAs:      caCertPool,
-			// Certificates: []tls.Certificate{cert},
-		}
-		caCert, err := os.ReadFile("./sslcert/tls.crt")
-		cert, err2 := tls.LoadX509KeyPair("./sslcert/tls.crt", "./sslcert/tls.key")
-		if err != nil || err2 != nil {
-			glog.Error("WARNING: Using insecure TLS connection. Couldn't load certs ", err, err2)
-			tlsCfg.InsecureSkipVerify = true
-		} else {
-			caCertPool := x509.NewCertPool()
-			caCertPool.AppendCertsFromPEM(caCert)
-			tlsCfg.RootCAs = caCertPool
-
-			tlsCfg.Certificates = []tls.Certificate{cert}
+		// Generate TLS config using the mounted certificates. If certificates aren't found we use
+		// insecure TLS connection (InsecureSkipVerify), unless TLS_STRICT is set. This should only happen
+		// during development.
+		tlsCfg, err := hubTLSConfig(cfg)
+		if err != nil {
+			return client, err
 		}
-
 		tr := &http.Transport{
 			TLSClientConfig: tlsCfg,
 		}
 
-		return http.Client{Transport: tr}
+		return http.Client{Transport: tr}, nil
+	}
+}
+
+// Builds the TLS config for hub deployments from the configured files, version and cipher profile.
+func hubTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	minVersion, ok := tlsVersions[cfg.TLSMinVersion]
+	if !ok {
+		return nil, fmt.Errorf("invalid TLS_MIN_VERSION %q, expected 1.2 or 1.3", cfg.TLSMinVersion)
+	}
+	cipherSuites, ok := cipherProfiles[cfg.TLSCipherProfile]
+	if !ok {
+		return nil, fmt.Errorf("invalid TLS_CIPHER_PROFILE %q, expected intermediate or modern",
+			cfg.TLSCipherProfile)
+	}
+	tlsCfg := &tls.Config{
+		MinVersion:       minVersion,
+		CurvePreferences: []tls.CurveID{tls.CurveP521, tls.CurveP384, tls.CurveP256},
+		CipherSuites:     cipherSuites,
+	}
+
+	caCert, err := os.ReadFile(cfg.TLSCAFile)
+	cert, err2 := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+	if err != nil || err2 != nil {
+		if cfg.TLSStrict {
+			return nil, fmt.Errorf("TLS_STRICT is set, but couldn't load certs: %v %v", err, err2)
+		}
+		glog.Error("WARNING: Using insecure TLS connection. Couldn't load certs ", err, err2)
+		tlsCfg.InsecureSkipVerify = true
+		return tlsCfg, nil
+	}
+
+	caCertPool := x509.NewCertPool()
+	if !caCertPool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("no certificates found in TLS_CA_FILE %s", cfg.TLSCAFile)
+	}
+	tlsCfg.RootCAs = caCertPool
+	tlsCfg.Certificates = []tls.Certificate{cert}
+	return tlsCfg, nil
+}
+
+// Tracks the modification time of the TLS files, so we can reload the client when they change on disk.
+type certWatcher struct {
+	files    []string
+	modTimes map[string]time.Time
+}
+
+func newCertWatcher(files ...string) *certWatcher {
+	w := &certWatcher{files: files, modTimes: make(map[string]time.Time, len(files))}
+	w.changed() // Record the initial modification times.
+	return w
+}
+
+// Returns true if any of the files was created, modified or removed since the last call.
+func (w *certWatcher) changed() bool {
+	changed := false
+	for _, file := range w.files {
+		var modTime time.Time
+		if info, err := os.Stat(file); err == nil {
+			modTime = info.ModTime()
+		}
+		if !modTime.Equal(w.modTimes[file]) {
+			changed = true
+			w.modTimes[file] = modTime
+		}
 	}
+	return changed
 }
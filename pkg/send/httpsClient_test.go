@@ -4,13 +4,134 @@
 package send
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/stolostron/search-collector/pkg/config"
 	assert "github.com/stretchr/testify/assert"
+	"k8s.io/client-go/rest"
 )
 
+// Returns a config for a collector deployed in the hub with the given TLS files.
+func tlsTestConfig(caFile, certFile, keyFile string, strict bool) *config.Config {
+	cfg := testConfig()
+	cfg.TLSCAFile = caFile
+	cfg.TLSCertFile = certFile
+	cfg.TLSKeyFile = keyFile
+	cfg.TLSStrict = strict
+	return cfg
+}
+
+// Writes the certificate and key used by the test server, so the client trusts the server.
+func writeServerCerts(t *testing.T, ts *httptest.Server) (string, string) {
+	dir := t.TempDir()
+	cert := ts.TLS.Certificates[0]
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key})
+	if err := os.WriteFile(certFile, certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
 func Test_getHttpsClient(t *testing.T) {
-	client := getHTTPSClient()
+	client, err := getHTTPSClient(testConfig())
 
+	assert.Nil(t, err)
 	assert.NotNil(t, client, "Should get a valid https client")
 }
+
+func Test_getHttpsClient_hubConfigNotModified(t *testing.T) {
+	cfg := testConfig()
+	cfg.DeployedInHub = false
+	cfg.AggregatorConfig = &rest.Config{Host: "https://hub.example.com:6443"}
+
+	_, err := getHTTPSClient(cfg)
+
+	assert.Nil(t, err)
+	assert.Nil(t, cfg.AggregatorConfig.NegotiatedSerializer, "Should not modify the shared hub config")
+}
+
+func Test_getHttpsClient_insecureFallback(t *testing.T) {
+	cfg := tlsTestConfig("./missing/ca.crt", "./missing/tls.crt", "./missing/tls.key", false)
+
+	tlsCfg, err := hubTLSConfig(cfg)
+
+	assert.Nil(t, err)
+	assert.True(t, tlsCfg.InsecureSkipVerify)
+}
+
+func Test_getHttpsClient_strict(t *testing.T) {
+	cfg := tlsTestConfig("./missing/ca.crt", "./missing/tls.crt", "./missing/tls.key", true)
+
+	_, err := getHTTPSClient(cfg)
+
+	assert.NotNil(t, err, "Should refuse to create an insecure client in strict mode")
+}
+
+func Test_getHttpsClient_invalidVersion(t *testing.T) {
+	cfg := tlsTestConfig("./missing/ca.crt", "./missing/tls.crt", "./missing/tls.key", false)
+	cfg.TLSMinVersion = "1.0"
+
+	_, err := hubTLSConfig(cfg)
+
+	assert.NotNil(t, err)
+}
+
+func Test_getHttpsClient_verifiesServer(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	}))
+	defer ts.Close()
+	certFile, keyFile := writeServerCerts(t, ts)
+	cfg := tlsTestConfig(certFile, certFile, keyFile, true)
+
+	client, err := getHTTPSClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tlsCfg := client.Transport.(*http.Transport).TLSClientConfig
+	assert.False(t, tlsCfg.InsecureSkipVerify)
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsCfg.MinVersion)
+
+	resp, err := client.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
+func Test_certWatcher(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "tls.crt")
+	w := newCertWatcher(file)
+	assert.False(t, w.changed())
+
+	if err := os.WriteFile(file, []byte("cert"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, w.changed(), "Should detect a new file")
+	assert.False(t, w.changed())
+
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(file, later, later); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, w.changed(), "Should detect a modified file")
+}
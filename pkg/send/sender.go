@@ -13,18 +13,23 @@ package send
 import (
 	"bytes"
 	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
 	"math/big"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang/glog"
 	"github.com/stolostron/search-collector/pkg/config"
 	"github.com/stolostron/search-collector/pkg/reconciler"
+	"github.com/stolostron/search-collector/pkg/stream"
 	tr "github.com/stolostron/search-collector/pkg/transforms"
 )
 
@@ -43,8 +48,14 @@ type Payload struct {
 	AddEdges    []tr.Edge `json:"addEdges,omitempty"`    // List of Edges which must be added
 	DeleteEdges []tr.Edge `json:"deleteEdges,omitempty"` // List of Edges which must be deleted
 	ClearAll    bool      `json:"clearAll,omitempty"`    // Tells the aggregator to clear existing data first.
-	RequestId   int       `json:"requestId,omitempty"`   // Unique ID to track each request for debug.
+	RequestId   int       `json:"requestId,omitempty"`   // Unique ID to track each request for debug. Same as Sequence.
 	Version     string    `json:"version,omitempty"`     // Version of this collector
+
+	// Identifies this collector process. Changes every time the collector restarts.
+	InstanceId string `json:"instanceId,omitempty"`
+	// Monotonically increasing number for each payload sent by this instance. A retry of the same payload keeps
+	// the same sequence, so the aggregator can detect a payload that was already applied.
+	Sequence int64 `json:"sequence,omitempty"`
 }
 
 func (p Payload) empty() bool {
@@ -67,72 +78,181 @@ type SyncResponse struct {
 	AddEdgeErrors     []SyncError
 	DeleteEdgeErrors  []SyncError
 	Version           string
+	AlreadyApplied    bool // The aggregator already processed a payload with this instanceId and sequence.
 }
 
 // SyncError is used to respond with errors.
+// Edge errors identify the edge with the UID of the source in ResourceUID, and with DestUID and EdgeType.
+// Aggregators that don't send DestUID and EdgeType only identify the source of the edge.
 type SyncError struct {
 	ResourceUID string
+	DestUID     string `json:",omitempty"`
+	EdgeType    string `json:",omitempty"`
 	Message     string
 }
 
+// Maximum number of times to resend the same payload after a request times out.
+const MAX_TIMEOUT_RETRIES = 3
+
+// Maximum number of times to resend the same payload while the aggregator is busy or has a server error.
+const MAX_BUSY_RETRIES = 5
+
 // Keeps the total data for this cluster as well as the data since the last send operation.
 type Sender struct {
 	aggregatorURL      string // URL of the aggregator, minus any path
 	aggregatorSyncPath string // Path of the aggregator's POST route [ /aggregator/clusters/{clustername}/sync ]
 	httpClient         http.Client
-	lastSentTime       int64 // Time we last successfully sent data to the hub. Gets reset to -1 if a send cycle fails.
+	lastSentTime       int64  // Time we last successfully sent data to the hub. Gets reset to -1 if a send cycle fails.
+	instanceId         string // Unique ID of this sender, sent with every payload.
+	sequence           int64  // Sequence of the last payload created.
 	rec                *reconciler.Reconciler
+	config             *config.Shared    // Shared with the config watcher, so live changes apply on the next cycle.
+	Publisher          *stream.Publisher // Optional. Publishes the changes from each send cycle to an event stream.
+	certs              *certWatcher      // Watches the TLS files for changes. Only used when deployed in the hub.
+	tlsConfigChanged   int32             // Set to 1 when the TLS config changes. Accessed atomically.
+	reportRateFactor   int32             // Multiplies the report rate to send less often. Accessed atomically.
+	pending            *pendingPayload   // Diff that the aggregator couldn't process. Resent on the next cycle.
+}
+
+// A payload to be resent, with the totals expected after the aggregator applies it.
+type pendingPayload struct {
+	payload                Payload
+	expectedTotalResources int
+	expectedTotalEdges     int
 }
 
 func (s *Sender) reloadSender() {
-	s.aggregatorURL = config.Cfg.AggregatorURL
-	s.aggregatorSyncPath = strings.Join([]string{"/aggregator/clusters/", config.Cfg.ClusterName, "/sync"}, "")
-	if !config.Cfg.DeployedInHub {
-		s.aggregatorSyncPath = strings.Join([]string{"/", config.Cfg.ClusterName, "/aggregator/sync"}, "")
+	// Klusterlet deployment: the hub kubeconfig could have been rotated.
+	if !s.config.Get().DeployedInHub {
+		if err := s.config.ReloadHubConfig(); err != nil {
+			glog.Error("Unable to reload the hub config. ", err)
+		}
+	}
+	cfg := s.config.Get()
+	s.aggregatorURL = cfg.AggregatorURL
+	s.aggregatorSyncPath = syncPath(cfg)
+	s.reloadClient()
+}
+
+// Returns the path of the aggregator's POST route.
+func syncPath(cfg *config.Config) string {
+	if !cfg.DeployedInHub {
+		return strings.Join([]string{"/", cfg.ClusterName, "/aggregator/sync"}, "")
+	}
+	return strings.Join([]string{"/aggregator/clusters/", cfg.ClusterName, "/sync"}, "")
+}
+
+// Rebuilds the https client. Keeps the current client if the new one can't be created.
+func (s *Sender) reloadClient() {
+	client, err := getHTTPSClient(s.config.Get())
+	if err != nil {
+		glog.Error("Unable to reload the https client, keeping the current one. ", err)
+		return
+	}
+	s.httpClient.CloseIdleConnections()
+	s.httpClient = client
+}
+
+// Reloads the https client if the TLS config or the TLS files changed on disk.
+func (s *Sender) reloadChangedCerts() {
+	if atomic.CompareAndSwapInt32(&s.tlsConfigChanged, 1, 0) {
+		glog.Info("TLS config changed, reloading the https client.")
+		if cfg := s.config.Get(); cfg.DeployedInHub {
+			s.certs = newCertWatcher(cfg.TLSCAFile, cfg.TLSCertFile, cfg.TLSKeyFile)
+		}
+		s.reloadClient()
+	} else if s.certs != nil && s.certs.changed() {
+		glog.Info("TLS certificates changed on disk, reloading the https client.")
+		s.reloadClient()
+	}
+}
+
+// ConfigChanged is called by the config watcher. Other live settings are read from the config on each send cycle,
+// but the https client needs to be rebuilt when the TLS config changes.
+func (s *Sender) ConfigChanged(changes []config.Change) {
+	for _, c := range changes {
+		if c.Applied && strings.HasPrefix(c.Field, "TLS") {
+			atomic.StoreInt32(&s.tlsConfigChanged, 1)
+			return
+		}
+	}
+}
+
+// SetReportRateFactor multiplies the interval between send cycles, so changes are batched in fewer payloads.
+// Used to shed load when the collector is close to its resource budget. A factor of 1 restores the report rate.
+func (s *Sender) SetReportRateFactor(factor int) {
+	atomic.StoreInt32(&s.reportRateFactor, int32(factor))
+}
+
+// Returns the factor to multiply the interval after a successful send cycle.
+func (s *Sender) reportFactor() time.Duration {
+	factor := time.Duration(atomic.LoadInt32(&s.reportRateFactor))
+	if factor < 1 {
+		factor = 1
 	}
-	s.httpClient = getHTTPSClient()
+	return factor
 }
 
-// Constructs a new Sender using the provided channels.
-// Sends to the URL provided by aggregatorURL, listing itself as clusterName.
-func NewSender(rec *reconciler.Reconciler, aggregatorURL, clusterName string) *Sender {
+// Constructs a new Sender that sends the changes tracked by the reconciler to the aggregator.
+// Returns an error if the https client can't be created with the given config.
+func NewSender(rec *reconciler.Reconciler, shared *config.Shared) (*Sender, error) {
+	cfg := shared.Get()
+	httpClient, err := getHTTPSClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error creating the https client: %w", err)
+	}
 
 	// Construct senders
 	s := &Sender{
-		aggregatorURL:      aggregatorURL,
-		aggregatorSyncPath: strings.Join([]string{"/aggregator/clusters/", clusterName, "/sync"}, ""),
-		httpClient:         getHTTPSClient(),
+		aggregatorURL:      cfg.AggregatorURL,
+		aggregatorSyncPath: syncPath(cfg),
+		httpClient:         httpClient,
 		lastSentTime:       -1,
+		instanceId:         generateInstanceId(),
 		rec:                rec,
+		config:             shared,
 	}
 
-	if !config.Cfg.DeployedInHub {
-		s.aggregatorSyncPath = strings.Join([]string{"/", clusterName, "/aggregator/sync"}, "")
+	if cfg.DeployedInHub {
+		s.certs = newCertWatcher(cfg.TLSCAFile, cfg.TLSCertFile, cfg.TLSKeyFile)
 	}
 
-	return s
+	return s, nil
 }
 
-// Generates a random ID for the request.
-func generateRequestId() int {
-	max := big.NewInt(999099)
-	valBig, err := rand.Int(rand.Reader, max)
-	if err != nil {
-		glog.Warning("Error generating RequestID.")
-		return 0
+// Generates a random ID to identify this collector instance.
+func generateInstanceId() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		glog.Warning("Error generating InstanceId.")
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
 	}
-	return int(valBig.Int64())
+	return hex.EncodeToString(b)
+}
+
+// Returns the sequence number for the next payload.
+func (s *Sender) nextSequence() int64 {
+	s.sequence++
+	return s.sequence
 }
 
 // Returns a payload and expected total resources, add, update, and delete operations since the last send.
 func (s *Sender) diffPayload() (Payload, int, int) {
 
 	diff := s.rec.Diff()
+	if s.Publisher != nil {
+		if err := s.Publisher.PublishDiff(diff); err != nil {
+			glog.Warning("Error publishing diff to the event stream. ", err)
+		}
+	}
 
+	sequence := s.nextSequence()
 	payload := Payload{
-		ClearAll:  false,
-		RequestId: generateRequestId(),
-		Version:   config.COLLECTOR_API_VERSION,
+		ClearAll:   false,
+		RequestId:  int(sequence),
+		Version:    config.COLLECTOR_API_VERSION,
+		InstanceId: s.instanceId,
+		Sequence:   sequence,
 
 		AddResources:     diff.AddNodes,
 		UpdatedResources: diff.UpdateNodes,
@@ -149,11 +269,19 @@ func (s *Sender) diffPayload() (Payload, int, int) {
 func (s *Sender) completePayload() (Payload, int, int) {
 
 	complete := s.rec.Complete()
+	if s.Publisher != nil {
+		if err := s.Publisher.PublishComplete(complete); err != nil {
+			glog.Warning("Error publishing complete state to the event stream. ", err)
+		}
+	}
 
 	// Delete and Update aren't needed when we're sending all the data. Just fill out the adds.
+	sequence := s.nextSequence()
 	payload := Payload{
 		ClearAll:     true,
-		RequestId:    generateRequestId(),
+		RequestId:    int(sequence),
+		InstanceId:   s.instanceId,
+		Sequence:     sequence,
 		AddResources: complete.Nodes,
 
 		AddEdges: complete.Edges,
@@ -162,32 +290,129 @@ func (s *Sender) completePayload() (Payload, int, int) {
 }
 
 // Send will retry after recoverable errors.
-//  - Aggregator busy
+//   - Aggregator busy (429 or 503). Waits for the time requested with Retry-After, up to MaxBackoffMS.
+//   - Aggregator server error (5xx). After MAX_BUSY_RETRIES busy or server errors, returns an UnavailableError.
+//   - Request timed out. The aggregator may have applied the payload, so it is resent with the same sequence.
+//   - Aggregator rejected some resources (4xx). The rejected resources are quarantined and the rest is resent.
+//
+// Other errors reload the config and are returned, so the caller can resend the complete state.
 func (s *Sender) sendWithRetry(payload Payload, expectedTotalResources int, expectedTotalEdges int) error {
 	retry := 0
 	for {
 		sendError := s.send(payload, expectedTotalResources, expectedTotalEdges)
+		if sendError == nil {
+			return nil
+		}
 		retry++
-		nextRetryWait := sendInterval(retry)
-
-		// If indexer was busy, wait and retry with the same payload.
-		if sendError != nil && sendError.Error() == "Aggregator busy" {
-			glog.Warningf("Received busy response from Indexer. Resending in %s.", nextRetryWait)
+		nextRetryWait := s.sendInterval(retry)
+
+		var aggErr *AggregatorError
+		if errors.As(sendError, &aggErr) {
+			// If indexer was busy, wait and retry with the same payload.
+			if aggErr.Busy() && retry <= MAX_BUSY_RETRIES {
+				if aggErr.RetryAfter > 0 {
+					nextRetryWait = aggErr.RetryAfter
+					maxBackoff := time.Duration(s.config.Get().MaxBackoffMS) * time.Millisecond
+					if nextRetryWait > maxBackoff {
+						nextRetryWait = maxBackoff
+					}
+				}
+				glog.Warningf("Received busy response [%d] from Indexer. Resending in %s.", aggErr.StatusCode,
+					nextRetryWait)
+				time.Sleep(nextRetryWait)
+				continue
+			}
+			// If indexer had an internal error, wait and retry with the same payload.
+			if aggErr.ServerError() && retry <= MAX_BUSY_RETRIES {
+				glog.Warningf("Received error response [%s] from Indexer. Resending in %s.", sendError.Error(),
+					nextRetryWait)
+				time.Sleep(nextRetryWait)
+				continue
+			}
+			// The aggregator is overloaded or failing, resending the complete state would only add to its load.
+			// Let the caller resend the same payload later.
+			if aggErr.Busy() || aggErr.ServerError() {
+				return &UnavailableError{Err: aggErr}
+			}
+			// If indexer rejected some resources, quarantine those and retry with the rest of the payload.
+			// Falls through if none of the rejected resources are in the payload, to avoid resending it forever.
+			if rejected := aggErr.Rejected(); len(rejected) > 0 {
+				glog.Warningf("Indexer rejected %d resources in request %d. Quarantining them and resending.",
+					len(rejected), payload.Sequence)
+				nodeCount := len(payload.AddResources) + len(payload.UpdatedResources)
+				payload, expectedTotalResources, expectedTotalEdges = s.quarantine(payload, rejected,
+					expectedTotalResources, expectedTotalEdges)
+				if len(payload.AddResources)+len(payload.UpdatedResources) < nodeCount {
+					continue
+				}
+			}
+		}
+		// If the request timed out, wait and retry with the same payload.
+		var netErr net.Error
+		if errors.As(sendError, &netErr) && netErr.Timeout() && retry <= MAX_TIMEOUT_RETRIES {
+			glog.Warningf("Request %d timed out. Resending the same payload in %s.", payload.Sequence, nextRetryWait)
 			time.Sleep(nextRetryWait)
 			continue
 		}
 		// For other errors, wait, reload the config, and re-send the full state payload.
-		if sendError != nil {
-			glog.Warningf("Received error response [%s] from Indexer. Resetting config and resending in %s.",
-				sendError.Error(), nextRetryWait)
-			time.Sleep(nextRetryWait)
-			config.InitConfig() // re-initialize config to get the latest certificate.
-			s.reloadSender()    // reload sender variables - Aggregator URL, path and client
-		}
+		glog.Warningf("Received error response [%s] from Indexer. Resetting config and resending in %s.",
+			sendError.Error(), nextRetryWait)
+		time.Sleep(nextRetryWait)
+		s.reloadSender() // reload sender variables - Aggregator URL, path and client
 		return sendError
 	}
 }
 
+// Quarantines the resources rejected by the aggregator and removes them from the payload.
+// Returns the updated payload and expected totals.
+func (s *Sender) quarantine(payload Payload, rejected []SyncError, expectedTotalResources,
+	expectedTotalEdges int) (Payload, int, int) {
+	rejectedUIDs := make(map[string]struct{}, len(rejected))
+	for _, r := range rejected {
+		glog.Warningf("Quarantining resource %s. Indexer message: %s", r.ResourceUID, r.Message)
+		rejectedUIDs[r.ResourceUID] = struct{}{}
+		if s.rec != nil {
+			expectedTotalEdges -= s.rec.Quarantine(r.ResourceUID, r.Message)
+		}
+	}
+
+	filterNodes := func(nodes []tr.Node) ([]tr.Node, []tr.Node) {
+		kept := make([]tr.Node, 0, len(nodes))
+		removed := []tr.Node{}
+		for _, n := range nodes {
+			if _, ok := rejectedUIDs[n.UID]; ok {
+				removed = append(removed, n)
+			} else {
+				kept = append(kept, n)
+			}
+		}
+		return kept, removed
+	}
+	filterEdges := func(edges []tr.Edge) []tr.Edge {
+		kept := make([]tr.Edge, 0, len(edges))
+		for _, e := range edges {
+			_, srcRejected := rejectedUIDs[e.SourceUID]
+			_, destRejected := rejectedUIDs[e.DestUID]
+			if !srcRejected && !destRejected {
+				kept = append(kept, e)
+			}
+		}
+		return kept
+	}
+
+	var removedAdds, removedUpdates []tr.Node
+	payload.AddResources, removedAdds = filterNodes(payload.AddResources)
+	payload.UpdatedResources, removedUpdates = filterNodes(payload.UpdatedResources)
+	// The aggregator has a previous version of the updated resources. Delete it, so our states stay in sync.
+	for _, n := range removedUpdates {
+		payload.DeletedResources = append(payload.DeletedResources, tr.Deletion{UID: n.UID})
+	}
+	payload.AddEdges = filterEdges(payload.AddEdges)
+	payload.DeleteEdges = filterEdges(payload.DeleteEdges)
+
+	return payload, expectedTotalResources - len(removedAdds) - len(removedUpdates), expectedTotalEdges
+}
+
 // Sends data to the aggregator and returns an error if it didn't work.
 // Pointer receiver because Sender contains a mutex - that freaked the linter out even though it
 // doesn't use the mutex. Changed it so that if we do need to use the mutex we wont have any problems.
@@ -210,15 +435,8 @@ func (s *Sender) send(payload Payload, expectedTotalResources int, expectedTotal
 		glog.Error("httpClient error: ", err)
 		return err
 	}
-	if resp.StatusCode == http.StatusTooManyRequests {
-		return errors.New("Aggregator busy")
-	} else if resp.StatusCode != http.StatusOK {
-		msg := fmt.Sprintf("POST to: %s responded with error. StatusCode: %d  Message: %s",
-			s.aggregatorURL+s.aggregatorSyncPath, resp.StatusCode, resp.Status)
-		if resp.StatusCode == http.StatusUnauthorized {
-			msg = "401 Unauthorized"
-		}
-		return errors.New(msg)
+	if resp.StatusCode != http.StatusOK {
+		return newAggregatorError(s.aggregatorURL+s.aggregatorSyncPath, resp)
 	}
 
 	r := SyncResponse{}
@@ -228,26 +446,98 @@ func (s *Sender) send(payload Payload, expectedTotalResources int, expectedTotal
 		return err
 	}
 
+	// The aggregator detected a duplicate of a payload it already processed (i.e. a retry after a timeout).
+	if r.AlreadyApplied {
+		glog.Infof("Aggregator already applied request %d from instance %s.", payload.Sequence, payload.InstanceId)
+		return nil
+	}
+
 	// Compare size that comes back in r to size that we track, accounting for the errors reported by the aggregator.
 	if r.TotalResources != (expectedTotalResources + len(r.DeleteErrors) - len(r.AddErrors)) {
-		msg := fmt.Sprintf("Aggregator reported wrong number of total resources. Expected %d, got %d",
-			expectedTotalResources, r.TotalResources)
-		return errors.New(msg)
+		return &CountMismatchError{Kind: "resources", Expected: expectedTotalResources, Actual: r.TotalResources}
 	}
 
 	if r.TotalEdges != (expectedTotalEdges + len(r.DeleteEdgeErrors) - len(r.AddEdgeErrors)) {
-		msg := fmt.Sprintf("Aggregator reported wrong number of total intra edges. Expected %d, got %d",
-			expectedTotalEdges, r.TotalEdges)
-		return errors.New(msg)
+		return &CountMismatchError{Kind: "intra edges", Expected: expectedTotalEdges, Actual: r.TotalEdges}
+	}
+
+	// Let the reconciler know which resources failed, so they are resent with the next diff.
+	if s.rec != nil {
+		s.rec.RetryFailures(syncFailures(payload, r))
 	}
 
 	// Check the total
 	return nil
 }
 
+// Maps the errors in the aggregator response to the nodes and edges in the payload.
+// Only the edges matching the source, destination and type of an error are retried. An edge error without
+// destination and type retries all the edges from its source. Edge errors that don't match an edge in the
+// payload are logged and dropped.
+func syncFailures(payload Payload, r SyncResponse) reconciler.SyncFailures {
+	nodeFailures := func(errs []SyncError) []reconciler.NodeFailure {
+		failures := make([]reconciler.NodeFailure, 0, len(errs))
+		for _, e := range errs {
+			failures = append(failures, reconciler.NodeFailure{UID: e.ResourceUID, Message: e.Message})
+		}
+		return failures
+	}
+	edgeFailures := func(errs []SyncError, edges []tr.Edge) []reconciler.EdgeFailure {
+		if len(errs) == 0 {
+			return nil
+		}
+		edgeKey := func(source, edgeType, dest string) string { return source + "-" + edgeType + "->" + dest }
+		inPayload := make(map[string]tr.Edge, len(edges))
+		bySource := make(map[string][]tr.Edge)
+		for _, edge := range edges {
+			inPayload[edgeKey(edge.SourceUID, string(edge.EdgeType), edge.DestUID)] = edge
+			bySource[edge.SourceUID] = append(bySource[edge.SourceUID], edge)
+		}
+		failures := []reconciler.EdgeFailure{}
+		failed := make(map[string]struct{}) // Keys of the failed edges, to retry each edge once.
+		addFailure := func(key string, edge tr.Edge, message string) {
+			if _, ok := failed[key]; !ok {
+				failed[key] = struct{}{}
+				failures = append(failures, reconciler.EdgeFailure{Edge: edge, Message: message})
+			}
+		}
+		for _, e := range errs {
+			if e.DestUID == "" && e.EdgeType == "" {
+				if len(bySource[e.ResourceUID]) == 0 {
+					glog.Warningf("Aggregator failed to apply edges from %s, which aren't in the payload. Message: %s",
+						e.ResourceUID, e.Message)
+				}
+				for _, edge := range bySource[e.ResourceUID] {
+					addFailure(edgeKey(edge.SourceUID, string(edge.EdgeType), edge.DestUID), edge, e.Message)
+				}
+				continue
+			}
+			key := edgeKey(e.ResourceUID, e.EdgeType, e.DestUID)
+			edge, ok := inPayload[key]
+			if !ok {
+				glog.Warningf("Aggregator failed to apply edge %s, which isn't in the payload. Message: %s", key,
+					e.Message)
+				continue
+			}
+			addFailure(key, edge, e.Message)
+		}
+		return failures
+	}
+
+	return reconciler.SyncFailures{
+		AddNodes:    nodeFailures(r.AddErrors),
+		UpdateNodes: nodeFailures(r.UpdateErrors),
+		DeleteNodes: nodeFailures(r.DeleteErrors),
+		AddEdges:    edgeFailures(r.AddEdgeErrors, payload.AddEdges),
+		DeleteEdges: edgeFailures(r.DeleteEdgeErrors, payload.DeleteEdges),
+	}
+}
+
 // Sends data to the aggregator.
 // Attempts to send a diff, then just sends the complete if the aggregator appears to need that.
 func (s *Sender) Sync() error {
+	s.reloadChangedCerts()
+
 	if s.lastSentTime == -1 { // If we have never sent before, we just send the complete.
 		glog.Info("First time sending or last Sync cycle failed, sending complete payload")
 		payload, expectedTotalResources, expectedTotalEdges := s.completePayload()
@@ -261,17 +551,44 @@ func (s *Sender) Sync() error {
 		return nil
 	}
 
+	// Resend the diff that the aggregator couldn't process before sending the next one.
+	if s.pending != nil {
+		glog.Infof("Resending request %d, which the aggregator couldn't process.", s.pending.payload.Sequence)
+		err := s.sendWithRetry(s.pending.payload, s.pending.expectedTotalResources, s.pending.expectedTotalEdges)
+		var unavailable *UnavailableError
+		if errors.As(err, &unavailable) {
+			return err
+		}
+		s.pending = nil
+		if err != nil {
+			// The diff is lost, so start over with a complete payload next time.
+			glog.Error("Error resending diff payload. ", err)
+			s.lastSentTime = -1
+			return err
+		}
+		s.lastSentTime = time.Now().Unix()
+		return nil
+	}
+
 	// If this isn't the first time we've sent, we can now attempt to send a diff.
 	payload, expectedTotalResources, expectedTotalEdges := s.diffPayload()
 	if payload.empty() {
 		// check if a ping is necessary
-		if time.Now().Unix()-s.lastSentTime < int64(config.Cfg.HeartbeatMS/1000) {
+		if time.Now().Unix()-s.lastSentTime < int64(s.config.Get().HeartbeatMS/1000) {
 			glog.V(3).Info("Nothing to send, skipping send cycle.")
 			return nil
 		}
 		glog.V(2).Info("Sending empty payload for heartbeat.")
 	}
 	err := s.sendWithRetry(payload, expectedTotalResources, expectedTotalEdges)
+	var unavailable *UnavailableError
+	if errors.As(err, &unavailable) {
+		// The aggregator state is still valid. Keep the diff and resend it on the next cycle.
+		glog.Warning("Aggregator unavailable, resending the diff payload on the next cycle. ", err)
+		s.pending = &pendingPayload{payload: payload, expectedTotalResources: expectedTotalResources,
+			expectedTotalEdges: expectedTotalEdges}
+		return err
+	}
 	if err != nil {
 		// If something went wrong here, form a new complete payload (only necessary because
 		// currentState may have changed since we got it, and we have to keep our diffs synced)
@@ -308,7 +625,7 @@ func (s *Sender) StartSendLoop() {
 			glog.Error("SEND ERROR: ", err)
 			// Increase the backoffFactor, doubling the wait time. Stops increasing after it passes the max
 			// wait time so that we don't overflow int. Can be changed with env:MAX_BACKOFF_MS
-			if sendInterval(backoffFactor) < time.Duration(config.Cfg.MaxBackoffMS)*time.Millisecond {
+			if s.sendInterval(backoffFactor) < time.Duration(s.config.Get().MaxBackoffMS)*time.Millisecond {
 				backoffFactor++
 			}
 		} else {
@@ -316,9 +633,11 @@ func (s *Sender) StartSendLoop() {
 			backoffFactor = 1 // Reset backoff to 1 because we had a sucessful send.
 		}
 
-		nextSendWait := sendInterval(backoffFactor)
+		nextSendWait := s.sendInterval(backoffFactor)
 		if backoffFactor > 1 {
 			glog.Warningf("Error during last sync. Resending in %s.", nextSendWait)
+		} else {
+			nextSendWait *= s.reportFactor() // Send less often while shedding load.
 		}
 		// Sleep either for the current backed off interval, or the maximum time defined in the config
 		time.Sleep(nextSendWait)
@@ -334,14 +653,14 @@ func min(a, b int) int {
 }
 
 // Compute the time interval to wait before next send or retry (backoff).
-func sendInterval(retry int) time.Duration {
-	nextInterval := int(1000*math.Exp2(float64(retry))) + addJitter()
-	return time.Duration(min(nextInterval, config.Cfg.MaxBackoffMS)) * time.Millisecond
+func (s *Sender) sendInterval(retry int) time.Duration {
+	nextInterval := int(1000*math.Exp2(float64(retry))) + s.addJitter()
+	return time.Duration(min(nextInterval, s.config.Get().MaxBackoffMS)) * time.Millisecond
 }
 
 // Generate a random jitter to add to the backoff retry to prevent clients from retrying at the same interval.
-func addJitter() int {
-	max := big.NewInt(int64(config.Cfg.RetryJitterMS))
+func (s *Sender) addJitter() int {
+	max := big.NewInt(int64(s.config.Get().RetryJitterMS))
 	j, err := rand.Int(rand.Reader, max)
 	if err != nil {
 		return 0
@@ -16,12 +16,33 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/stolostron/search-collector/pkg/config"
+	"github.com/stolostron/search-collector/pkg/reconciler"
 	"github.com/stolostron/search-collector/pkg/transforms"
 	"github.com/stretchr/testify/assert"
 )
 
+// Returns the default config for a collector deployed in the hub.
+func testConfig() *config.Config {
+	return &config.Config{
+		ClusterName:      "local-cluster",
+		DeployedInHub:    true,
+		HeartbeatMS:      config.DEFAULT_HEARTBEAT_MS,
+		MaxBackoffMS:     config.DEFAULT_MAX_BACKOFF_MS,
+		ReportRateMS:     config.DEFAULT_REPORT_RATE_MS,
+		RetryJitterMS:    config.DEFAULT_RETRY_JITTER_MS,
+		TLSCAFile:        config.DEFAULT_TLS_CA_FILE,
+		TLSCertFile:      config.DEFAULT_TLS_CERT_FILE,
+		TLSKeyFile:       config.DEFAULT_TLS_KEY_FILE,
+		TLSMinVersion:    config.DEFAULT_TLS_MIN_VERSION,
+		TLSCipherProfile: config.DEFAULT_TLS_CIPHER_PROFILE,
+	}
+}
+
 func TestSenderWrongCount(t *testing.T) {
 	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		response := SyncResponse{
@@ -37,6 +58,7 @@ func TestSenderWrongCount(t *testing.T) {
 	defer ts.Close()
 
 	s := Sender{
+		config:        config.NewShared(testConfig()),
 		httpClient:    *ts.Client(),
 		aggregatorURL: ts.URL,
 	}
@@ -62,6 +84,7 @@ func TestSenderUnavailable(t *testing.T) {
 	defer ts.Close()
 
 	s := Sender{
+		config:        config.NewShared(testConfig()),
 		httpClient:    *ts.Client(),
 		aggregatorURL: ts.URL,
 	}
@@ -99,6 +122,7 @@ func TestSenderSuccessful(t *testing.T) {
 	defer ts.Close()
 
 	s := Sender{
+		config:        config.NewShared(testConfig()),
 		httpClient:    *ts.Client(),
 		aggregatorURL: ts.URL,
 	}
@@ -119,6 +143,336 @@ func TestSenderSuccessful(t *testing.T) {
 	}
 }
 
+func TestSenderSequence(t *testing.T) {
+	s := Sender{
+		config:     config.NewShared(testConfig()),
+		instanceId: generateInstanceId(),
+		rec:        reconciler.NewReconciler(reconciler.Options{}),
+	}
+
+	complete, _, _ := s.completePayload()
+	diff, _, _ := s.diffPayload()
+
+	assert.NotEmpty(t, s.instanceId)
+	assert.Equal(t, s.instanceId, complete.InstanceId)
+	assert.Equal(t, s.instanceId, diff.InstanceId)
+	assert.Equal(t, int64(1), complete.Sequence)
+	assert.Equal(t, int64(2), diff.Sequence)
+	assert.Equal(t, 2, diff.RequestId)
+}
+
+func TestSenderAlreadyApplied(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := SyncResponse{
+			AlreadyApplied: true,
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(200)
+		err := json.NewEncoder(w).Encode(response)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	s := Sender{
+		config:        config.NewShared(testConfig()),
+		httpClient:    *ts.Client(),
+		aggregatorURL: ts.URL,
+	}
+
+	// Totals don't match, but the aggregator reports the payload was already applied.
+	err := s.send(Payload{InstanceId: "test", Sequence: 7}, 5, 5)
+	assert.Nil(t, err)
+}
+
+func TestSenderRetryAfterTimeout(t *testing.T) {
+	var mutex sync.Mutex
+	received := []Payload{}
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := Payload{}
+		_ = json.NewDecoder(r.Body).Decode(&p)
+		mutex.Lock()
+		received = append(received, p)
+		first := len(received) == 1
+		mutex.Unlock()
+		if first {
+			time.Sleep(200 * time.Millisecond) // Force the first request to time out.
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(200)
+		_ = json.NewEncoder(w).Encode(SyncResponse{AlreadyApplied: !first})
+	}))
+	defer ts.Close()
+
+	client := ts.Client()
+	client.Timeout = 100 * time.Millisecond
+	cfg := testConfig()
+	cfg.MaxBackoffMS = 1 // Don't wait between retries.
+	s := Sender{
+		config:        config.NewShared(cfg),
+		httpClient:    *client,
+		aggregatorURL: ts.URL,
+	}
+
+	err := s.sendWithRetry(Payload{InstanceId: "test", Sequence: 3}, 0, 0)
+	assert.Nil(t, err)
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, 2, len(received))
+	assert.Equal(t, received[0].Sequence, received[1].Sequence)
+}
+
+func TestSenderBusyRetryAfter(t *testing.T) {
+	var mutex sync.Mutex
+	requests := 0
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests++
+		first := requests == 1
+		mutex.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if first {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(503)
+			return
+		}
+		w.WriteHeader(200)
+		_ = json.NewEncoder(w).Encode(SyncResponse{})
+	}))
+	defer ts.Close()
+
+	s := Sender{
+		config:        config.NewShared(testConfig()),
+		httpClient:    *ts.Client(),
+		aggregatorURL: ts.URL,
+	}
+
+	err := s.sendWithRetry(Payload{}, 0, 0)
+	assert.Nil(t, err)
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, 2, requests)
+}
+
+func TestSenderBusyRetryAfterClamped(t *testing.T) {
+	var mutex sync.Mutex
+	requests := 0
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests++
+		first := requests == 1
+		mutex.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if first {
+			w.Header().Set("Retry-After", "86400")
+			w.WriteHeader(429)
+			return
+		}
+		w.WriteHeader(200)
+		_ = json.NewEncoder(w).Encode(SyncResponse{})
+	}))
+	defer ts.Close()
+
+	cfg := testConfig()
+	cfg.MaxBackoffMS = 10 // The wait requested with Retry-After can't be longer than this.
+	s := Sender{
+		config:        config.NewShared(cfg),
+		httpClient:    *ts.Client(),
+		aggregatorURL: ts.URL,
+	}
+
+	start := time.Now()
+	err := s.sendWithRetry(Payload{}, 0, 0)
+	assert.Nil(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, 2, requests)
+}
+
+func TestSenderPersistentServiceUnavailable(t *testing.T) {
+	var mutex sync.Mutex
+	requests := 0
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests++
+		mutex.Unlock()
+		w.WriteHeader(503)
+	}))
+	defer ts.Close()
+
+	cfg := testConfig()
+	cfg.MaxBackoffMS = 1 // Don't wait between retries.
+	s := Sender{
+		config:        config.NewShared(cfg),
+		httpClient:    *ts.Client(),
+		aggregatorURL: ts.URL,
+	}
+
+	err := s.sendWithRetry(Payload{}, 0, 0)
+	var unavailable *UnavailableError
+	assert.ErrorAs(t, err, &unavailable)
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, MAX_BUSY_RETRIES+1, requests)
+}
+
+func TestSenderSyncUnavailableKeepsDiff(t *testing.T) {
+	var mutex sync.Mutex
+	received := []Payload{}
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := Payload{}
+		_ = json.NewDecoder(r.Body).Decode(&p)
+		mutex.Lock()
+		received = append(received, p)
+		busy := len(received) <= MAX_BUSY_RETRIES+1
+		mutex.Unlock()
+		if busy {
+			w.WriteHeader(503)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(200)
+		_ = json.NewEncoder(w).Encode(SyncResponse{})
+	}))
+	defer ts.Close()
+
+	cfg := testConfig()
+	cfg.MaxBackoffMS = 1 // Don't wait between retries.
+	s := Sender{
+		config:        config.NewShared(cfg),
+		httpClient:    *ts.Client(),
+		aggregatorURL: ts.URL,
+		rec:           reconciler.NewReconciler(reconciler.Options{}),
+		lastSentTime:  1, // Sends an empty diff for the heartbeat.
+	}
+
+	err := s.Sync()
+	var unavailable *UnavailableError
+	assert.ErrorAs(t, err, &unavailable)
+	assert.NotEqual(t, int64(-1), s.lastSentTime)
+
+	// The next cycle resends the same diff.
+	assert.Nil(t, s.Sync())
+	assert.Nil(t, s.pending)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, MAX_BUSY_RETRIES+2, len(received))
+	for _, p := range received {
+		assert.False(t, p.ClearAll, "complete payload sent while the aggregator was unavailable")
+		assert.Equal(t, received[0].Sequence, p.Sequence)
+	}
+}
+
+func TestSenderQuarantineRejected(t *testing.T) {
+	var mutex sync.Mutex
+	received := []Payload{}
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := Payload{}
+		_ = json.NewDecoder(r.Body).Decode(&p)
+		mutex.Lock()
+		received = append(received, p)
+		first := len(received) == 1
+		mutex.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if first {
+			w.WriteHeader(400)
+			_ = json.NewEncoder(w).Encode(SyncResponse{
+				AddErrors: []SyncError{{ResourceUID: "bad", Message: "invalid property"}},
+			})
+			return
+		}
+		w.WriteHeader(200)
+		_ = json.NewEncoder(w).Encode(SyncResponse{TotalResources: 1})
+	}))
+	defer ts.Close()
+
+	s := Sender{
+		config:        config.NewShared(testConfig()),
+		httpClient:    *ts.Client(),
+		aggregatorURL: ts.URL,
+	}
+
+	payload := Payload{
+		AddResources: []transforms.Node{{UID: "good"}, {UID: "bad"}},
+		AddEdges:     []transforms.Edge{{SourceUID: "good", DestUID: "bad", EdgeType: "ownedBy"}},
+	}
+	err := s.sendWithRetry(payload, 2, 0)
+	assert.Nil(t, err)
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, 2, len(received))
+	assert.Equal(t, 1, len(received[1].AddResources))
+	assert.Equal(t, "good", received[1].AddResources[0].UID)
+	assert.Equal(t, 0, len(received[1].AddEdges))
+}
+
+func Test_syncFailures(t *testing.T) {
+	payload := Payload{
+		AddEdges: []transforms.Edge{
+			{SourceUID: "a", DestUID: "b", EdgeType: "runsOn"},
+			{SourceUID: "a", DestUID: "c", EdgeType: "ownedBy"},
+			{SourceUID: "c", DestUID: "b", EdgeType: "runsOn"},
+		},
+		DeleteEdges: []transforms.Edge{{SourceUID: "d", DestUID: "b", EdgeType: "ownedBy"}},
+	}
+	response := SyncResponse{
+		AddErrors:    []SyncError{{ResourceUID: "x", Message: "bad"}},
+		DeleteErrors: []SyncError{{ResourceUID: "y", Message: "missing"}},
+		AddEdgeErrors: []SyncError{
+			{ResourceUID: "a", DestUID: "b", EdgeType: "runsOn", Message: "bad edge"},
+			{ResourceUID: "e", DestUID: "b", EdgeType: "runsOn", Message: "not sent"},
+		},
+		DeleteEdgeErrors: []SyncError{{ResourceUID: "d", DestUID: "b", EdgeType: "ownedBy", Message: "missing edge"}},
+	}
+
+	failures := syncFailures(payload, response)
+
+	assert.Equal(t, []reconciler.NodeFailure{{UID: "x", Message: "bad"}}, failures.AddNodes)
+	assert.Empty(t, failures.UpdateNodes)
+	assert.Equal(t, []reconciler.NodeFailure{{UID: "y", Message: "missing"}}, failures.DeleteNodes)
+	assert.Equal(t, []reconciler.EdgeFailure{{Edge: payload.AddEdges[0], Message: "bad edge"}}, failures.AddEdges)
+	assert.Equal(t, []reconciler.EdgeFailure{{Edge: payload.DeleteEdges[0], Message: "missing edge"}},
+		failures.DeleteEdges)
+}
+
+func Test_syncFailuresSourceOnly(t *testing.T) {
+	payload := Payload{
+		AddEdges: []transforms.Edge{
+			{SourceUID: "a", DestUID: "b", EdgeType: "runsOn"},
+			{SourceUID: "a", DestUID: "c", EdgeType: "ownedBy"},
+			{SourceUID: "c", DestUID: "b", EdgeType: "runsOn"},
+		},
+	}
+	// The aggregator only identifies the source of the failed edges.
+	response := SyncResponse{
+		AddEdgeErrors: []SyncError{
+			{ResourceUID: "a", Message: "bad edge"},
+			{ResourceUID: "a", DestUID: "b", EdgeType: "runsOn", Message: "bad edge"},
+			{ResourceUID: "e", Message: "not sent"},
+		},
+	}
+
+	failures := syncFailures(payload, response)
+
+	assert.Equal(t, []reconciler.EdgeFailure{
+		{Edge: payload.AddEdges[0], Message: "bad edge"},
+		{Edge: payload.AddEdges[1], Message: "bad edge"},
+	}, failures.AddEdges)
+	assert.Empty(t, failures.DeleteEdges)
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 30*time.Second, parseRetryAfter("30", now))
+	assert.Equal(t, 10*time.Second, parseRetryAfter("Fri, 01 Jan 2021 00:00:10 GMT", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("-5", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon", now))
+}
+
 func Test_minIsB(t *testing.T) {
 	min := min(11, 99)
 	assert.Equal(t, 11, min)
@@ -129,18 +483,32 @@ func Test_minIsA(t *testing.T) {
 }
 
 func Test_sendInterval(t *testing.T) {
-	wait := sendInterval(1)
+	s := Sender{config: config.NewShared(testConfig())}
+	wait := s.sendInterval(1)
 	assert.GreaterOrEqual(t, wait.Milliseconds(), int64(1000))
 	assert.LessOrEqual(t, wait.Milliseconds(), int64(7000))
 }
 
 func Test_sendInterval_maxBackoff(t *testing.T) {
-	wait := sendInterval(50)
+	s := Sender{config: config.NewShared(testConfig())}
+	wait := s.sendInterval(50)
 	assert.Equal(t, int64(600000), wait.Milliseconds())
 }
 
 func Test_sendInterval_minBackoff(t *testing.T) {
-	wait := sendInterval(0)
+	s := Sender{config: config.NewShared(testConfig())}
+	wait := s.sendInterval(0)
 	assert.GreaterOrEqual(t, wait.Milliseconds(), int64(0))
 	assert.LessOrEqual(t, wait.Milliseconds(), int64(6000))
 }
+
+func Test_reportFactor(t *testing.T) {
+	s := Sender{config: config.NewShared(testConfig())}
+	assert.Equal(t, time.Duration(1), s.reportFactor())
+
+	s.SetReportRateFactor(4)
+	assert.Equal(t, time.Duration(4), s.reportFactor())
+
+	s.SetReportRateFactor(1)
+	assert.Equal(t, time.Duration(1), s.reportFactor())
+}
@@ -0,0 +1,52 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package stream
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileProducer appends messages to a local file, one JSON document per line.
+// Used to consume the event stream without a message broker, mostly for development and testing.
+type FileProducer struct {
+	file  *os.File
+	mutex sync.Mutex
+}
+
+// NewFileProducer opens (or creates) the file at path. New messages are appended to the end of the file.
+func NewFileProducer(path string) (*FileProducer, error) {
+	file, err := os.OpenFile(filepath.Clean(path), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileProducer{file: file}, nil
+}
+
+// Produce writes the messages to the file and flushes them to disk.
+func (p *FileProducer) Produce(messages []Message) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	writer := bufio.NewWriter(p.file)
+	encoder := json.NewEncoder(writer) // Encode() adds the trailing newline.
+	for i := range messages {
+		if err := encoder.Encode(messages[i]); err != nil {
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	return p.file.Sync()
+}
+
+// Close closes the underlying file.
+func (p *FileProducer) Close() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.file.Close()
+}
@@ -0,0 +1,40 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package stream
+
+import (
+	tr "github.com/stolostron/search-collector/pkg/transforms"
+)
+
+// Operations published on the event stream.
+const (
+	OperationAdd    = "add"
+	OperationUpdate = "update"
+	OperationDelete = "delete"
+)
+
+// Types of objects published on the event stream.
+const (
+	TypeNode = "node"
+	TypeEdge = "edge"
+)
+
+// A single keyed change published to the event stream.
+// Messages with the same key are always published in the order the changes were observed.
+type Message struct {
+	Offset    int64    `json:"offset"`           // Monotonically increasing position of this message in the stream.
+	Key       string   `json:"key"`              // UID of the node. For edges, the UID of the source node.
+	Type      string   `json:"type"`             // node or edge
+	Operation string   `json:"operation"`        // add, update or delete
+	Time      int64    `json:"time"`             // Unix time when the message was published.
+	Resync    bool     `json:"resync,omitempty"` // Set when the message is part of a complete state resync.
+	Node      *tr.Node `json:"node,omitempty"`   // Not set for node deletions.
+	Edge      *tr.Edge `json:"edge,omitempty"`
+}
+
+// Producer writes messages to a stream backend (i.e. a Kafka topic or a local file).
+// Implementations must preserve the order of the messages within each call to Produce.
+type Producer interface {
+	Produce(messages []Message) error
+	Close() error
+}
@@ -0,0 +1,113 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package stream
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	rec "github.com/stolostron/search-collector/pkg/reconciler"
+	tr "github.com/stolostron/search-collector/pkg/transforms"
+)
+
+// Publisher converts the changes computed by the reconciler into keyed messages and sends them to a Producer.
+// A Publisher can be shared by the senders of multiple clusters, offsets stay monotonic across all of them.
+type Publisher struct {
+	producer Producer
+	mutex    sync.Mutex
+	offset   int64 // Offset of the last message published.
+}
+
+// Creates a new Publisher that sends messages to the given producer.
+func NewPublisher(producer Producer) *Publisher {
+	return &Publisher{producer: producer}
+}
+
+// Publishes the changes from a reconciler Diff.
+// Messages are ordered so that a consumer applying them in sequence never sees an edge to a missing node:
+// node adds, node updates, edge adds, edge deletes, and last node deletes.
+func (p *Publisher) PublishDiff(diff rec.Diff) error {
+	messages := make([]Message, 0, len(diff.AddNodes)+len(diff.UpdateNodes)+len(diff.DeleteNodes)+
+		len(diff.AddEdges)+len(diff.DeleteEdges))
+
+	messages = append(messages, nodeMessages(diff.AddNodes, OperationAdd)...)
+	messages = append(messages, nodeMessages(diff.UpdateNodes, OperationUpdate)...)
+	messages = append(messages, edgeMessages(diff.AddEdges, OperationAdd)...)
+	messages = append(messages, edgeMessages(diff.DeleteEdges, OperationDelete)...)
+
+	deletes := make([]tr.Deletion, len(diff.DeleteNodes))
+	copy(deletes, diff.DeleteNodes)
+	sort.Slice(deletes, func(i, j int) bool { return deletes[i].UID < deletes[j].UID })
+	for _, d := range deletes {
+		messages = append(messages, Message{Key: d.UID, Type: TypeNode, Operation: OperationDelete})
+	}
+
+	return p.publish(messages, false)
+}
+
+// Publishes the complete state as add messages flagged with resync.
+// Consumers should treat these as upserts, the stream doesn't carry deletes for nodes removed while disconnected.
+func (p *Publisher) PublishComplete(complete rec.CompleteState) error {
+	messages := nodeMessages(complete.Nodes, OperationAdd)
+	messages = append(messages, edgeMessages(complete.Edges, OperationAdd)...)
+	return p.publish(messages, true)
+}
+
+// Close the underlying producer.
+func (p *Publisher) Close() error {
+	return p.producer.Close()
+}
+
+func (p *Publisher) publish(messages []Message, resync bool) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	now := time.Now().Unix()
+	for i := range messages {
+		p.offset++
+		messages[i].Offset = p.offset
+		messages[i].Time = now
+		messages[i].Resync = resync
+	}
+	glog.V(3).Infof("Publishing %d messages to event stream. Last offset: %d", len(messages), p.offset)
+	return p.producer.Produce(messages)
+}
+
+// Builds node messages sorted by UID.
+func nodeMessages(nodes []tr.Node, operation string) []Message {
+	messages := make([]Message, 0, len(nodes))
+	for i := range nodes {
+		messages = append(messages, Message{
+			Key:       nodes[i].UID,
+			Type:      TypeNode,
+			Operation: operation,
+			Node:      &nodes[i],
+		})
+	}
+	sort.SliceStable(messages, func(i, j int) bool { return messages[i].Key < messages[j].Key })
+	return messages
+}
+
+// Builds edge messages keyed and sorted by source UID.
+func edgeMessages(edges []tr.Edge, operation string) []Message {
+	messages := make([]Message, 0, len(edges))
+	for i := range edges {
+		messages = append(messages, Message{
+			Key:       edges[i].SourceUID,
+			Type:      TypeEdge,
+			Operation: operation,
+			Edge:      &edges[i],
+		})
+	}
+	sort.SliceStable(messages, func(i, j int) bool {
+		if messages[i].Key == messages[j].Key {
+			return messages[i].Edge.DestUID < messages[j].Edge.DestUID
+		}
+		return messages[i].Key < messages[j].Key
+	})
+	return messages
+}
@@ -0,0 +1,106 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package stream
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	rec "github.com/stolostron/search-collector/pkg/reconciler"
+	tr "github.com/stolostron/search-collector/pkg/transforms"
+	"github.com/stretchr/testify/assert"
+)
+
+// Reads all the messages written by a FileProducer.
+func readMessages(t *testing.T, path string) []Message {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal("Unable to open stream file. ", err)
+	}
+	defer file.Close()
+
+	messages := []Message{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		m := Message{}
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			t.Fatal("Unable to decode message. ", err)
+		}
+		messages = append(messages, m)
+	}
+	return messages
+}
+
+func newNode(uid string) tr.Node {
+	return tr.Node{UID: uid, Properties: map[string]interface{}{"kind": "Pod", "name": uid}}
+}
+
+func Test_PublishDiff(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stream.json")
+	producer, err := NewFileProducer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewPublisher(producer)
+
+	diff := rec.Diff{
+		AddNodes:    []tr.Node{newNode("cluster/b"), newNode("cluster/a")},
+		UpdateNodes: []tr.Node{newNode("cluster/c")},
+		DeleteNodes: []tr.Deletion{{UID: "cluster/d"}},
+		AddEdges:    []tr.Edge{{EdgeType: "runsOn", SourceUID: "cluster/a", DestUID: "cluster/b"}},
+		DeleteEdges: []tr.Edge{{EdgeType: "runsOn", SourceUID: "cluster/d", DestUID: "cluster/c"}},
+	}
+	assert.Nil(t, p.PublishDiff(diff))
+	assert.Nil(t, p.Close())
+
+	messages := readMessages(t, path)
+	assert.Equal(t, 6, len(messages))
+
+	expected := []struct{ key, kind, operation string }{
+		{"cluster/a", TypeNode, OperationAdd},
+		{"cluster/b", TypeNode, OperationAdd},
+		{"cluster/c", TypeNode, OperationUpdate},
+		{"cluster/a", TypeEdge, OperationAdd},
+		{"cluster/d", TypeEdge, OperationDelete},
+		{"cluster/d", TypeNode, OperationDelete},
+	}
+	for i, e := range expected {
+		assert.Equal(t, int64(i+1), messages[i].Offset)
+		assert.Equal(t, e.key, messages[i].Key)
+		assert.Equal(t, e.kind, messages[i].Type)
+		assert.Equal(t, e.operation, messages[i].Operation)
+		assert.False(t, messages[i].Resync)
+	}
+	assert.Equal(t, "Pod", messages[0].Node.Properties["kind"])
+	assert.Equal(t, "cluster/b", messages[3].Edge.DestUID)
+	assert.Nil(t, messages[5].Node)
+}
+
+func Test_PublishComplete_ContinuesOffset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stream.json")
+	producer, err := NewFileProducer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewPublisher(producer)
+
+	assert.Nil(t, p.PublishDiff(rec.Diff{AddNodes: []tr.Node{newNode("cluster/a")}}))
+	assert.Nil(t, p.PublishDiff(rec.Diff{})) // Empty diffs don't write anything.
+	assert.Nil(t, p.PublishComplete(rec.CompleteState{
+		Nodes: []tr.Node{newNode("cluster/a"), newNode("cluster/b")},
+		Edges: []tr.Edge{{EdgeType: "ownedBy", SourceUID: "cluster/b", DestUID: "cluster/a"}},
+	}))
+	assert.Nil(t, p.Close())
+
+	messages := readMessages(t, path)
+	assert.Equal(t, 4, len(messages))
+	for i, m := range messages {
+		assert.Equal(t, int64(i+1), m.Offset)
+		assert.Equal(t, OperationAdd, m.Operation)
+		assert.Equal(t, i > 0, m.Resync)
+	}
+	assert.Equal(t, TypeEdge, messages[3].Type)
+}
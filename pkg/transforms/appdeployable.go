@@ -21,8 +21,8 @@ type AppDeployableResource struct {
 }
 
 // AppDeployableResourceBuilder ...
-func AppDeployableResourceBuilder(d *appDeployable.Deployable) *AppDeployableResource {
-	node := transformCommon(d)         // Start off with the common properties
+func AppDeployableResourceBuilder(d *appDeployable.Deployable, opts Options) *AppDeployableResource {
+	node := transformCommon(d, opts)   // Start off with the common properties
 	apiGroupVersion(d.TypeMeta, &node) // add kind, apigroup and version
 	// Extract the properties specific to this type
 	//TODO: Add properties, TEMPLATE-KIND   TEMPLATE-APIVERSION    AGE   STATUS
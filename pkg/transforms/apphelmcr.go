@@ -23,8 +23,8 @@ type AppHelmCRResource struct {
 }
 
 // AppHelmCRResourceBuilder ...
-func AppHelmCRResourceBuilder(a *app.HelmRelease) *AppHelmCRResource {
-	node := transformCommon(a)         // Start off with the common properties
+func AppHelmCRResourceBuilder(a *app.HelmRelease, opts Options) *AppHelmCRResource {
+	node := transformCommon(a, opts)   // Start off with the common properties
 	apiGroupVersion(a.TypeMeta, &node) // add kind, apigroup and version
 
 	// Add other properties
@@ -22,7 +22,7 @@ func TestTransformAppHelmCR(t *testing.T) {
 
 	UnmarshalFile("apphelmcr.json", &a, t)
 
-	node := AppHelmCRResourceBuilder(&a).BuildNode()
+	node := AppHelmCRResourceBuilder(&a, testOptions).BuildNode()
 
 	// Test only the fields that exist in HelmRelease - the common test will test the other bits
 	AssertEqual("name", node.Properties["name"], "testAppHelmCR", t)
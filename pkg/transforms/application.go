@@ -23,8 +23,8 @@ type ApplicationResource struct {
 }
 
 // ApplicationResourceBuilder ...
-func ApplicationResourceBuilder(a *app.Application) *ApplicationResource {
-	node := transformCommon(a)
+func ApplicationResourceBuilder(a *app.Application, opts Options) *ApplicationResource {
+	node := transformCommon(a, opts)
 	apiGroupVersion(a.TypeMeta, &node) // add kind, apigroup and version
 	// Extract the properties specific to this type
 	node.Properties["dashboard"] = a.GetAnnotations()["apps.open-cluster-management.io/dashboard"]
@@ -5,6 +5,7 @@ package transforms
 import (
 	"encoding/json"
 	"strings"
+	"time"
 
 	"github.com/golang/glog"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -26,6 +27,8 @@ type ArgoApplication struct {
 type ArgoApplicationSpec struct {
 	Source      ArgoApplicationSource      `json:"source" protobuf:"bytes,1,opt,name=source"`
 	Destination ArgoApplicationDestination `json:"destination" protobuf:"bytes,2,name=destination"`
+	// Sources is used instead of Source by the applications with multiple sources
+	Sources []ArgoApplicationSource `json:"sources,omitempty" protobuf:"bytes,8,opt,name=sources"`
 }
 
 type ArgoApplicationSource struct {
@@ -47,6 +50,7 @@ type ArgoApplicationStatus struct {
 	OperationState *OperationState        `json:"operationState,omitempty" protobuf:"bytes,7,opt,name=operationState"`
 	Health         HealthStatus           `json:"health" protobuf:"bytes,1,opt,name=health"`
 	Sync           SyncStatus             `json:"sync,omitempty" protobuf:"bytes,2,opt,name=sync"`
+	History        []RevisionHistory      `json:"history,omitempty" protobuf:"bytes,3,opt,name=history"`
 }
 
 type ResourceStatus struct {
@@ -69,6 +73,20 @@ type OperationState struct {
 	Phase string `json:"phase" protobuf:"bytes,2,opt,name=phase"`
 	// Message holds any pertinent messages when attempting to perform operation (typically errors).
 	Message string `json:"message,omitempty" protobuf:"bytes,3,opt,name=message"`
+	// StartedAt contains time of operation start
+	StartedAt metav1.Time `json:"startedAt" protobuf:"bytes,6,opt,name=startedAt"`
+	// FinishedAt contains time of operation completion
+	FinishedAt *metav1.Time `json:"finishedAt,omitempty" protobuf:"bytes,7,opt,name=finishedAt"`
+}
+
+// RevisionHistory contains history information about a previous sync
+type RevisionHistory struct {
+	// Revision holds the revision the sync was performed against
+	Revision string `json:"revision,omitempty" protobuf:"bytes,2,opt,name=revision"`
+	// DeployedAt holds the time the sync operation completed
+	DeployedAt metav1.Time `json:"deployedAt" protobuf:"bytes,4,opt,name=deployedAt"`
+	// Revisions holds the revision of each source in sources field the sync was performed against
+	Revisions []string `json:"revisions,omitempty" protobuf:"bytes,9,opt,name=revisions"`
 }
 
 type HealthStatus struct {
@@ -76,12 +94,14 @@ type HealthStatus struct {
 }
 
 type SyncStatus struct {
-	Status string `json:"status" protobuf:"bytes,1,opt,name=status,casttype=SyncStatusCode"`
+	Status    string   `json:"status" protobuf:"bytes,1,opt,name=status,casttype=SyncStatusCode"`
+	Revision  string   `json:"revision,omitempty" protobuf:"bytes,3,opt,name=revision"`
+	Revisions []string `json:"revisions,omitempty" protobuf:"bytes,4,opt,name=revisions"`
 }
 
 // ArgoApplicationResourceBuilder ...
-func ArgoApplicationResourceBuilder(a *ArgoApplication) *ArgoApplicationResource {
-	node := transformCommon(a)
+func ArgoApplicationResourceBuilder(a *ArgoApplication, opts Options) *ArgoApplicationResource {
+	node := transformCommon(a, opts)
 	apiGroupVersion(a.TypeMeta, &node) // add kind, apigroup and version
 
 	// Extract the properties specific to this type
@@ -110,20 +130,49 @@ func ArgoApplicationResourceBuilder(a *ArgoApplication) *ArgoApplicationResource
 	node.Properties["destinationName"] = a.Spec.Destination.Name
 	node.Properties["destinationNamespace"] = a.Spec.Destination.Namespace
 	node.Properties["destinationServer"] = a.Spec.Destination.Server
+	node.Properties["destinationCluster"] = destinationCluster(a.Spec.Destination)
 
-	// Source properties
-	node.Properties["path"] = a.Spec.Source.Path
-	node.Properties["chart"] = a.Spec.Source.Chart
-	node.Properties["repoURL"] = a.Spec.Source.RepoURL
-	node.Properties["targetRevision"] = a.Spec.Source.TargetRevision
+	// Source properties, from the first source of the applications with multiple sources
+	sources := a.Spec.Sources
+	if len(sources) == 0 {
+		sources = []ArgoApplicationSource{a.Spec.Source}
+	}
+	node.Properties["path"] = sources[0].Path
+	node.Properties["chart"] = sources[0].Chart
+	node.Properties["repoURL"] = sources[0].RepoURL
+	node.Properties["targetRevision"] = sources[0].TargetRevision
 
-	if a.Spec.Source.TargetRevision == "" {
+	if sources[0].TargetRevision == "" {
 		node.Properties["targetRevision"] = "HEAD"
 	}
+	node.Properties["sources"] = formatArgoSources(sources)
 
 	// Status properties
 	node.Properties["healthStatus"] = a.Status.Health.Status
 	node.Properties["syncStatus"] = a.Status.Sync.Status
+	if revision := joinRevisions(a.Status.Sync.Revision, a.Status.Sync.Revisions); revision != "" {
+		node.Properties["revision"] = revision
+	}
+
+	// Revision history, the last entry is the current deployment
+	if len(a.Status.History) > 0 {
+		revisions := make([]string, 0, len(a.Status.History))
+		for i := len(a.Status.History) - 1; i >= 0; i-- {
+			revisions = append(revisions, joinRevisions(a.Status.History[i].Revision, a.Status.History[i].Revisions))
+		}
+		node.Properties["revisionHistory"] = revisions
+		node.Properties["lastDeployed"] = a.Status.History[len(a.Status.History)-1].DeployedAt.UTC().Format(time.RFC3339)
+	}
+
+	// Timing of the last sync operation
+	if a.Status.OperationState != nil && !a.Status.OperationState.StartedAt.IsZero() {
+		node.Properties["lastSyncPhase"] = a.Status.OperationState.Phase
+		node.Properties["lastSyncStarted"] = a.Status.OperationState.StartedAt.UTC().Format(time.RFC3339)
+		if finished := a.Status.OperationState.FinishedAt; finished != nil && !finished.IsZero() {
+			node.Properties["lastSyncFinished"] = finished.UTC().Format(time.RFC3339)
+			node.Properties["lastSyncDuration"] = int64(finished.Sub(a.Status.OperationState.StartedAt.Time).Seconds())
+		}
+	}
 
 	// conditions properties, each condition type is a property
 	for _, condition := range a.Status.Conditions {
@@ -147,6 +196,48 @@ func ArgoApplicationResourceBuilder(a *ArgoApplication) *ArgoApplicationResource
 	return &ArgoApplicationResource{node: node, resources: a.Status.Resources}
 }
 
+// Returns the cluster where the application is deployed. The destination has the name or the server of the cluster,
+// the server of the cluster where Argo CD runs is known as in-cluster.
+func destinationCluster(d ArgoApplicationDestination) string {
+	switch {
+	case d.Name != "":
+		return d.Name
+	case d.Server == "https://kubernetes.default.svc":
+		return "in-cluster"
+	}
+	return d.Server
+}
+
+// Formats the sources as repoURL/path@targetRevision, or repoURL/chart@targetRevision for Helm charts.
+func formatArgoSources(sources []ArgoApplicationSource) []string {
+	ret := make([]string, 0, len(sources))
+	for _, s := range sources {
+		if s.RepoURL == "" {
+			continue
+		}
+		source := s.RepoURL
+		if s.Chart != "" {
+			source = strings.TrimSuffix(source, "/") + "/" + s.Chart
+		} else if s.Path != "" && s.Path != "." {
+			source = strings.TrimSuffix(source, "/") + "/" + s.Path
+		}
+		revision := s.TargetRevision
+		if revision == "" {
+			revision = "HEAD"
+		}
+		ret = append(ret, source+"@"+revision)
+	}
+	return ret
+}
+
+// Applications with multiple sources have a revision for each source.
+func joinRevisions(revision string, revisions []string) string {
+	if revision != "" {
+		return revision
+	}
+	return strings.Join(revisions, ",")
+}
+
 func TruncateText(text string, width int) string {
 	if width < 0 {
 		glog.Warningf("text truncation width is less than zero, width: %v", width)
@@ -9,7 +9,7 @@ import (
 func TestTransformArgoApplication(t *testing.T) {
 	var a ArgoApplication
 	UnmarshalFile("argoapplication.json", &a, t)
-	argoApplicationResource := ArgoApplicationResourceBuilder(&a)
+	argoApplicationResource := ArgoApplicationResourceBuilder(&a, testOptions)
 
 	node := argoApplicationResource.BuildNode()
 
@@ -20,10 +20,13 @@ func TestTransformArgoApplication(t *testing.T) {
 	AssertEqual("destinationName", node.Properties["destinationName"], "local-cluster", t)
 	AssertEqual("destinationNamespace", node.Properties["destinationNamespace"], "argo-helloworld", t)
 	AssertEqual("destinationServer", node.Properties["destinationServer"], "https://kubernetes.default.svc", t)
+	AssertEqual("destinationCluster", node.Properties["destinationCluster"], "local-cluster", t)
 	AssertEqual("path", node.Properties["path"], "helloworld", t)
 	AssertEqual("chart", node.Properties["chart"], "hello-chart", t)
 	AssertEqual("repoURL", node.Properties["repoURL"], "https://github.com/fxiang1/app-samples", t)
 	AssertEqual("targetRevision", node.Properties["targetRevision"], "HEAD", t)
+	AssertDeepEqual("sources", node.Properties["sources"],
+		[]string{"https://github.com/fxiang1/app-samples/hello-chart@HEAD"}, t)
 	AssertEqual("healthStatus", node.Properties["healthStatus"], "Missing", t)
 	AssertEqual("syncStatus", node.Properties["syncStatus"], "OutOfSync", t)
 
@@ -68,3 +71,29 @@ func TestTransformArgoApplication(t *testing.T) {
 	AssertEqual("edges", len(edges), 5, t)
 	AssertEqual("missingResources", node.Properties["_missingResources"], nil, t)
 }
+
+func TestTransformArgoApplicationMultiSource(t *testing.T) {
+	var a ArgoApplication
+	UnmarshalFile("argoapplication-multisource.json", &a, t)
+	node := ArgoApplicationResourceBuilder(&a, testOptions).BuildNode()
+
+	// Source properties are from the first source.
+	AssertEqual("chart", node.Properties["chart"], "guestbook", t)
+	AssertEqual("repoURL", node.Properties["repoURL"], "https://charts.example.com/", t)
+	AssertEqual("targetRevision", node.Properties["targetRevision"], "1.2.0", t)
+	AssertDeepEqual("sources", node.Properties["sources"], []string{"https://charts.example.com/guestbook@1.2.0",
+		"https://github.com/example/guestbook-config.git@HEAD"}, t)
+	AssertEqual("destinationCluster", node.Properties["destinationCluster"], "in-cluster", t)
+
+	// Revisions, with the most recent deployment first.
+	AssertEqual("revision", node.Properties["revision"], "1.2.0,9f8e7d6c5b4a39281706f5e4d3c2b1a098765432", t)
+	AssertDeepEqual("revisionHistory", node.Properties["revisionHistory"], []string{
+		"1.2.0,9f8e7d6c5b4a39281706f5e4d3c2b1a098765432", "1.1.0,2b5e7f9a0c1d3e4f5a6b7c8d9e0f1a2b3c4d5e6f"}, t)
+	AssertEqual("lastDeployed", node.Properties["lastDeployed"], "2023-05-03T14:20:52Z", t)
+
+	// Timing of the last sync operation.
+	AssertEqual("lastSyncPhase", node.Properties["lastSyncPhase"], "Succeeded", t)
+	AssertEqual("lastSyncStarted", node.Properties["lastSyncStarted"], "2023-05-03T14:20:10Z", t)
+	AssertEqual("lastSyncFinished", node.Properties["lastSyncFinished"], "2023-05-03T14:20:52Z", t)
+	AssertEqual("lastSyncDuration", node.Properties["lastSyncDuration"], int64(42), t)
+}
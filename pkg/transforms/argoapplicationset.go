@@ -0,0 +1,117 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ArgoApplicationSetResource ...
+type ArgoApplicationSetResource struct {
+	node Node
+}
+
+type ArgoApplicationSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata" protobuf:"bytes,1,opt,name=metadata"`
+	Spec              ArgoApplicationSetSpec   `json:"spec" protobuf:"bytes,2,opt,name=spec"`
+	Status            ArgoApplicationSetStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+}
+
+type ArgoApplicationSetSpec struct {
+	// Each generator is an object with a single key, the generator type. The generators have many types and
+	// fields, only the types are collected.
+	Generators []map[string]interface{}   `json:"generators" protobuf:"bytes,2,name=generators"`
+	Template   ArgoApplicationSetTemplate `json:"template" protobuf:"bytes,3,name=template"`
+}
+
+type ArgoApplicationSetTemplate struct {
+	Spec ArgoApplicationSpec `json:"spec" protobuf:"bytes,2,opt,name=spec"`
+}
+
+type ArgoApplicationSetStatus struct {
+	// Resources are the Applications generated by the ApplicationSet
+	Resources []ResourceStatus `json:"resources,omitempty" protobuf:"bytes,3,opt,name=resources"`
+}
+
+// ArgoApplicationSetResourceBuilder ...
+func ArgoApplicationSetResourceBuilder(a *ArgoApplicationSet, opts Options) *ArgoApplicationSetResource {
+	node := transformCommon(a, opts)
+	apiGroupVersion(a.TypeMeta, &node) // add kind, apigroup and version
+
+	// Extract the properties specific to this type
+	node.Properties["generators"] = formatGenerators(a.Spec.Generators)
+	node.Properties["applicationCount"] = int64(len(a.Status.Resources))
+
+	// Template properties, these may have parameters like {{name}} that are replaced by the generators
+	sources := a.Spec.Template.Spec.Sources
+	if len(sources) == 0 {
+		sources = []ArgoApplicationSource{a.Spec.Template.Spec.Source}
+	}
+	node.Properties["templateSources"] = formatArgoSources(sources)
+	node.Properties["templateDestination"] = destinationCluster(a.Spec.Template.Spec.Destination)
+	node.Properties["templateDestinationNamespace"] = a.Spec.Template.Spec.Destination.Namespace
+
+	return &ArgoApplicationSetResource{node: node}
+}
+
+// Returns the types of the generators. The matrix and merge generators combine other generators, which are
+// included in parenthesis, for example matrix(clusterDecisionResource,git).
+func formatGenerators(generators []map[string]interface{}) []string {
+	ret := make([]string, 0, len(generators))
+	for _, generator := range generators {
+		types := make([]string, 0, len(generator))
+		for generatorType := range generator {
+			if generatorType != "selector" { // The selector filters the parameters of any generator type.
+				types = append(types, generatorType)
+			}
+		}
+		sort.Strings(types)
+		for _, generatorType := range types {
+			spec, _ := generator[generatorType].(map[string]interface{})
+			if nested, ok := spec["generators"].([]interface{}); ok &&
+				(generatorType == "matrix" || generatorType == "merge") {
+				children := make([]map[string]interface{}, 0, len(nested))
+				for _, child := range nested {
+					if c, ok := child.(map[string]interface{}); ok {
+						children = append(children, c)
+					}
+				}
+				generatorType += "(" + strings.Join(formatGenerators(children), ",") + ")"
+			}
+			ret = append(ret, generatorType)
+		}
+	}
+	return ret
+}
+
+// BuildNode construct the node for the ApplicationSet Resources
+func (a ArgoApplicationSetResource) BuildNode() Node {
+	return a.node
+}
+
+// BuildEdges construct the edges for the ApplicationSet Resources
+// See documentation at pkg/transforms/README.md
+func (a ArgoApplicationSetResource) BuildEdges(ns NodeStore) []Edge {
+	ret := []Edge{}
+
+	// generates edges to the Argo CD Applications owned by the ApplicationSet. The Applications can be in any
+	// namespace watched by Argo CD.
+	for _, apps := range ns.ByKindNamespaceName["Application"] {
+		for _, app := range apps {
+			if app.Properties["apigroup"] == "argoproj.io" && app.GetMetadata("OwnerUID") == a.node.UID {
+				ret = append(ret, Edge{
+					EdgeType:   "generates",
+					SourceUID:  a.node.UID,
+					SourceKind: a.node.Properties["kind"].(string),
+					DestUID:    app.UID,
+					DestKind:   "Application",
+				})
+			}
+		}
+	}
+	return ret
+}
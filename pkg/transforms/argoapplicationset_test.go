@@ -0,0 +1,45 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	"testing"
+)
+
+func TestTransformArgoApplicationSet(t *testing.T) {
+	var a ArgoApplicationSet
+	UnmarshalFile("argoapplicationset.json", &a, t)
+	node := ArgoApplicationSetResourceBuilder(&a, testOptions).BuildNode()
+
+	// Test only the fields that exist in applicationset - the common test will test the other bits
+	AssertEqual("kind", node.Properties["kind"], "ApplicationSet", t)
+	AssertDeepEqual("generators", node.Properties["generators"],
+		[]string{"clusterDecisionResource", "matrix(clusters,git)"}, t)
+	AssertEqual("applicationCount", node.Properties["applicationCount"], int64(1), t)
+	AssertDeepEqual("templateSources", node.Properties["templateSources"],
+		[]string{"https://github.com/fxiang1/app-samples/helloworld@HEAD"}, t)
+	AssertEqual("templateDestination", node.Properties["templateDestination"], "{{server}}", t)
+	AssertEqual("templateDestinationNamespace", node.Properties["templateDestinationNamespace"], "argo-helloworld", t)
+}
+
+func TestArgoApplicationSetBuildEdges(t *testing.T) {
+	var a ArgoApplicationSet
+	UnmarshalFile("argoapplicationset.json", &a, t)
+	appSet := ArgoApplicationSetResourceBuilder(&a, testOptions)
+
+	// Build a fake NodeStore with the Application generated by the ApplicationSet.
+	var app ArgoApplication
+	UnmarshalFile("argoapplication.json", &app, t)
+	nodeStore := BuildFakeNodeStore([]Node{ArgoApplicationResourceBuilder(&app, testOptions).BuildNode()})
+
+	appNode := nodeStore.ByKindNamespaceName["Application"]["argocd"]["helloworld"]
+
+	edges := appSet.BuildEdges(nodeStore)
+	AssertEqual("ApplicationSet edge total: ", len(edges), 1, t)
+	AssertEqual("ApplicationSet generates", edges[0].EdgeType, EdgeType("generates"), t)
+	AssertEqual("ApplicationSet generates", edges[0].DestUID, appNode.UID, t)
+
+	// Applications from the app.k8s.io group aren't generated by ApplicationSets.
+	appNode.Properties["apigroup"] = "app.k8s.io"
+	AssertEqual("ApplicationSet edge total: ", len(appSet.BuildEdges(nodeStore)), 0, t)
+}
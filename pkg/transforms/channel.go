@@ -21,8 +21,8 @@ type ChannelResource struct {
 }
 
 // ChannelResourceBuilder ...
-func ChannelResourceBuilder(c *app.Channel) *ChannelResource {
-	node := transformCommon(c)
+func ChannelResourceBuilder(c *app.Channel, opts Options) *ChannelResource {
+	node := transformCommon(c, opts)
 	apiGroupVersion(c.TypeMeta, &node) // add kind, apigroup and version
 	// Extract the properties specific to this type
 	node.Properties["type"] = string(c.Spec.Type)
@@ -11,11 +11,12 @@ Copyright (c) 2020 Red Hat, Inc.
 package transforms
 
 import (
+	"reflect"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/golang/glog"
-	"github.com/stolostron/search-collector/pkg/config"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	apiTypes "k8s.io/apimachinery/pkg/types"
 )
@@ -25,15 +26,88 @@ import (
 type NodeStore struct {
 	ByUID               map[string]Node
 	ByKindNamespaceName map[string]map[string]map[string]Node
+	PodsByNodeName      map[string][]Node // Pods using resources on each Node. Built with IndexPodsByNodeName.
+	// Optional. Properties computed from other nodes while building the edges, keyed by node UID.
+	Computed map[string]map[string]interface{}
+}
+
+// Metadata key with the comma separated names of the properties computed from other nodes.
+const computedPropertiesKey = "_computedProperties"
+
+// Indexes the pods by the name of the Node where they are scheduled. Pods that finished are not included,
+// because they don't use resources on the Node anymore.
+func IndexPodsByNodeName(allNodes map[string]Node) map[string][]Node {
+	ret := make(map[string][]Node)
+	for _, n := range allNodes {
+		if nodeName := n.Metadata["NodeName"]; nodeName != "" && n.Properties["kind"] == "Pod" {
+			ret[nodeName] = append(ret[nodeName], n)
+		}
+	}
+	return ret
+}
+
+// Records the properties of a node computed from other nodes while building the edges, i.e. the pods running on
+// a Node. The nodes in the store aren't modified, the reconciler applies the properties with WithComputedProperties.
+func (ns NodeStore) setComputedProperties(uid string, props map[string]interface{}) {
+	if ns.Computed != nil {
+		ns.Computed[uid] = props
+	}
+}
+
+// Returns a copy of the node with the computed properties, and true if a value changed. The node is returned
+// as is when nothing changed, its maps are shared with the reconciler state and aren't modified.
+func WithComputedProperties(node Node, props map[string]interface{}) (Node, bool) {
+	keys := make([]string, 0, len(props))
+	changed := false
+	for key, value := range props {
+		keys = append(keys, key)
+		if !reflect.DeepEqual(node.Properties[key], value) {
+			changed = true
+		}
+	}
+	sort.Strings(keys)
+	computed := strings.Join(keys, ",")
+	if !changed && node.Metadata[computedPropertiesKey] == computed {
+		return node, false
+	}
+
+	updated := node
+	updated.Properties = make(map[string]interface{}, len(node.Properties)+len(props))
+	for key, value := range node.Properties {
+		updated.Properties[key] = value
+	}
+	for key, value := range props {
+		updated.Properties[key] = value
+	}
+	updated.Metadata = make(map[string]string, len(node.Metadata)+1)
+	for key, value := range node.Metadata {
+		updated.Metadata[key] = value
+	}
+	updated.Metadata[computedPropertiesKey] = computed
+	return updated, changed
+}
+
+// Copies the computed properties from the previous version of a node to the new version, so they aren't
+// seen as a change while they are computed again with the edges.
+func CopyComputedProperties(from, to Node) {
+	if from.Metadata[computedPropertiesKey] == "" || to.Properties == nil || to.Metadata == nil {
+		return
+	}
+	for _, key := range strings.Split(from.Metadata[computedPropertiesKey], ",") {
+		if value, ok := from.Properties[key]; ok {
+			to.Properties[key] = value
+		}
+	}
+	to.Metadata[computedPropertiesKey] = from.Metadata[computedPropertiesKey]
 }
 
 // Extracts the common properties from a k8s resource of any type and returns a map ready to be put in a Node
-func commonProperties(resource v1.Object) map[string]interface{} {
+func commonProperties(resource v1.Object, opts Options) map[string]interface{} {
 	ret := make(map[string]interface{})
 
 	ret["name"] = resource.GetName()
 	ret["created"] = resource.GetCreationTimestamp().UTC().Format(time.RFC3339)
-	if config.Cfg.DeployedInHub {
+	if opts.DeployedInHub {
 		ret["_hubClusterResource"] = true
 	}
 
@@ -50,17 +124,20 @@ func commonProperties(resource v1.Object) map[string]interface{} {
 	if resource.GetAnnotations()["apps.open-cluster-management.io/hosting-deployable"] != "" {
 		ret["_hostingDeployable"] = resource.GetAnnotations()["apps.open-cluster-management.io/hosting-deployable"]
 	}
+	if kustomization := hostingKustomization(resource.GetLabels()); kustomization != "" {
+		ret["_hostingKustomization"] = kustomization
+	}
 	return ret
 }
 
 // Transforms a resource of unknown type by simply pulling out the common properties.
-func transformCommon(resource v1.Object) Node {
+func transformCommon(resource v1.Object, opts Options) Node {
 	n := Node{
-		UID:        prefixedUID(resource.GetUID()),
-		Properties: commonProperties(resource),
+		UID:        prefixedUID(resource.GetUID(), opts),
+		Properties: commonProperties(resource, opts),
 		Metadata:   make(map[string]string),
 	}
-	n.Metadata["OwnerUID"] = ownerRefUID(resource.GetOwnerReferences())
+	n.Metadata["OwnerUID"] = ownerRefUID(resource.GetOwnerReferences(), opts)
 	// Adding OwnerReleaseName and Namespace to resources that doesn't have ownerRef but are deployed by a release.
 	if n.Metadata["OwnerUID"] == "" && resource.GetAnnotations()["meta.helm.sh/release-name"] != "" &&
 		resource.GetAnnotations()["meta.helm.sh/release-namespace"] != "" {
@@ -118,17 +195,17 @@ func CommonEdges(uid string, ns NodeStore) []Edge {
 	return ret
 }
 
-// Prefixes the given UID with the cluster name from config and a /
-func prefixedUID(uid apiTypes.UID) string {
-	return strings.Join([]string{config.Cfg.ClusterName, string(uid)}, "/")
+// Prefixes the given UID with the cluster name from the options and a /
+func prefixedUID(uid apiTypes.UID, opts Options) string {
+	return strings.Join([]string{opts.ClusterName, string(uid)}, "/")
 }
 
-// Prefixes the given UID with the cluster name from config and a /
-func ownerRefUID(ownerReferences []v1.OwnerReference) string {
+// Prefixes the given UID with the cluster name from the options and a /
+func ownerRefUID(ownerReferences []v1.OwnerReference, opts Options) string {
 	ownerUID := ""
 	for _, ref := range ownerReferences {
 		if ref.Controller != nil && *ref.Controller {
-			ownerUID = prefixedUID(ref.UID)
+			ownerUID = prefixedUID(ref.UID, opts)
 			continue
 		}
 	}
@@ -349,6 +426,7 @@ func edgesByDeployerSubscriber(nodeInfo NodeInfo, ns NodeStore) []Edge {
 		}
 		subscription := ""
 		deployable := ""
+		kustomization := ""
 		if node, ok := ns.ByUID[UID]; ok {
 			if subscription, ok = node.Properties["_hostingSubscription"].(string); ok &&
 				node.Properties["_hostingSubscription"] != "" {
@@ -360,13 +438,18 @@ func edgesByDeployerSubscriber(nodeInfo NodeInfo, ns NodeStore) []Edge {
 				nodeInfo.EdgeType = "definedBy"
 				ret = append(ret, edgesByDepSub(deployable, "Deployable")...)
 			}
+			if kustomization, ok = node.Properties["_hostingKustomization"].(string); ok &&
+				node.Properties["_hostingKustomization"] != "" {
+				nodeInfo.EdgeType = "deployedBy"
+				ret = append(ret, edgesByDepSub(kustomization, "Kustomization")...)
+			}
 			seenDests = append(seenDests, UID) // add UID to processed/seen destinations
 
-			// Recursively call the function with ownerUID, if the node doesn't have hosting deployable/subscription
-			// properties but has an owner reference.
+			// Recursively call the function with ownerUID, if the node doesn't have hosting
+			// deployable/subscription/kustomization properties but has an owner reference.
 			// This is mainly to create edges from pods to subscription/deployable, when the hosting
 			// deployable/subscription properties are not in pods, but present in deployments
-			if subscription == "" && deployable == "" {
+			if subscription == "" && deployable == "" && kustomization == "" {
 				if node.GetMetadata("OwnerUID") != "" {
 					node = ns.ByUID[node.GetMetadata("OwnerUID")]
 					ret = findSub(node.UID)
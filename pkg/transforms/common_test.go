@@ -18,6 +18,9 @@ import (
 	machineryV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// Options used to build the nodes in the tests.
+var testOptions = Options{ClusterName: "local-cluster", DeployedInHub: true}
+
 var labels = map[string]string{"app": "test", "fake": "true", "component": "testapp"}
 var timestamp = machineryV1.Now()
 
@@ -40,7 +43,7 @@ func TestCommonProperties(t *testing.T) {
 	res := CreateGenericResource()
 	timeString := timestamp.UTC().Format(time.RFC3339)
 
-	cp := commonProperties(res)
+	cp := commonProperties(res, testOptions)
 
 	// Test all the fields.
 	AssertEqual("name", cp["name"], interface{}("testpod"), t)
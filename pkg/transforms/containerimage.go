@@ -0,0 +1,133 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	defaultRegistry = "docker.io"
+	containerImages = "containerimages" // Resource string of the ContainerImage nodes.
+)
+
+// The parts of a container image reference, i.e. quay.io/stolostron/search-collector:2.9@sha256:abc...
+type containerImage struct {
+	Registry, Repository, Tag, Digest string
+}
+
+// Parses an image reference like the container runtime does. Images without a registry are from docker.io,
+// and official images are in the library repository. Images without a tag or digest use the latest tag.
+func parseImageReference(image string) containerImage {
+	ret := containerImage{}
+	if i := strings.Index(image, "@"); i >= 0 {
+		image, ret.Digest = image[:i], image[i+1:]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image, ret.Tag = image[:i], image[i+1:]
+	}
+
+	ret.Registry, ret.Repository = defaultRegistry, image
+	if i := strings.Index(image, "/"); i >= 0 {
+		if host := image[:i]; strings.ContainsAny(host, ".:") || host == "localhost" {
+			ret.Registry, ret.Repository = host, image[i+1:]
+		}
+	}
+	if ret.Registry == defaultRegistry && !strings.Contains(ret.Repository, "/") {
+		ret.Repository = "library/" + ret.Repository
+	}
+	if ret.Tag == "" && ret.Digest == "" {
+		ret.Tag = "latest"
+	}
+	return ret
+}
+
+// Returns the digest from the imageID of a container status. The runtime reports it as
+// [docker-pullable://]repository@digest. An imageID without repository is the local ID of the image, not the
+// digest in the registry, so it's ignored.
+func imageIDDigest(imageID string) string {
+	if i := strings.Index(imageID, "@"); i >= 0 {
+		return imageID[i+1:]
+	}
+	return ""
+}
+
+// Returns the full reference of the image, which identifies the ContainerImage node.
+func (c containerImage) String() string {
+	ref := c.Registry + "/" + c.Repository
+	if c.Tag != "" {
+		ref += ":" + c.Tag
+	}
+	if c.Digest != "" {
+		ref += "@" + c.Digest
+	}
+	return ref
+}
+
+// Returns a deterministic UID for the image, so all the pods using it refer to the same node. The UID is formatted
+// like the UIDs of the k8s resources, from the sha256 of the full reference.
+func (c containerImage) uid(opts Options) string {
+	sum := sha256.Sum256([]byte(c.String()))
+	return fmt.Sprintf("%s/%x-%x-%x-%x-%x", opts.ClusterName, sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// Builds the synthetic ContainerImage node. It isn't a k8s resource, so it only has the image properties.
+func (c containerImage) node(opts Options) Node {
+	node := Node{
+		UID:            c.uid(opts),
+		ResourceString: containerImages,
+		Properties: map[string]interface{}{
+			"kind":        "ContainerImage",
+			"kind_plural": containerImages,
+			"name":        c.String(),
+			"registry":    c.Registry,
+			"repository":  c.Repository,
+		},
+		Metadata: make(map[string]string),
+	}
+	if c.Tag != "" {
+		node.Properties["tag"] = c.Tag
+	}
+	if c.Digest != "" {
+		node.Properties["digest"] = c.Digest
+	}
+	if opts.DeployedInHub {
+		node.Properties["_hubClusterResource"] = true
+	}
+	return node
+}
+
+// Returns the nodes for the unique images used by the init containers and containers of the pod. The digest comes
+// from the container statuses, so it's only known after the image is pulled.
+func podContainerImages(p *v1.Pod, opts Options) []Node {
+	imageIDs := make(map[string]string)
+	for _, statuses := range [][]v1.ContainerStatus{p.Status.InitContainerStatuses, p.Status.ContainerStatuses} {
+		for _, s := range statuses {
+			imageIDs[s.Name] = s.ImageID
+		}
+	}
+
+	ret := []Node{}
+	seen := make(map[string]struct{})
+	for _, containers := range [][]v1.Container{p.Spec.InitContainers, p.Spec.Containers} {
+		for _, c := range containers {
+			if c.Image == "" {
+				continue
+			}
+			image := parseImageReference(c.Image)
+			if image.Digest == "" {
+				image.Digest = imageIDDigest(imageIDs[c.Name])
+			}
+			node := image.node(opts)
+			if _, ok := seen[node.UID]; !ok {
+				seen[node.UID] = struct{}{}
+				ret = append(ret, node)
+			}
+		}
+	}
+	return ret
+}
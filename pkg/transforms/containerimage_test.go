@@ -0,0 +1,50 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestParseImageReference(t *testing.T) {
+	tests := map[string]containerImage{
+		"nginx":                      {Registry: "docker.io", Repository: "library/nginx", Tag: "latest"},
+		"bitnami/redis:7.2":          {Registry: "docker.io", Repository: "bitnami/redis", Tag: "7.2"},
+		"localhost/app":              {Registry: "localhost", Repository: "app", Tag: "latest"},
+		"registry:5000/team/app:1.0": {Registry: "registry:5000", Repository: "team/app", Tag: "1.0"},
+		"quay.io/stolostron/search-collector@sha256:abc": {Registry: "quay.io",
+			Repository: "stolostron/search-collector", Digest: "sha256:abc"},
+		"quay.io/stolostron/search-collector:2.9@sha256:abc": {Registry: "quay.io",
+			Repository: "stolostron/search-collector", Tag: "2.9", Digest: "sha256:abc"},
+	}
+	for image, expected := range tests {
+		AssertEqual(image, parseImageReference(image), expected, t)
+	}
+}
+
+func TestTransformPodContainerImages(t *testing.T) {
+	var p v1.Pod
+	UnmarshalFile("pod.json", &p, t)
+	images := PodResourceBuilder(&p, testOptions).BuildSyntheticNodes()
+
+	AssertEqual("images", len(images), 1, t)
+	digest := "sha256:396c3d5a7ee6174f6f9ca0f626474673a003b0be87afec31a4e91e61ebd9ab70"
+	AssertEqual("kind", images[0].Properties["kind"], "ContainerImage", t)
+	AssertEqual("name", images[0].Properties["name"], "docker.io/library/fake-image:latest@"+digest, t)
+	AssertEqual("registry", images[0].Properties["registry"], "docker.io", t)
+	AssertEqual("repository", images[0].Properties["repository"], "library/fake-image", t)
+	AssertEqual("tag", images[0].Properties["tag"], "latest", t)
+	AssertEqual("digest", images[0].Properties["digest"], digest, t)
+
+	// Pods using the same image have the same ContainerImage node.
+	p.UID = "another-pod"
+	AssertEqual("UID", PodResourceBuilder(&p, testOptions).BuildSyntheticNodes()[0].UID, images[0].UID, t)
+
+	// The digest isn't known until the image is pulled.
+	p.Status.ContainerStatuses = nil
+	pending := PodResourceBuilder(&p, testOptions).BuildSyntheticNodes()
+	AssertEqual("digest", pending[0].Properties["digest"], nil, t)
+	AssertEqual("UID", pending[0].UID == images[0].UID, false, t)
+}
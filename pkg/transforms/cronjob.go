@@ -14,16 +14,18 @@ import (
 	"time"
 
 	v1 "k8s.io/api/batch/v1beta1"
+	core "k8s.io/api/core/v1"
 )
 
 // CronJobResource ...
 type CronJobResource struct {
-	node Node
+	node    Node
+	PodSpec core.PodSpec // Spec of the pod template, used to build the edges.
 }
 
 // CronJobResourceBuilder ...
-func CronJobResourceBuilder(c *v1.CronJob) *CronJobResource {
-	node := transformCommon(c) // Start off with the common properties
+func CronJobResourceBuilder(c *v1.CronJob, opts Options) *CronJobResource {
+	node := transformCommon(c, opts) // Start off with the common properties
 
 	apiGroupVersion(c.TypeMeta, &node) // add kind, apigroup and version
 	// Extract the properties specific to this type
@@ -38,7 +40,7 @@ func CronJobResourceBuilder(c *v1.CronJob) *CronJobResource {
 		node.Properties["suspend"] = *c.Spec.Suspend
 	}
 
-	return &CronJobResource{node: node}
+	return &CronJobResource{node: node, PodSpec: c.Spec.JobTemplate.Spec.Template.Spec}
 }
 
 // BuildNode construct the node for the Cronjob Resources
@@ -48,6 +50,5 @@ func (c CronJobResource) BuildNode() Node {
 
 // BuildEdges construct the edges for the Cronjob Resources
 func (c CronJobResource) BuildEdges(ns NodeStore) []Edge {
-	//no op for now to implement interface
-	return []Edge{}
+	return edgesByPodTemplate(c.node, c.PodSpec, ns)
 }
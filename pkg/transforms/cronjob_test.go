@@ -20,7 +20,7 @@ import (
 func TestTransformCronJob(t *testing.T) {
 	var c v1.CronJob
 	UnmarshalFile("cronjob.json", &c, t)
-	node := CronJobResourceBuilder(&c).BuildNode()
+	node := CronJobResourceBuilder(&c, testOptions).BuildNode()
 
 	// Build time struct matching time in test data
 	date := time.Date(2019, 3, 5, 23, 30, 0, 0, time.UTC)
@@ -41,8 +41,27 @@ func TestCronJobBuildEdges(t *testing.T) {
 	// Build edges from mock resource cronjob.json
 	var cron v1.CronJob
 	UnmarshalFile("cronjob.json", &cron, t)
-	edges := CronJobResourceBuilder(&cron).BuildEdges(nodeStore)
+	edges := CronJobResourceBuilder(&cron, testOptions).BuildEdges(nodeStore)
 
 	// Validate results
 	AssertEqual("CronJob has no edges:", len(edges), 0, t)
 }
+
+func TestCronJobBuildEdgesPodTemplate(t *testing.T) {
+	// Build a fake NodeStore with nodes needed to generate edges.
+	nodes := []Node{{
+		UID:        "uuid-123-secret",
+		Properties: map[string]interface{}{"kind": "Secret", "namespace": "default", "name": "fake-certs"},
+	}}
+	nodeStore := BuildFakeNodeStore(nodes)
+
+	// Build edges from mock resource cronjob.json. A suspended CronJob still shows its dependencies.
+	var cron v1.CronJob
+	UnmarshalFile("cronjob.json", &cron, t)
+	suspend := true
+	cron.Spec.Suspend = &suspend
+	edges := CronJobResourceBuilder(&cron, testOptions).BuildEdges(nodeStore)
+
+	AssertEqual("CronJob edge total:", len(edges), 1, t)
+	AssertEqual("CronJob attachedTo", edges[0].DestUID, "uuid-123-secret", t)
+}
@@ -12,16 +12,18 @@ package transforms
 
 import (
 	v1 "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
 )
 
 // DaemonSetResource ...
 type DaemonSetResource struct {
-	node Node
+	node    Node
+	PodSpec core.PodSpec // Spec of the pod template, used to build the edges.
 }
 
 // DaemonSetResourceBuilder ...
-func DaemonSetResourceBuilder(d *v1.DaemonSet) *DaemonSetResource {
-	node := transformCommon(d)         // Start off with the common properties
+func DaemonSetResourceBuilder(d *v1.DaemonSet, opts Options) *DaemonSetResource {
+	node := transformCommon(d, opts)   // Start off with the common properties
 	apiGroupVersion(d.TypeMeta, &node) // add kind, apigroup and version
 	// Extract the properties specific to this type
 	node.Properties["available"] = int64(d.Status.NumberAvailable)
@@ -30,7 +32,7 @@ func DaemonSetResourceBuilder(d *v1.DaemonSet) *DaemonSetResource {
 	node.Properties["ready"] = int64(d.Status.NumberReady)
 	node.Properties["updated"] = int64(d.Status.UpdatedNumberScheduled)
 
-	return &DaemonSetResource{node: node}
+	return &DaemonSetResource{node: node, PodSpec: d.Spec.Template.Spec}
 }
 
 // BuildNode construct the node for the Daemonset Resources
@@ -40,6 +42,5 @@ func (d DaemonSetResource) BuildNode() Node {
 
 // BuildEdges construct the edges for the Daemonset Resources
 func (d DaemonSetResource) BuildEdges(ns NodeStore) []Edge {
-	//no op for now to implement interface
-	return []Edge{}
+	return edgesByPodTemplate(d.node, d.PodSpec, ns)
 }
@@ -12,16 +12,18 @@ package transforms
 
 import (
 	v1 "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
 )
 
 // DeploymentResource ...
 type DeploymentResource struct {
-	node Node
+	node    Node
+	PodSpec core.PodSpec // Spec of the pod template, used to build the edges.
 }
 
 // DeploymentResourceBuilder ...
-func DeploymentResourceBuilder(d *v1.Deployment) *DeploymentResource {
-	node := transformCommon(d)         // Start off with the common properties
+func DeploymentResourceBuilder(d *v1.Deployment, opts Options) *DeploymentResource {
+	node := transformCommon(d, opts)   // Start off with the common properties
 	apiGroupVersion(d.TypeMeta, &node) // add kind, apigroup and version
 	// Extract the properties specific to this type
 	node.Properties["available"] = int64(d.Status.AvailableReplicas)
@@ -32,7 +34,7 @@ func DeploymentResourceBuilder(d *v1.Deployment) *DeploymentResource {
 		node.Properties["desired"] = int64(*d.Spec.Replicas)
 	}
 
-	return &DeploymentResource{node: node}
+	return &DeploymentResource{node: node, PodSpec: d.Spec.Template.Spec}
 }
 
 // BuildNode construct the node for the Deployment Resources
@@ -42,6 +44,5 @@ func (d DeploymentResource) BuildNode() Node {
 
 // BuildEdges construct the edges for the Deployment Resources
 func (d DeploymentResource) BuildEdges(ns NodeStore) []Edge {
-	//no op for now to implement interface
-	return []Edge{}
+	return edgesByPodTemplate(d.node, d.PodSpec, ns)
 }
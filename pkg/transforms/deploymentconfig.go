@@ -7,16 +7,18 @@ package transforms
 
 import (
 	v1 "github.com/openshift/api/apps/v1"
+	core "k8s.io/api/core/v1"
 )
 
 // DeploymentConfigResource ...
 type DeploymentConfigResource struct {
-	node Node
+	node    Node
+	PodSpec core.PodSpec // Spec of the pod template, used to build the edges.
 }
 
 // DeploymentConfigResourceBuilder ...
-func DeploymentConfigResourceBuilder(d *v1.DeploymentConfig) *DeploymentConfigResource {
-	node := transformCommon(d)         // Start off with the common properties
+func DeploymentConfigResourceBuilder(d *v1.DeploymentConfig, opts Options) *DeploymentConfigResource {
+	node := transformCommon(d, opts)   // Start off with the common properties
 	apiGroupVersion(d.TypeMeta, &node) // add kind, apigroup and version
 	// Extract the properties specific to this type
 	node.Properties["available"] = int64(d.Status.AvailableReplicas)
@@ -24,7 +26,11 @@ func DeploymentConfigResourceBuilder(d *v1.DeploymentConfig) *DeploymentConfigRe
 	node.Properties["ready"] = int64(d.Status.ReadyReplicas)
 	node.Properties["desired"] = int64(d.Spec.Replicas)
 
-	return &DeploymentConfigResource{node: node}
+	podSpec := core.PodSpec{}
+	if d.Spec.Template != nil {
+		podSpec = d.Spec.Template.Spec
+	}
+	return &DeploymentConfigResource{node: node, PodSpec: podSpec}
 }
 
 // BuildNode construct the node for the Deployment Resources
@@ -34,6 +40,5 @@ func (d DeploymentConfigResource) BuildNode() Node {
 
 // BuildEdges construct the edges for the Deployment Resources
 func (d DeploymentConfigResource) BuildEdges(ns NodeStore) []Edge {
-	//no op for now to implement interface
-	return []Edge{}
+	return edgesByPodTemplate(d.node, d.PodSpec, ns)
 }
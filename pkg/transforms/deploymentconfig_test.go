@@ -14,7 +14,7 @@ import (
 func TestTransformDeploymentConfig(t *testing.T) {
 	var d v1.DeploymentConfig
 	UnmarshalFile("deploymentconfig.json", &d, t)
-	node := DeploymentConfigResourceBuilder(&d).BuildNode()
+	node := DeploymentConfigResourceBuilder(&d, testOptions).BuildNode()
 
 	// Test only the fields that exist in deployment
 	AssertEqual("available", node.Properties["available"], int64(1), t)
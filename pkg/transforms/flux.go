@@ -0,0 +1,169 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	"time"
+
+	"github.com/golang/glog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Flux CD resources. The Kustomizations and HelmReleases deploy the artifacts fetched by the sources:
+// GitRepository, HelmRepository, OCIRepository and Bucket.
+
+// Prefix of the labels with the name and namespace of the Kustomization that applied a resource.
+const fluxKustomizationLabel = "kustomize.toolkit.fluxcd.io/"
+
+type FluxCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// FluxCrossNamespaceRef is the reference to a source, the namespace is the same as the referrer when not set.
+type FluxCrossNamespaceRef struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// Adds the properties shared by the Flux resources, from the Ready condition and Spec.Suspend.
+func addFluxStatusProperties(node *Node, suspend bool, conditions []FluxCondition) {
+	node.Properties["suspended"] = suspend
+	for _, condition := range conditions {
+		if condition.Type == "Ready" {
+			node.Properties["ready"] = condition.Status
+			if condition.Reason != "" {
+				node.Properties["readyReason"] = condition.Reason
+			}
+			if condition.Status != "True" && condition.Message != "" {
+				node.Properties["_conditionReady"] = TruncateText(condition.Message, 512)
+			}
+		}
+	}
+}
+
+// Returns the namespace/name of the Kustomization that applied the resource, from its labels.
+func hostingKustomization(labels map[string]string) string {
+	name, namespace := labels[fluxKustomizationLabel+"name"], labels[fluxKustomizationLabel+"namespace"]
+	if name == "" || namespace == "" {
+		return ""
+	}
+	return namespace + "/" + name
+}
+
+// Builds the edge to the source of a Kustomization or HelmRelease.
+func fluxSourceEdge(node Node, source FluxCrossNamespaceRef, ns NodeStore) []Edge {
+	namespace := node.Properties["namespace"].(string)
+	if source.Namespace != "" {
+		namespace = source.Namespace
+	}
+	if dest, ok := ns.ByKindNamespaceName[source.Kind][namespace][source.Name]; ok {
+		return []Edge{{
+			SourceUID:  node.UID,
+			DestUID:    dest.UID,
+			EdgeType:   "uses",
+			SourceKind: node.Properties["kind"].(string),
+			DestKind:   source.Kind,
+		}}
+	}
+	glog.V(4).Infof("For %s %s/%s, uses edge not created as %s named %s/%s not found", node.Properties["kind"],
+		node.Properties["namespace"], node.Properties["name"], source.Kind, namespace, source.Name)
+	return []Edge{}
+}
+
+// FluxSourceResource ...
+type FluxSourceResource struct {
+	node Node
+}
+
+// FluxSource has the fields of the GitRepository, HelmRepository, OCIRepository and Bucket kinds
+// that we collect.
+type FluxSource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              FluxSourceSpec   `json:"spec"`
+	Status            FluxSourceStatus `json:"status,omitempty"`
+}
+
+type FluxSourceSpec struct {
+	URL     string         `json:"url,omitempty"`
+	Ref     *FluxSourceRef `json:"ref,omitempty"`
+	Suspend bool           `json:"suspend,omitempty"`
+	// HelmRepository type, default or oci
+	Type string `json:"type,omitempty"`
+	// Bucket fields
+	BucketName string `json:"bucketName,omitempty"`
+	Endpoint   string `json:"endpoint,omitempty"`
+	Provider   string `json:"provider,omitempty"`
+}
+
+// FluxSourceRef is the reference to fetch from a GitRepository or OCIRepository.
+type FluxSourceRef struct {
+	Branch string `json:"branch,omitempty"`
+	Tag    string `json:"tag,omitempty"`
+	SemVer string `json:"semver,omitempty"`
+	Commit string `json:"commit,omitempty"`
+	Digest string `json:"digest,omitempty"`
+}
+
+type FluxSourceStatus struct {
+	Conditions []FluxCondition `json:"conditions,omitempty"`
+	Artifact   *FluxArtifact   `json:"artifact,omitempty"`
+}
+
+// FluxArtifact is the last artifact fetched from the source.
+type FluxArtifact struct {
+	Revision       string      `json:"revision"`
+	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+}
+
+// FluxSourceResourceBuilder ...
+func FluxSourceResourceBuilder(s *FluxSource, opts Options) *FluxSourceResource {
+	node := transformCommon(s, opts)   // Start off with the common properties
+	apiGroupVersion(s.TypeMeta, &node) // add kind, apigroup and version
+	// Extract the properties specific to this type
+	addFluxStatusProperties(&node, s.Spec.Suspend, s.Status.Conditions)
+	if s.Spec.URL != "" {
+		node.Properties["url"] = s.Spec.URL
+	}
+	if s.Spec.Ref != nil {
+		refs := map[string]string{"branch": s.Spec.Ref.Branch, "tag": s.Spec.Ref.Tag, "semver": s.Spec.Ref.SemVer,
+			"commit": s.Spec.Ref.Commit, "digest": s.Spec.Ref.Digest}
+		for property, value := range refs {
+			if value != "" {
+				node.Properties[property] = value
+			}
+		}
+	}
+	if s.Spec.Type != "" {
+		node.Properties["type"] = s.Spec.Type
+	}
+	if s.Spec.BucketName != "" {
+		node.Properties["bucketName"] = s.Spec.BucketName
+		node.Properties["endpoint"] = s.Spec.Endpoint
+		node.Properties["provider"] = "generic" // Default when not set.
+		if s.Spec.Provider != "" {
+			node.Properties["provider"] = s.Spec.Provider
+		}
+	}
+	if s.Status.Artifact != nil {
+		node.Properties["revision"] = s.Status.Artifact.Revision
+		node.Properties["lastUpdated"] = s.Status.Artifact.LastUpdateTime.UTC().Format(time.RFC3339)
+	}
+
+	return &FluxSourceResource{node: node}
+}
+
+// BuildNode construct the node for the Flux source Resources
+func (s FluxSourceResource) BuildNode() Node {
+	return s.node
+}
+
+// BuildEdges construct the edges for the Flux source Resources
+func (s FluxSourceResource) BuildEdges(ns NodeStore) []Edge {
+	//no op for now to implement interface
+	return []Edge{}
+}
@@ -0,0 +1,75 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	"testing"
+)
+
+func TestTransformFluxGitRepository(t *testing.T) {
+	var s FluxSource
+	UnmarshalFile("fluxgitrepository.json", &s, t)
+	node := FluxSourceResourceBuilder(&s, testOptions).BuildNode()
+
+	// Test only the fields that exist in the Flux sources - the common test will test the other bits
+	AssertEqual("kind", node.Properties["kind"], "GitRepository", t)
+	AssertEqual("apigroup", node.Properties["apigroup"], "source.toolkit.fluxcd.io", t)
+	AssertEqual("url", node.Properties["url"], "https://github.com/stefanprodan/podinfo", t)
+	AssertEqual("branch", node.Properties["branch"], "master", t)
+	AssertEqual("revision", node.Properties["revision"], "master@sha1:b3396adb98a6a0f5eeedd1a600beaf5e954a1f28", t)
+	AssertEqual("lastUpdated", node.Properties["lastUpdated"], "2024-01-15T10:21:02Z", t)
+	AssertEqual("ready", node.Properties["ready"], "True", t)
+	AssertEqual("readyReason", node.Properties["readyReason"], "Succeeded", t)
+	AssertEqual("suspended", node.Properties["suspended"], false, t)
+	// The message is only kept when the resource isn't ready.
+	AssertEqual("_conditionReady", node.Properties["_conditionReady"], nil, t)
+}
+
+func TestTransformFluxHelmRepository(t *testing.T) {
+	var s FluxSource
+	UnmarshalFile("fluxhelmrepository.json", &s, t)
+	node := FluxSourceResourceBuilder(&s, testOptions).BuildNode()
+
+	AssertEqual("kind", node.Properties["kind"], "HelmRepository", t)
+	AssertEqual("type", node.Properties["type"], "oci", t)
+	AssertEqual("url", node.Properties["url"], "oci://registry-1.docker.io/bitnamicharts", t)
+	AssertEqual("_hostingKustomization", node.Properties["_hostingKustomization"], "flux-system/podinfo", t)
+}
+
+func TestHostingKustomization(t *testing.T) {
+	AssertEqual("name and namespace", hostingKustomization(map[string]string{
+		"kustomize.toolkit.fluxcd.io/name": "apps", "kustomize.toolkit.fluxcd.io/namespace": "flux-system"}),
+		"flux-system/apps", t)
+	AssertEqual("without namespace", hostingKustomization(map[string]string{
+		"kustomize.toolkit.fluxcd.io/name": "apps"}), "", t)
+	AssertEqual("without labels", hostingKustomization(nil), "", t)
+}
+
+func TestFluxKustomizationDeployedByEdges(t *testing.T) {
+	// Build a fake NodeStore with a Deployment applied by the Kustomization and its Pod.
+	nodes := []Node{{
+		UID:        "uuid-123-kustomization",
+		Properties: map[string]interface{}{"kind": "Kustomization", "namespace": "flux-system", "name": "podinfo"},
+	}, {
+		UID: "uuid-123-deployment",
+		Properties: map[string]interface{}{"kind": "Deployment", "namespace": "podinfo", "name": "podinfo",
+			"_hostingKustomization": "flux-system/podinfo"},
+	}, {
+		UID:        "uuid-123-pod",
+		Properties: map[string]interface{}{"kind": "Pod", "namespace": "podinfo", "name": "podinfo-7d9f8"},
+		Metadata:   map[string]string{"OwnerUID": "uuid-123-deployment"},
+	}}
+	nodeStore := BuildFakeNodeStore(nodes)
+
+	edges := CommonEdges("uuid-123-deployment", nodeStore)
+	AssertEqual("Deployment edge total: ", len(edges), 1, t)
+	AssertEqual("Deployment deployedBy", edges[0].EdgeType, EdgeType("deployedBy"), t)
+	AssertEqual("Deployment deployedBy", edges[0].DestUID, "uuid-123-kustomization", t)
+
+	// The pod doesn't have the labels, the edge is found through its owner.
+	edges = CommonEdges("uuid-123-pod", nodeStore)
+	AssertEqual("Pod edge total: ", len(edges), 2, t)
+	AssertEqual("Pod ownedBy", edges[0].DestUID, "uuid-123-deployment", t)
+	AssertEqual("Pod deployedBy", edges[1].EdgeType, EdgeType("deployedBy"), t)
+	AssertEqual("Pod deployedBy", edges[1].DestUID, "uuid-123-kustomization", t)
+}
@@ -0,0 +1,103 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FluxHelmReleaseResource ...
+type FluxHelmReleaseResource struct {
+	node      Node
+	SourceRef FluxCrossNamespaceRef
+}
+
+// FluxHelmRelease is the HelmRelease of helm.toolkit.fluxcd.io. Not to be confused with the HelmRelease of
+// apps.open-cluster-management.io or the helm releases.
+type FluxHelmRelease struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              FluxHelmReleaseSpec   `json:"spec"`
+	Status            FluxHelmReleaseStatus `json:"status,omitempty"`
+}
+
+type FluxHelmReleaseSpec struct {
+	Chart *FluxHelmChartTemplate `json:"chart,omitempty"`
+	// ChartRef is used instead of Chart to refer to an OCIRepository or HelmChart
+	ChartRef        *FluxCrossNamespaceRef `json:"chartRef,omitempty"`
+	ReleaseName     string                 `json:"releaseName,omitempty"`
+	Suspend         bool                   `json:"suspend,omitempty"`
+	TargetNamespace string                 `json:"targetNamespace,omitempty"`
+}
+
+type FluxHelmChartTemplate struct {
+	Spec struct {
+		Chart     string                `json:"chart"`
+		Version   string                `json:"version,omitempty"`
+		SourceRef FluxCrossNamespaceRef `json:"sourceRef"`
+	} `json:"spec"`
+}
+
+type FluxHelmReleaseStatus struct {
+	Conditions            []FluxCondition `json:"conditions,omitempty"`
+	LastAppliedRevision   string          `json:"lastAppliedRevision,omitempty"`
+	LastAttemptedRevision string          `json:"lastAttemptedRevision,omitempty"`
+	// History replaces LastAppliedRevision in helm.toolkit.fluxcd.io/v2, the most recent release first.
+	History []struct {
+		ChartVersion string `json:"chartVersion"`
+	} `json:"history,omitempty"`
+}
+
+// FluxHelmReleaseResourceBuilder ...
+func FluxHelmReleaseResourceBuilder(h *FluxHelmRelease, opts Options) *FluxHelmReleaseResource {
+	node := transformCommon(h, opts)   // Start off with the common properties
+	apiGroupVersion(h.TypeMeta, &node) // add kind, apigroup and version
+	// Extract the properties specific to this type
+	addFluxStatusProperties(&node, h.Spec.Suspend, h.Status.Conditions)
+
+	sourceRef := FluxCrossNamespaceRef{}
+	if h.Spec.ChartRef != nil {
+		sourceRef = *h.Spec.ChartRef
+	} else if h.Spec.Chart != nil {
+		sourceRef = h.Spec.Chart.Spec.SourceRef
+		node.Properties["chart"] = h.Spec.Chart.Spec.Chart
+		if h.Spec.Chart.Spec.Version != "" {
+			node.Properties["chartVersion"] = h.Spec.Chart.Spec.Version
+		}
+	}
+	node.Properties["sourceRef"] = sourceRef.Kind + "/" + sourceRef.Name
+
+	if h.Spec.ReleaseName != "" {
+		node.Properties["releaseName"] = h.Spec.ReleaseName
+	}
+	if h.Spec.TargetNamespace != "" {
+		node.Properties["targetNamespace"] = h.Spec.TargetNamespace
+	}
+	lastApplied := h.Status.LastAppliedRevision
+	if lastApplied == "" && len(h.Status.History) > 0 {
+		lastApplied = h.Status.History[0].ChartVersion
+	}
+	if lastApplied != "" {
+		node.Properties["lastAppliedRevision"] = lastApplied
+	}
+	if h.Status.LastAttemptedRevision != "" {
+		node.Properties["lastAttemptedRevision"] = h.Status.LastAttemptedRevision
+	}
+
+	return &FluxHelmReleaseResource{node: node, SourceRef: sourceRef}
+}
+
+// BuildNode construct the node for the Flux HelmRelease Resources
+func (h FluxHelmReleaseResource) BuildNode() Node {
+	return h.node
+}
+
+// BuildEdges construct the edges for the Flux HelmRelease Resources
+// See documentation at pkg/transforms/README.md
+func (h FluxHelmReleaseResource) BuildEdges(ns NodeStore) []Edge {
+	if h.SourceRef.Name == "" {
+		return []Edge{}
+	}
+	// uses edge to the HelmRepository, GitRepository, Bucket, OCIRepository or HelmChart of the chart
+	return fluxSourceEdge(h.node, h.SourceRef, ns)
+}
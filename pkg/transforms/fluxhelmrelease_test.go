@@ -0,0 +1,56 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	"testing"
+)
+
+func TestTransformFluxHelmRelease(t *testing.T) {
+	var h FluxHelmRelease
+	UnmarshalFile("fluxhelmrelease.json", &h, t)
+	node := FluxHelmReleaseResourceBuilder(&h, testOptions).BuildNode()
+
+	// Test only the fields that exist in helmrelease - the common test will test the other bits
+	AssertEqual("kind", node.Properties["kind"], "HelmRelease", t)
+	AssertEqual("apigroup", node.Properties["apigroup"], "helm.toolkit.fluxcd.io", t)
+	AssertEqual("chart", node.Properties["chart"], "redis", t)
+	AssertEqual("chartVersion", node.Properties["chartVersion"], "18.x", t)
+	AssertEqual("sourceRef", node.Properties["sourceRef"], "HelmRepository/bitnami", t)
+	AssertEqual("releaseName", node.Properties["releaseName"], "podinfo-redis", t)
+	AssertEqual("suspended", node.Properties["suspended"], true, t)
+	AssertEqual("ready", node.Properties["ready"], "True", t)
+	// helm.toolkit.fluxcd.io/v2 has the applied chart version in the history.
+	AssertEqual("lastAppliedRevision", node.Properties["lastAppliedRevision"], "18.6.1", t)
+	AssertEqual("lastAttemptedRevision", node.Properties["lastAttemptedRevision"], "18.6.1", t)
+	AssertEqual("_hostingKustomization", node.Properties["_hostingKustomization"], "flux-system/podinfo", t)
+}
+
+func TestFluxHelmReleaseBuildEdges(t *testing.T) {
+	// Build a fake NodeStore with nodes needed to generate edges.
+	nodes := []Node{{
+		UID:        "uuid-123-helmrepository",
+		Properties: map[string]interface{}{"kind": "HelmRepository", "namespace": "flux-system", "name": "bitnami"},
+	}}
+	nodeStore := BuildFakeNodeStore(nodes)
+
+	var h FluxHelmRelease
+	UnmarshalFile("fluxhelmrelease.json", &h, t)
+	edges := FluxHelmReleaseResourceBuilder(&h, testOptions).BuildEdges(nodeStore)
+
+	// The source is in another namespace.
+	AssertEqual("HelmRelease edge total: ", len(edges), 1, t)
+	AssertEqual("HelmRelease uses", edges[0].EdgeType, EdgeType("uses"), t)
+	AssertEqual("HelmRelease uses", edges[0].DestUID, "uuid-123-helmrepository", t)
+
+	// HelmReleases with chartRef use the OCIRepository.
+	h.Spec.Chart = nil
+	h.Spec.ChartRef = &FluxCrossNamespaceRef{Kind: "OCIRepository", Name: "podinfo"}
+	nodeStore = BuildFakeNodeStore([]Node{{
+		UID:        "uuid-123-ocirepository",
+		Properties: map[string]interface{}{"kind": "OCIRepository", "namespace": "podinfo", "name": "podinfo"},
+	}})
+	edges = FluxHelmReleaseResourceBuilder(&h, testOptions).BuildEdges(nodeStore)
+	AssertEqual("HelmRelease edge total: ", len(edges), 1, t)
+	AssertEqual("HelmRelease uses", edges[0].DestUID, "uuid-123-ocirepository", t)
+}
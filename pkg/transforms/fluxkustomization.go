@@ -0,0 +1,70 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FluxKustomizationResource ...
+type FluxKustomizationResource struct {
+	node      Node
+	SourceRef FluxCrossNamespaceRef
+}
+
+// FluxKustomization is the Kustomization of kustomize.toolkit.fluxcd.io. Not to be confused with the
+// kustomization.yaml file of kustomize.
+type FluxKustomization struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              FluxKustomizationSpec   `json:"spec"`
+	Status            FluxKustomizationStatus `json:"status,omitempty"`
+}
+
+type FluxKustomizationSpec struct {
+	SourceRef       FluxCrossNamespaceRef `json:"sourceRef"`
+	Path            string                `json:"path,omitempty"`
+	Prune           bool                  `json:"prune"`
+	Suspend         bool                  `json:"suspend,omitempty"`
+	TargetNamespace string                `json:"targetNamespace,omitempty"`
+}
+
+type FluxKustomizationStatus struct {
+	Conditions            []FluxCondition `json:"conditions,omitempty"`
+	LastAppliedRevision   string          `json:"lastAppliedRevision,omitempty"`
+	LastAttemptedRevision string          `json:"lastAttemptedRevision,omitempty"`
+}
+
+// FluxKustomizationResourceBuilder ...
+func FluxKustomizationResourceBuilder(k *FluxKustomization, opts Options) *FluxKustomizationResource {
+	node := transformCommon(k, opts)   // Start off with the common properties
+	apiGroupVersion(k.TypeMeta, &node) // add kind, apigroup and version
+	// Extract the properties specific to this type
+	addFluxStatusProperties(&node, k.Spec.Suspend, k.Status.Conditions)
+	node.Properties["sourceRef"] = k.Spec.SourceRef.Kind + "/" + k.Spec.SourceRef.Name
+	node.Properties["path"] = k.Spec.Path
+	node.Properties["prune"] = k.Spec.Prune
+	if k.Spec.TargetNamespace != "" {
+		node.Properties["targetNamespace"] = k.Spec.TargetNamespace
+	}
+	if k.Status.LastAppliedRevision != "" {
+		node.Properties["lastAppliedRevision"] = k.Status.LastAppliedRevision
+	}
+	if k.Status.LastAttemptedRevision != "" {
+		node.Properties["lastAttemptedRevision"] = k.Status.LastAttemptedRevision
+	}
+
+	return &FluxKustomizationResource{node: node, SourceRef: k.Spec.SourceRef}
+}
+
+// BuildNode construct the node for the Flux Kustomization Resources
+func (k FluxKustomizationResource) BuildNode() Node {
+	return k.node
+}
+
+// BuildEdges construct the edges for the Flux Kustomization Resources
+// See documentation at pkg/transforms/README.md
+func (k FluxKustomizationResource) BuildEdges(ns NodeStore) []Edge {
+	// uses edge to the GitRepository, OCIRepository or Bucket in Spec.SourceRef
+	return fluxSourceEdge(k.node, k.SourceRef, ns)
+}
@@ -0,0 +1,48 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	"testing"
+)
+
+func TestTransformFluxKustomization(t *testing.T) {
+	var k FluxKustomization
+	UnmarshalFile("fluxkustomization.json", &k, t)
+	node := FluxKustomizationResourceBuilder(&k, testOptions).BuildNode()
+
+	// Test only the fields that exist in kustomization - the common test will test the other bits
+	AssertEqual("kind", node.Properties["kind"], "Kustomization", t)
+	AssertEqual("apigroup", node.Properties["apigroup"], "kustomize.toolkit.fluxcd.io", t)
+	AssertEqual("sourceRef", node.Properties["sourceRef"], "GitRepository/podinfo", t)
+	AssertEqual("path", node.Properties["path"], "./kustomize", t)
+	AssertEqual("prune", node.Properties["prune"], true, t)
+	AssertEqual("targetNamespace", node.Properties["targetNamespace"], "podinfo", t)
+	AssertEqual("suspended", node.Properties["suspended"], false, t)
+	AssertEqual("ready", node.Properties["ready"], "False", t)
+	AssertEqual("readyReason", node.Properties["readyReason"], "ReconciliationFailed", t)
+	AssertEqual("_conditionReady", node.Properties["_conditionReady"], "Deployment/podinfo/podinfo dry-run failed: "+
+		"failed to create typed patch object: .spec.replicas: expected numeric, got string", t)
+	AssertEqual("lastAppliedRevision", node.Properties["lastAppliedRevision"],
+		"master@sha1:0a7c2e1d4f6b8a9c3e5d7f1b2a4c6e8d0f1a3b5c", t)
+	AssertEqual("lastAttemptedRevision", node.Properties["lastAttemptedRevision"],
+		"master@sha1:b3396adb98a6a0f5eeedd1a600beaf5e954a1f28", t)
+}
+
+func TestFluxKustomizationBuildEdges(t *testing.T) {
+	// Build a fake NodeStore with nodes needed to generate edges.
+	nodes := []Node{{
+		UID:        "uuid-123-gitrepository",
+		Properties: map[string]interface{}{"kind": "GitRepository", "namespace": "flux-system", "name": "podinfo"},
+	}}
+	nodeStore := BuildFakeNodeStore(nodes)
+
+	var k FluxKustomization
+	UnmarshalFile("fluxkustomization.json", &k, t)
+	edges := FluxKustomizationResourceBuilder(&k, testOptions).BuildEdges(nodeStore)
+
+	AssertEqual("Kustomization edge total: ", len(edges), 1, t)
+	AssertEqual("Kustomization uses", edges[0].EdgeType, EdgeType("uses"), t)
+	AssertEqual("Kustomization uses", edges[0].DestUID, "uuid-123-gitrepository", t)
+	AssertEqual("Kustomization uses", edges[0].DestKind, "GitRepository", t)
+}
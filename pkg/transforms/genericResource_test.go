@@ -3,6 +3,7 @@
 package transforms
 
 import (
+	"sync/atomic"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -11,7 +12,7 @@ import (
 func Test_genericResourceFromConfig(t *testing.T) {
 	var r unstructured.Unstructured
 	UnmarshalFile("clusterserviceversion.json", &r, t)
-	node := GenericResourceBuilder(&r).BuildNode()
+	node := GenericResourceBuilder(&r, testOptions).BuildNode()
 
 	// Verify common properties
 	AssertEqual("name", node.Properties["name"], "advanced-cluster-management.v2.9.0", t)
@@ -29,7 +30,7 @@ func Test_genericResourceFromConfig(t *testing.T) {
 func Test_genericResourceFromConfigVM(t *testing.T) {
 	var r unstructured.Unstructured
 	UnmarshalFile("virtualmachine.json", &r, t)
-	node := GenericResourceBuilder(&r).BuildNode()
+	node := GenericResourceBuilder(&r, testOptions).BuildNode()
 
 	// Verify common properties
 	AssertEqual("name", node.Properties["name"], "rhel9-gitops", t)
@@ -42,3 +43,21 @@ func Test_genericResourceFromConfigVM(t *testing.T) {
 	AssertEqual("phase", node.Properties["ready"], "True", t)
 
 }
+
+func Test_genericResourceReducedProperties(t *testing.T) {
+	var r unstructured.Unstructured
+	UnmarshalFile("clusterserviceversion.json", &r, t)
+	opts := testOptions
+	opts.ReducedProperties = &atomic.Bool{}
+	opts.ReducedProperties.Store(true)
+	node := GenericResourceBuilder(&r, opts).BuildNode()
+
+	// Verify common properties are still extracted
+	AssertEqual("name", node.Properties["name"], "advanced-cluster-management.v2.9.0", t)
+	AssertEqual("kind", node.Properties["kind"], "ClusterServiceVersion", t)
+
+	// Verify properties defined in the transform config are skipped
+	if _, ok := node.Properties["display"]; ok {
+		t.Error("Expected display property to be skipped with reduced properties")
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/stolostron/search-collector/pkg/config"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/util/jsonpath"
 	"k8s.io/klog/v2"
@@ -22,18 +21,18 @@ type GenericResource struct {
 // Builds a GenericResource node.
 // Extract default properties from unstructured resource.
 // Supports extracting additional properties defined by the transform config.
-func GenericResourceBuilder(r *unstructured.Unstructured) *GenericResource {
+func GenericResourceBuilder(r *unstructured.Unstructured, opts Options) *GenericResource {
 	n := Node{
-		UID:        prefixedUID(r.GetUID()),
-		Properties: genericProperties(r),
-		Metadata:   genericMetadata(r),
+		UID:        prefixedUID(r.GetUID(), opts),
+		Properties: genericProperties(r, opts),
+		Metadata:   genericMetadata(r, opts),
 	}
 
 	// Check if a transform config exists for this resource and extract the additional properties.
 	group := r.GroupVersionKind().Group
 	kind := r.GetKind()
 	transformConfig, found := getTransformConfig(group, kind)
-	if found {
+	if found && !opts.reducedProperties() {
 		for _, prop := range transformConfig.properties {
 			jp := jsonpath.New(prop.name)
 			parseErr := jp.Parse(prop.jsonpath)
@@ -76,13 +75,13 @@ func (r GenericResource) BuildEdges(ns NodeStore) []Edge {
 
 // TODO: Consolidate with commonProperties() in common.go
 // Extracts the common properties from any k8s resource and returns them in a map ready to be put in an Node
-func genericProperties(r *unstructured.Unstructured) map[string]interface{} {
+func genericProperties(r *unstructured.Unstructured, opts Options) map[string]interface{} {
 	ret := make(map[string]interface{})
 
 	ret["kind"] = r.GetKind()
 	ret["name"] = r.GetName()
 	ret["created"] = r.GetCreationTimestamp().UTC().Format(time.RFC3339)
-	if config.Cfg.DeployedInHub {
+	if opts.DeployedInHub {
 		ret["_hubClusterResource"] = true
 	}
 
@@ -108,13 +107,16 @@ func genericProperties(r *unstructured.Unstructured) map[string]interface{} {
 	if r.GetAnnotations()["apps.open-cluster-management.io/hosting-deployable"] != "" {
 		ret["_hostingDeployable"] = r.GetAnnotations()["apps.open-cluster-management.io/hosting-deployable"]
 	}
+	if kustomization := hostingKustomization(r.GetLabels()); kustomization != "" {
+		ret["_hostingKustomization"] = kustomization
+	}
 	return ret
 
 }
 
-func genericMetadata(r *unstructured.Unstructured) map[string]string {
+func genericMetadata(r *unstructured.Unstructured, opts Options) map[string]string {
 	metadata := make(map[string]string)
-	metadata["OwnerUID"] = ownerRefUID(r.GetOwnerReferences())
+	metadata["OwnerUID"] = ownerRefUID(r.GetOwnerReferences(), opts)
 	// Adds OwnerReleaseName and Namespace to resources that don't have ownerRef, but are deployed by a release.
 	if metadata["OwnerUID"] == "" && r.GetAnnotations()["meta.helm.sh/release-name"] != "" &&
 		r.GetAnnotations()["meta.helm.sh/release-namespace"] != "" {
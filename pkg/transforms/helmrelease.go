@@ -16,7 +16,6 @@ import (
 	"time"
 
 	"github.com/golang/glog"
-	"github.com/stolostron/search-collector/pkg/config"
 	"gopkg.in/yaml.v2"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/helm/pkg/proto/hapi/release"
@@ -25,10 +24,11 @@ import (
 type HelmReleaseResource struct {
 	*v1.ConfigMap    // ConfigMap Resource created by Tiller that contains Release info
 	*release.Release // Release from Tiller
+	Options          Options
 }
 
-func GetHelmReleaseUID(releaseName string) string {
-	return config.Cfg.ClusterName + "/Release/" + releaseName
+func GetHelmReleaseUID(releaseName string, opts Options) string {
+	return opts.ClusterName + "/Release/" + releaseName
 }
 
 func (h HelmReleaseResource) BuildNode() Node {
@@ -36,7 +36,7 @@ func (h HelmReleaseResource) BuildNode() Node {
 	releaseName := releaseLabels["NAME"]
 
 	node := Node{
-		UID:        GetHelmReleaseUID(releaseName),
+		UID:        GetHelmReleaseUID(releaseName, h.Options),
 		Properties: make(map[string]interface{}),
 		Metadata:   make(map[string]string),
 	}
@@ -51,7 +51,7 @@ func (h HelmReleaseResource) BuildNode() Node {
 		node.Properties["revision"] = revision
 	}
 
-	if config.Cfg.DeployedInHub {
+	if h.Options.DeployedInHub {
 		node.Properties["_hubClusterResource"] = true
 	}
 
@@ -116,7 +116,7 @@ func (h HelmReleaseResource) BuildEdges(ns NodeStore) []Edge {
 
 	smr := getSummarizedManifestResources(h)
 
-	UID := GetHelmReleaseUID(h.GetLabels()["NAME"])
+	UID := GetHelmReleaseUID(h.GetLabels()["NAME"], h.Options)
 	edges := []Edge{}
 	helmNode := ns.ByUID[UID]
 
@@ -126,12 +126,7 @@ func (h HelmReleaseResource) BuildEdges(ns NodeStore) []Edge {
 		kind := resource.Kind
 		name := resource.Metadata.Name
 
-		//Obtain Read Lock before checking the map
-		NonNSResMapMutex.RLock()
-		_, notNameSpaced := NonNSResourceMap[kind]
-		NonNSResMapMutex.RUnlock()
-
-		if notNameSpaced {
+		if isNonNamespaced(h.Options.ClusterName, kind) {
 			// These are non-namespaced resources. So check in namespace "_NONE"
 			namespace = "_NONE"
 		}
@@ -140,9 +135,9 @@ func (h HelmReleaseResource) BuildEdges(ns NodeStore) []Edge {
 		if resourceNode, ok := ns.ByKindNamespaceName[kind][namespace][name]; ok {
 			if resourceNode.Metadata != nil { // Metadata can be nil if no node found
 				// update node metadata to include release for upstream edge from resource to Release
-				resourceNode.Metadata["ReleaseUID"] = GetHelmReleaseUID(h.GetLabels()["NAME"])
+				resourceNode.Metadata["ReleaseUID"] = GetHelmReleaseUID(h.GetLabels()["NAME"], h.Options)
 			}
-			if GetHelmReleaseUID(h.GetLabels()["NAME"]) != "" {
+			if GetHelmReleaseUID(h.GetLabels()["NAME"], h.Options) != "" {
 				// Add hosting Subscription/Deployable properties to the resource so that they can tracked
 				if helmNode.Properties["_hostingSubscription"] != "" || helmNode.Properties["_hostingDeployable"] != "" {
 					resourceNode := ns.ByUID[resourceNode.UID]
@@ -152,10 +147,10 @@ func (h HelmReleaseResource) BuildEdges(ns NodeStore) []Edge {
 						copyhostingSubProperties(UID, resourceNode.UID, ns)
 					}
 				}
-				if resourceNode.UID != GetHelmReleaseUID(h.GetLabels()["NAME"]) { //avoid connecting node to itself
+				if resourceNode.UID != GetHelmReleaseUID(h.GetLabels()["NAME"], h.Options) { //avoid connecting node to itself
 					edges = append(edges, Edge{
 						SourceUID:  resourceNode.UID,
-						DestUID:    GetHelmReleaseUID(h.GetLabels()["NAME"]),
+						DestUID:    GetHelmReleaseUID(h.GetLabels()["NAME"], h.Options),
 						EdgeType:   "ownedBy",
 						SourceKind: resourceNode.Properties["kind"].(string),
 						DestKind:   "Release",
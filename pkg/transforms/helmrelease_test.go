@@ -24,7 +24,7 @@ func TestTransformHelmRelease(t *testing.T) {
 	UnmarshalFile("../../test-data/helmrelease-configmap.json", &c, t)
 	UnmarshalFile("../../test-data/helmrelease-release.json", &r, t)
 
-	node := HelmReleaseResource{&c, &r}.BuildNode()
+	node := HelmReleaseResource{&c, &r, testOptions}.BuildNode()
 
 	// Test only the fields that exist in HelmRelease - the common test will test the other bits
 	AssertEqual("name", node.Properties["name"], "helmrelease-ex", t)
@@ -0,0 +1,113 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	autoscaling "k8s.io/api/autoscaling/v2"
+)
+
+// HorizontalPodAutoscalerResource ...
+type HorizontalPodAutoscalerResource struct {
+	node           Node
+	ScaleTargetRef autoscaling.CrossVersionObjectReference
+}
+
+// HorizontalPodAutoscalerResourceBuilder ...
+func HorizontalPodAutoscalerResourceBuilder(h *autoscaling.HorizontalPodAutoscaler,
+	opts Options) *HorizontalPodAutoscalerResource {
+	node := transformCommon(h, opts)   // Start off with the common properties
+	apiGroupVersion(h.TypeMeta, &node) // add kind, apigroup and version
+	// Extract the properties specific to this type
+	minReplicas := int64(1) // Default when not set.
+	if h.Spec.MinReplicas != nil {
+		minReplicas = int64(*h.Spec.MinReplicas)
+	}
+	node.Properties["minReplicas"] = minReplicas
+	node.Properties["maxReplicas"] = int64(h.Spec.MaxReplicas)
+	node.Properties["currentReplicas"] = int64(h.Status.CurrentReplicas)
+	node.Properties["desiredReplicas"] = int64(h.Status.DesiredReplicas)
+	node.Properties["scaleTarget"] = h.Spec.ScaleTargetRef.Kind + "/" + h.Spec.ScaleTargetRef.Name
+
+	metrics := make([]string, 0, len(h.Spec.Metrics))
+	for _, m := range h.Spec.Metrics {
+		if metric := formatMetricSpec(m); metric != "" {
+			metrics = append(metrics, metric)
+		}
+	}
+	if len(metrics) > 0 {
+		node.Properties["metric"] = metrics
+	}
+
+	return &HorizontalPodAutoscalerResource{node: node, ScaleTargetRef: h.Spec.ScaleTargetRef}
+}
+
+// Formats a metric target as name=target, for example cpu=80% or packets-per-second=1k.
+// Container resource metrics are prefixed with the container name, for example app/cpu=80%.
+func formatMetricSpec(m autoscaling.MetricSpec) string {
+	switch m.Type {
+	case autoscaling.ResourceMetricSourceType:
+		if m.Resource != nil {
+			return string(m.Resource.Name) + "=" + formatMetricTarget(m.Resource.Target)
+		}
+	case autoscaling.ContainerResourceMetricSourceType:
+		if m.ContainerResource != nil {
+			return m.ContainerResource.Container + "/" + string(m.ContainerResource.Name) + "=" +
+				formatMetricTarget(m.ContainerResource.Target)
+		}
+	case autoscaling.PodsMetricSourceType:
+		if m.Pods != nil {
+			return m.Pods.Metric.Name + "=" + formatMetricTarget(m.Pods.Target)
+		}
+	case autoscaling.ObjectMetricSourceType:
+		if m.Object != nil {
+			return m.Object.Metric.Name + "=" + formatMetricTarget(m.Object.Target)
+		}
+	case autoscaling.ExternalMetricSourceType:
+		if m.External != nil {
+			return m.External.Metric.Name + "=" + formatMetricTarget(m.External.Target)
+		}
+	}
+	glog.V(4).Infof("Ignoring HorizontalPodAutoscaler metric of type %s", m.Type)
+	return ""
+}
+
+func formatMetricTarget(t autoscaling.MetricTarget) string {
+	switch {
+	case t.AverageUtilization != nil:
+		return fmt.Sprintf("%d%%", *t.AverageUtilization)
+	case t.AverageValue != nil:
+		return t.AverageValue.String()
+	case t.Value != nil:
+		return t.Value.String()
+	}
+	return ""
+}
+
+// BuildNode construct the node for the HorizontalPodAutoscaler Resources
+func (h HorizontalPodAutoscalerResource) BuildNode() Node {
+	return h.node
+}
+
+// BuildEdges construct the edges for the HorizontalPodAutoscaler Resources
+func (h HorizontalPodAutoscalerResource) BuildEdges(ns NodeStore) []Edge {
+	ret := []Edge{}
+	namespace := h.node.Properties["namespace"].(string)
+
+	// scales edge to the workload in Spec.ScaleTargetRef.
+	if dest, ok := ns.ByKindNamespaceName[h.ScaleTargetRef.Kind][namespace][h.ScaleTargetRef.Name]; ok {
+		ret = append(ret, Edge{
+			SourceUID:  h.node.UID,
+			DestUID:    dest.UID,
+			EdgeType:   "scales",
+			SourceKind: h.node.Properties["kind"].(string),
+			DestKind:   h.ScaleTargetRef.Kind,
+		})
+	} else {
+		glog.V(4).Infof("For HorizontalPodAutoscaler %s/%s, scales edge not created as %s named %s not found",
+			namespace, h.node.Properties["name"], h.ScaleTargetRef.Kind, h.ScaleTargetRef.Name)
+	}
+	return ret
+}
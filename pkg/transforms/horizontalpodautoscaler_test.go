@@ -0,0 +1,42 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	"testing"
+
+	v2 "k8s.io/api/autoscaling/v2"
+)
+
+func TestTransformHorizontalPodAutoscaler(t *testing.T) {
+	var h v2.HorizontalPodAutoscaler
+	UnmarshalFile("horizontalpodautoscaler.json", &h, t)
+	node := HorizontalPodAutoscalerResourceBuilder(&h, testOptions).BuildNode()
+
+	// Test only the fields that exist in horizontalpodautoscaler - the common test will test the other bits
+	AssertEqual("minReplicas", node.Properties["minReplicas"], int64(2), t)
+	AssertEqual("maxReplicas", node.Properties["maxReplicas"], int64(10), t)
+	AssertEqual("currentReplicas", node.Properties["currentReplicas"], int64(10), t)
+	AssertEqual("desiredReplicas", node.Properties["desiredReplicas"], int64(10), t)
+	AssertEqual("scaleTarget", node.Properties["scaleTarget"], "Deployment/fake-deployment", t)
+	AssertDeepEqual("metric", node.Properties["metric"], []string{"cpu=80%", "packets-per-second=1k"}, t)
+}
+
+func TestHorizontalPodAutoscalerBuildEdges(t *testing.T) {
+	// Build a fake NodeStore with nodes needed to generate edges.
+	nodes := []Node{{
+		UID:        "uuid-123-deployment",
+		Properties: map[string]interface{}{"kind": "Deployment", "namespace": "default", "name": "fake-deployment"},
+	}}
+	nodeStore := BuildFakeNodeStore(nodes)
+
+	// Build edges from mock resource horizontalpodautoscaler.json
+	var h v2.HorizontalPodAutoscaler
+	UnmarshalFile("horizontalpodautoscaler.json", &h, t)
+	edges := HorizontalPodAutoscalerResourceBuilder(&h, testOptions).BuildEdges(nodeStore)
+
+	AssertEqual("HorizontalPodAutoscaler edge total: ", len(edges), 1, t)
+	AssertEqual("HorizontalPodAutoscaler scales", edges[0].EdgeType, EdgeType("scales"), t)
+	AssertEqual("HorizontalPodAutoscaler scales", edges[0].DestUID, "uuid-123-deployment", t)
+	AssertEqual("HorizontalPodAutoscaler scales", edges[0].DestKind, "Deployment", t)
+}
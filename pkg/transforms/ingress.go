@@ -0,0 +1,121 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	"strconv"
+
+	networking "k8s.io/api/networking/v1"
+)
+
+// IngressResource ...
+type IngressResource struct {
+	node Node
+	Spec networking.IngressSpec
+}
+
+// IngressResourceBuilder ...
+func IngressResourceBuilder(i *networking.Ingress, opts Options) *IngressResource {
+	node := transformCommon(i, opts)   // Start off with the common properties
+	apiGroupVersion(i.TypeMeta, &node) // add kind, apigroup and version
+	// Extract the properties specific to this type
+	if i.Spec.IngressClassName != nil {
+		node.Properties["ingressClassName"] = *i.Spec.IngressClassName
+	} else if class, ok := i.GetAnnotations()["kubernetes.io/ingress.class"]; ok {
+		node.Properties["ingressClassName"] = class // Deprecated annotation, still used by older ingresses.
+	}
+
+	hosts := []string{}
+	backends := []string{}
+	if i.Spec.DefaultBackend != nil {
+		backends = appendIngressBackend(backends, *i.Spec.DefaultBackend)
+	}
+	for _, rule := range i.Spec.Rules {
+		if rule.Host != "" {
+			hosts = append(hosts, rule.Host)
+		}
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			backends = appendIngressBackend(backends, path.Backend)
+		}
+	}
+	if len(hosts) > 0 {
+		node.Properties["host"] = hosts
+	}
+	if len(backends) > 0 {
+		node.Properties["backend"] = backends
+	}
+
+	tlsSecrets := []string{}
+	for _, tls := range i.Spec.TLS {
+		if tls.SecretName != "" {
+			tlsSecrets = append(tlsSecrets, tls.SecretName)
+		}
+	}
+	if len(tlsSecrets) > 0 {
+		node.Properties["tlsSecret"] = tlsSecrets
+	}
+	return &IngressResource{node: node, Spec: i.Spec}
+}
+
+// Adds the backend as service:port, or as the kind/name of a resource backend.
+func appendIngressBackend(backends []string, backend networking.IngressBackend) []string {
+	if backend.Service != nil {
+		port := backend.Service.Port.Name
+		if port == "" {
+			port = strconv.Itoa(int(backend.Service.Port.Number))
+		}
+		return append(backends, backend.Service.Name+":"+port)
+	}
+	if backend.Resource != nil {
+		return append(backends, backend.Resource.Kind+"/"+backend.Resource.Name)
+	}
+	return backends
+}
+
+// BuildNode construct the node for the Ingress Resources
+func (i IngressResource) BuildNode() Node {
+	return i.node
+}
+
+// BuildEdges construct the edges for the Ingress Resources
+func (i IngressResource) BuildEdges(ns NodeStore) []Edge {
+	ret := []Edge{}
+	nodeInfo := NodeInfo{
+		Name:      i.node.Properties["name"].(string),
+		NameSpace: i.node.Properties["namespace"].(string),
+		UID:       i.node.UID,
+		EdgeType:  "routesTo",
+		Kind:      i.node.Properties["kind"].(string)}
+
+	// routesTo edges
+	services := make(map[string]struct{})
+	if i.Spec.DefaultBackend != nil && i.Spec.DefaultBackend.Service != nil {
+		services[i.Spec.DefaultBackend.Service.Name] = struct{}{}
+	}
+	for _, rule := range i.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service != nil {
+				services[path.Backend.Service.Name] = struct{}{}
+			}
+		}
+	}
+	ret = append(ret, edgesByDestinationName(services, "Service", nodeInfo, ns, []string{})...)
+
+	// uses edges
+	nodeInfo.EdgeType = "uses"
+	secrets := make(map[string]struct{})
+	for _, tls := range i.Spec.TLS {
+		if tls.SecretName != "" {
+			secrets[tls.SecretName] = struct{}{}
+		}
+	}
+	ret = append(ret, edgesByDestinationName(secrets, "Secret", nodeInfo, ns, []string{})...)
+
+	return ret
+}
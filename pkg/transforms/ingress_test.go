@@ -0,0 +1,45 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/networking/v1"
+)
+
+func TestTransformIngress(t *testing.T) {
+	var i v1.Ingress
+	UnmarshalFile("ingress.json", &i, t)
+	node := IngressResourceBuilder(&i, testOptions).BuildNode()
+
+	// Test only the fields that exist in ingress - the common test will test the other bits
+	AssertEqual("ingressClassName", node.Properties["ingressClassName"], "nginx", t)
+	AssertDeepEqual("host", node.Properties["host"], []string{"app.example.com"}, t)
+	AssertDeepEqual("backend", node.Properties["backend"], []string{"test-default-service:8080", "test-service:http"}, t)
+	AssertDeepEqual("tlsSecret", node.Properties["tlsSecret"], []string{"test-tls-secret"}, t)
+}
+
+func TestIngressBuildEdges(t *testing.T) {
+	// Build a fake NodeStore with nodes needed to generate edges.
+	nodes := []Node{{
+		UID:        "uuid-123-service",
+		Properties: map[string]interface{}{"kind": "Service", "namespace": "default", "name": "test-service"},
+	}, {
+		UID:        "uuid-123-secret",
+		Properties: map[string]interface{}{"kind": "Secret", "namespace": "default", "name": "test-tls-secret"},
+	}}
+	nodeStore := BuildFakeNodeStore(nodes)
+
+	// Build edges from mock resource ingress.json
+	var i v1.Ingress
+	UnmarshalFile("ingress.json", &i, t)
+	edges := IngressResourceBuilder(&i, testOptions).BuildEdges(nodeStore)
+
+	// Verify created edges.
+	AssertEqual("Ingress edge total: ", len(edges), 2, t)
+	AssertEqual("Ingress routesTo", edges[0].EdgeType, EdgeType("routesTo"), t)
+	AssertEqual("Ingress routesTo", edges[0].DestUID, "uuid-123-service", t)
+	AssertEqual("Ingress uses", edges[1].EdgeType, EdgeType("uses"), t)
+	AssertEqual("Ingress uses", edges[1].DestUID, "uuid-123-secret", t)
+}
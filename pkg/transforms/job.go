@@ -12,16 +12,18 @@ package transforms
 
 import (
 	v1 "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
 )
 
 // JobResource ...
 type JobResource struct {
-	node Node
+	node    Node
+	PodSpec core.PodSpec // Spec of the pod template, used to build the edges.
 }
 
 // JobResourceBuilder ...
-func JobResourceBuilder(j *v1.Job) *JobResource {
-	node := transformCommon(j)         // Start off with the common properties
+func JobResourceBuilder(j *v1.Job, opts Options) *JobResource {
+	node := transformCommon(j, opts)   // Start off with the common properties
 	apiGroupVersion(j.TypeMeta, &node) // add kind, apigroup and version
 	// Extract the properties specific to this type
 	node.Properties["successful"] = int64(j.Status.Succeeded)
@@ -34,7 +36,7 @@ func JobResourceBuilder(j *v1.Job) *JobResource {
 		node.Properties["parallelism"] = int64(*j.Spec.Parallelism)
 	}
 
-	return &JobResource{node: node}
+	return &JobResource{node: node, PodSpec: j.Spec.Template.Spec}
 }
 
 // BuildNode construct node for Job resources
@@ -44,6 +46,5 @@ func (j JobResource) BuildNode() Node {
 
 // BuildEdges construct edges for Job resources
 func (j JobResource) BuildEdges(ns NodeStore) []Edge {
-	//no op for now to implement interface
-	return []Edge{}
+	return edgesByPodTemplate(j.node, j.PodSpec, ns)
 }
@@ -19,7 +19,7 @@ import (
 func TestTransformJob(t *testing.T) {
 	var j v1.Job
 	UnmarshalFile("job.json", &j, t)
-	node := JobResourceBuilder(&j).BuildNode()
+	node := JobResourceBuilder(&j, testOptions).BuildNode()
 
 	// Test only the fields that exist in job - the common test will test the other bits
 	AssertEqual("successful", node.Properties["successful"], int64(1), t)
@@ -35,7 +35,7 @@ func TestJobBuildEdges(t *testing.T) {
 	// Build edges from mock resource job.json
 	var j v1.Job
 	UnmarshalFile("job.json", &j, t)
-	edges := JobResourceBuilder(&j).BuildEdges(nodeStore)
+	edges := JobResourceBuilder(&j, testOptions).BuildEdges(nodeStore)
 
 	// Validate results
 	AssertEqual("Job has no edges:", len(edges), 0, t)
@@ -14,8 +14,8 @@ type KlusterletAddonConfigResource struct {
 }
 
 // KlusterletAddonConfigResourceBuilder ...
-func KlusterletAddonConfigResourceBuilder(p *agentv1.KlusterletAddonConfig) *KlusterletAddonConfigResource {
-	node := transformCommon(p)         // Start off with the common properties
+func KlusterletAddonConfigResourceBuilder(p *agentv1.KlusterletAddonConfig, opts Options) *KlusterletAddonConfigResource {
+	node := transformCommon(p, opts)   // Start off with the common properties
 	apiGroupVersion(p.TypeMeta, &node) // add kind, apigroup and version
 
 	// Extract the properties specific to this type
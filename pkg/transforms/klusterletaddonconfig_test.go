@@ -11,7 +11,7 @@ import (
 func TestTransformKlusterletAddonConfig(t *testing.T) {
 	var p agentv1.KlusterletAddonConfig
 	UnmarshalFile("klusterletaddonconfig.json", &p, t)
-	node := KlusterletAddonConfigResourceBuilder(&p).BuildNode()
+	node := KlusterletAddonConfigResourceBuilder(&p, testOptions).BuildNode()
 
 	enabledAddons := map[string]interface{}{
 		"search-collector":       true,
@@ -20,8 +20,8 @@ type NamespaceResource struct {
 }
 
 // NamespaceResourceBuilder ...
-func NamespaceResourceBuilder(n *v1.Namespace) *NamespaceResource {
-	node := transformCommon(n)         // Start off with the common properties
+func NamespaceResourceBuilder(n *v1.Namespace, opts Options) *NamespaceResource {
+	node := transformCommon(n, opts)   // Start off with the common properties
 	apiGroupVersion(n.TypeMeta, &node) // add kind, apigroup and version
 	// Extract the properties specific to this type
 	node.Properties["status"] = string(n.Status.Phase)
@@ -19,7 +19,7 @@ import (
 func TestTransformNamespace(t *testing.T) {
 	var n v1.Namespace
 	UnmarshalFile("namespace.json", &n, t)
-	node := NamespaceResourceBuilder(&n).BuildNode()
+	node := NamespaceResourceBuilder(&n, testOptions).BuildNode()
 
 	// Test only the fields that exist in namespace - the common test will test the other bits
 	AssertEqual("status", node.Properties["status"], "Active", t)
@@ -33,7 +33,7 @@ func TestNamespaceBuildEdges(t *testing.T) {
 	// Build edges from mock resource namespace.json
 	var ns v1.Namespace
 	UnmarshalFile("namespace.json", &ns, t)
-	edges := NamespaceResourceBuilder(&ns).BuildEdges(nodeStore)
+	edges := NamespaceResourceBuilder(&ns, testOptions).BuildEdges(nodeStore)
 
 	// Validate results
 	AssertEqual("Namespace has no edges:", len(edges), 0, t)
@@ -0,0 +1,67 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	"github.com/golang/glog"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NetworkPolicyResource ...
+type NetworkPolicyResource struct {
+	node Node
+	Spec networking.NetworkPolicySpec
+}
+
+// NetworkPolicyResourceBuilder ...
+func NetworkPolicyResourceBuilder(n *networking.NetworkPolicy, opts Options) *NetworkPolicyResource {
+	node := transformCommon(n, opts)   // Start off with the common properties
+	apiGroupVersion(n.TypeMeta, &node) // add kind, apigroup and version
+	// Extract the properties specific to this type
+	policyTypes := make([]string, 0, len(n.Spec.PolicyTypes))
+	for _, t := range n.Spec.PolicyTypes {
+		policyTypes = append(policyTypes, string(t))
+	}
+	if len(policyTypes) == 0 {
+		// When not set, Ingress always applies and Egress applies if the policy has egress rules.
+		policyTypes = append(policyTypes, string(networking.PolicyTypeIngress))
+		if len(n.Spec.Egress) > 0 {
+			policyTypes = append(policyTypes, string(networking.PolicyTypeEgress))
+		}
+	}
+	node.Properties["policyTypes"] = policyTypes
+	node.Properties["ingressRules"] = int64(len(n.Spec.Ingress))
+	node.Properties["egressRules"] = int64(len(n.Spec.Egress))
+	node.Properties["podSelector"] = metav1.FormatLabelSelector(&n.Spec.PodSelector)
+
+	return &NetworkPolicyResource{node: node, Spec: n.Spec}
+}
+
+// BuildNode construct the node for the NetworkPolicy Resources
+func (n NetworkPolicyResource) BuildNode() Node {
+	return n.node
+}
+
+// BuildEdges construct the edges for the NetworkPolicy Resources
+func (n NetworkPolicyResource) BuildEdges(ns NodeStore) []Edge {
+	ret := []Edge{}
+	selector, err := metav1.LabelSelectorAsSelector(&n.Spec.PodSelector)
+	if err != nil {
+		glog.V(2).Infof("Invalid podSelector in NetworkPolicy %s/%s. Not building appliesTo edges. %s",
+			n.node.Properties["namespace"], n.node.Properties["name"], err)
+		return ret
+	}
+
+	// appliesTo edges. An empty podSelector selects all the pods in the namespace.
+	for _, p := range podsBySelector(ns, n.node.Properties["namespace"].(string), selector) {
+		ret = append(ret, Edge{
+			SourceUID:  n.node.UID,
+			DestUID:    p.UID,
+			EdgeType:   "appliesTo",
+			SourceKind: n.node.Properties["kind"].(string),
+			DestKind:   "Pod",
+		})
+	}
+	return ret
+}
@@ -0,0 +1,64 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTransformNetworkPolicy(t *testing.T) {
+	var n v1.NetworkPolicy
+	UnmarshalFile("networkpolicy.json", &n, t)
+	node := NetworkPolicyResourceBuilder(&n, testOptions).BuildNode()
+
+	// Test only the fields that exist in networkpolicy - the common test will test the other bits
+	AssertDeepEqual("policyTypes", node.Properties["policyTypes"], []string{"Ingress", "Egress"}, t)
+	AssertEqual("ingressRules", node.Properties["ingressRules"], int64(2), t)
+	AssertEqual("egressRules", node.Properties["egressRules"], int64(1), t)
+	AssertEqual("podSelector", node.Properties["podSelector"], "app=test-fixture,tier in (backend,frontend)", t)
+}
+
+func TestNetworkPolicyDefaultPolicyTypes(t *testing.T) {
+	var n v1.NetworkPolicy
+	UnmarshalFile("networkpolicy.json", &n, t)
+	n.Spec.PolicyTypes = nil
+	node := NetworkPolicyResourceBuilder(&n, testOptions).BuildNode()
+
+	AssertDeepEqual("policyTypes", node.Properties["policyTypes"], []string{"Ingress", "Egress"}, t)
+}
+
+func TestNetworkPolicyBuildEdges(t *testing.T) {
+	// Build a fake NodeStore with nodes needed to generate edges.
+	nodes := []Node{{
+		UID: "uuid-123-pod",
+		Properties: map[string]interface{}{"kind": "Pod", "namespace": "default", "name": "test-pod",
+			"label": map[string]string{"app": "test-fixture", "tier": "frontend"}},
+	}}
+	nodeStore := BuildFakeNodeStore(nodes)
+
+	// Build edges from mock resource networkpolicy.json
+	var n v1.NetworkPolicy
+	UnmarshalFile("networkpolicy.json", &n, t)
+	edges := NetworkPolicyResourceBuilder(&n, testOptions).BuildEdges(nodeStore)
+
+	AssertEqual("NetworkPolicy edge total: ", len(edges), 1, t)
+	AssertEqual("NetworkPolicy appliesTo", edges[0].EdgeType, EdgeType("appliesTo"), t)
+	AssertEqual("NetworkPolicy appliesTo", edges[0].DestUID, "uuid-123-pod", t)
+
+	// A pod that doesn't match the matchExpressions.
+	nodes[0].Properties["label"] = map[string]string{"app": "test-fixture", "tier": "db"}
+	edges = NetworkPolicyResourceBuilder(&n, testOptions).BuildEdges(BuildFakeNodeStore(nodes))
+
+	AssertEqual("NetworkPolicy edge total: ", len(edges), 0, t)
+
+	// An empty podSelector selects all the pods in the namespace, including the pods without labels.
+	delete(nodes[0].Properties, "label")
+	n.Spec.PodSelector = metav1.LabelSelector{}
+	edges = NetworkPolicyResourceBuilder(&n, testOptions).BuildEdges(BuildFakeNodeStore(nodes))
+
+	AssertEqual("NetworkPolicy edge total: ", len(edges), 1, t)
+	AssertEqual("NetworkPolicy appliesTo", edges[0].DestUID, "uuid-123-pod", t)
+}
@@ -23,8 +23,8 @@ type NodeResource struct {
 }
 
 // NodeResourceBuilder ...
-func NodeResourceBuilder(n *v1.Node) *NodeResource {
-	node := transformCommon(n) // Start off with the common properties
+func NodeResourceBuilder(n *v1.Node, opts Options) *NodeResource {
+	node := transformCommon(n, opts) // Start off with the common properties
 
 	var roles []string
 	labels := n.ObjectMeta.Labels
@@ -69,16 +69,85 @@ func NodeResourceBuilder(n *v1.Node) *NodeResource {
 	}
 	node.Properties["status"] = status
 
+	node.Properties["memory"] = n.Status.Capacity.Memory().Value()
+	node.Properties["podCapacity"] = n.Status.Capacity.Pods().Value()
+	node.Properties["cpuAllocatable"] = n.Status.Allocatable.Cpu().MilliValue()
+	node.Properties["memoryAllocatable"] = n.Status.Allocatable.Memory().Value()
+	node.Properties["podAllocatable"] = n.Status.Allocatable.Pods().Value()
+	node.Properties["kubeletVersion"] = n.Status.NodeInfo.KubeletVersion
+	node.Properties["containerRuntimeVersion"] = n.Status.NodeInfo.ContainerRuntimeVersion
+
+	// Pressure conditions are only added when true, so they can be searched as i.e. condition:DiskPressure
+	conditions := []string{}
+	for _, condition := range n.Status.Conditions {
+		if condition.Type != v1.NodeReady && condition.Status == v1.ConditionTrue {
+			conditions = append(conditions, string(condition.Type))
+		}
+	}
+	node.Properties["condition"] = conditions
+
+	if len(n.Spec.Taints) > 0 {
+		taints := make([]string, 0, len(n.Spec.Taints))
+		for _, taint := range n.Spec.Taints {
+			taints = append(taints, taint.ToString())
+		}
+		node.Properties["taints"] = taints
+	}
+
+	// The failure-domain labels are deprecated, but still set on older clusters.
+	for property, keys := range map[string][]string{
+		"zone":   {v1.LabelTopologyZone, v1.LabelFailureDomainBetaZone},
+		"region": {v1.LabelTopologyRegion, v1.LabelFailureDomainBetaRegion},
+	} {
+		for _, key := range keys {
+			if value, ok := labels[key]; ok {
+				node.Properties[property] = value
+				break
+			}
+		}
+	}
+
+	for _, address := range n.Status.Addresses {
+		switch address.Type {
+		case v1.NodeInternalIP:
+			node.Properties["internalIP"] = appendProperty(node.Properties["internalIP"], address.Address)
+		case v1.NodeExternalIP:
+			node.Properties["externalIP"] = appendProperty(node.Properties["externalIP"], address.Address)
+		}
+	}
+
 	return &NodeResource{node: node}
 }
 
+// Appends the value to a []string property, which is nil when not set. Nodes can have an IPv4 and an IPv6 address.
+func appendProperty(property interface{}, value string) []string {
+	values, _ := property.([]string)
+	return append(values, value)
+}
+
 // BuildNode construct the node for the Node Resources
 func (n NodeResource) BuildNode() Node {
 	return n.node
 }
 
 // BuildEdges construct the edges for the Node Resources
+// The Node doesn't have edges of its own, the pods have runsOn edges to the Node. Computes the pod count and the
+// CPU (millicores) and memory (bytes) requested by the pods running on the Node.
 func (n NodeResource) BuildEdges(ns NodeStore) []Edge {
-	//no op for now to implement interface
+	podCount, cpuRequested, memoryRequested := int64(0), int64(0), int64(0)
+	for _, pod := range ns.PodsByNodeName[n.node.Properties["name"].(string)] {
+		podCount++
+		if cpu, ok := pod.Properties["cpuRequest"].(int64); ok {
+			cpuRequested += cpu
+		}
+		if memory, ok := pod.Properties["memoryRequest"].(int64); ok {
+			memoryRequested += memory
+		}
+	}
+	ns.setComputedProperties(n.node.UID, map[string]interface{}{
+		"podCount":        podCount,
+		"cpuRequested":    cpuRequested,
+		"memoryRequested": memoryRequested,
+	})
 	return []Edge{}
 }
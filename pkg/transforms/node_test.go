@@ -14,12 +14,13 @@ import (
 	"testing"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 func TestTransformNode(t *testing.T) {
 	var n v1.Node
 	UnmarshalFile("node.json", &n, t)
-	node := NodeResourceBuilder(&n).BuildNode()
+	node := NodeResourceBuilder(&n, testOptions).BuildNode()
 
 	// Test only the fields that exist in node - the common test will test the other bits
 	AssertEqual("architecture", node.Properties["architecture"], "amd64", t)
@@ -28,6 +29,19 @@ func TestTransformNode(t *testing.T) {
 	AssertEqual("_systemUUID", node.Properties["_systemUUID"], "4BCDE0D7-CFFB-4A8F-B6F8-0026F347AD93", t)
 	AssertDeepEqual("role", node.Properties["role"], []string{"etcd", "main", "management", "proxy", "va"}, t)
 	AssertDeepEqual("status", node.Properties["status"], "Ready", t)
+	AssertEqual("memory", node.Properties["memory"], int64(24689408*1024), t)
+	AssertEqual("podCapacity", node.Properties["podCapacity"], int64(80), t)
+	AssertEqual("cpuAllocatable", node.Properties["cpuAllocatable"], int64(7600), t)
+	AssertEqual("memoryAllocatable", node.Properties["memoryAllocatable"], int64(23538432*1024), t)
+	AssertEqual("podAllocatable", node.Properties["podAllocatable"], int64(80), t)
+	AssertEqual("kubeletVersion", node.Properties["kubeletVersion"], "v1.12.4+icp-ee", t)
+	AssertEqual("containerRuntimeVersion", node.Properties["containerRuntimeVersion"], "docker://17.12.1-ce", t)
+	AssertDeepEqual("condition", node.Properties["condition"], []string{"DiskPressure"}, t)
+	AssertDeepEqual("taints", node.Properties["taints"], []string{"dedicated=infra:NoSchedule"}, t)
+	AssertEqual("zone", node.Properties["zone"], "us-east-1a", t)
+	AssertEqual("region", node.Properties["region"], "us-east-1", t)
+	AssertDeepEqual("internalIP", node.Properties["internalIP"], []string{"1.1.1.1"}, t)
+	AssertEqual("externalIP", node.Properties["externalIP"], nil, t)
 }
 
 func TestNodeBuildEdges(t *testing.T) {
@@ -38,8 +52,44 @@ func TestNodeBuildEdges(t *testing.T) {
 	// Build edges from mock resource node.json
 	var n v1.Node
 	UnmarshalFile("node.json", &n, t)
-	edges := NodeResourceBuilder(&n).BuildEdges(nodeStore)
+	edges := NodeResourceBuilder(&n, testOptions).BuildEdges(nodeStore)
 
 	// Validate results
 	AssertEqual("Node has no edges:", len(edges), 0, t)
 }
+
+func TestNodeBuildEdgesAggregates(t *testing.T) {
+	var n v1.Node
+	UnmarshalFile("node.json", &n, t)
+	nodeResource := NodeResourceBuilder(&n, testOptions)
+
+	var p v1.Pod
+	UnmarshalFile("pod.json", &p, t)
+	p.Spec.Containers[0].Resources.Requests = v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("500m"),
+		v1.ResourceMemory: resource.MustParse("1Gi"),
+	}
+	pod := PodResourceBuilder(&p, testOptions).BuildNode()
+	nodeStore := BuildFakeNodeStore([]Node{nodeResource.BuildNode(), pod})
+
+	nodeResource.BuildEdges(nodeStore)
+	stored := nodeStore.ByUID[nodeResource.BuildNode().UID]
+	node, changed := WithComputedProperties(stored, nodeStore.Computed[stored.UID])
+
+	AssertEqual("podCount", node.Properties["podCount"], int64(1), t)
+	AssertEqual("cpuRequested", node.Properties["cpuRequested"], int64(500), t)
+	AssertEqual("memoryRequested", node.Properties["memoryRequested"], int64(1024*1024*1024), t)
+	AssertEqual("changed", changed, true, t)
+	// Building the edges doesn't modify the nodes in the store.
+	AssertEqual("stored podCount", stored.Properties["podCount"], nil, t)
+
+	// Building the edges again with the same pods isn't a change.
+	nodeResource.BuildEdges(nodeStore)
+	_, changed = WithComputedProperties(node, nodeStore.Computed[stored.UID])
+	AssertEqual("changed", changed, false, t)
+
+	// The computed properties are kept when the node is updated.
+	updated := NodeResourceBuilder(&n, testOptions).BuildNode()
+	CopyComputedProperties(node, updated)
+	AssertEqual("podCount", updated.Properties["podCount"], int64(1), t)
+}
@@ -22,8 +22,8 @@ type PersistentVolumeResource struct {
 }
 
 // PersistentVolumeResourceBuilder ...
-func PersistentVolumeResourceBuilder(p *v1.PersistentVolume) *PersistentVolumeResource {
-	node := transformCommon(p)         // Start off with the common properties
+func PersistentVolumeResourceBuilder(p *v1.PersistentVolume, opts Options) *PersistentVolumeResource {
+	node := transformCommon(p, opts)   // Start off with the common properties
 	apiGroupVersion(p.TypeMeta, &node) // add kind, apigroup and version
 	// Extract the properties specific to this type
 	node.Properties["reclaimPolicy"] = string(p.Spec.PersistentVolumeReclaimPolicy)
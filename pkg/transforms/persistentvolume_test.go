@@ -19,7 +19,7 @@ import (
 func TestTransformPersistentVolume(t *testing.T) {
 	var p v1.PersistentVolume
 	UnmarshalFile("persistentvolume.json", &p, t)
-	node := PersistentVolumeResourceBuilder(&p).BuildNode()
+	node := PersistentVolumeResourceBuilder(&p, testOptions).BuildNode()
 
 	// Test only the fields that exist in node - the common test will test the other bits
 	AssertEqual("reclaimPolicy", node.Properties["reclaimPolicy"], "Delete", t)
@@ -20,8 +20,8 @@ type PersistentVolumeClaimResource struct {
 }
 
 // PersistentVolumeClaimResourceBuilder ...
-func PersistentVolumeClaimResourceBuilder(p *v1.PersistentVolumeClaim) *PersistentVolumeClaimResource {
-	node := transformCommon(p) // Start off with the common properties
+func PersistentVolumeClaimResourceBuilder(p *v1.PersistentVolumeClaim, opts Options) *PersistentVolumeClaimResource {
+	node := transformCommon(p, opts) // Start off with the common properties
 
 	apiGroupVersion(p.TypeMeta, &node) // add kind, apigroup and version
 	// Extract the properties specific to this type
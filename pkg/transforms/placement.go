@@ -0,0 +1,104 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	cluster "open-cluster-management.io/api/cluster/v1beta1"
+)
+
+// PlacementResource ...
+type PlacementResource struct {
+	node        Node
+	ClusterSets []string
+}
+
+// PlacementResourceBuilder ...
+func PlacementResourceBuilder(p *cluster.Placement, opts Options) *PlacementResource {
+	node := transformCommon(p, opts)   // Start off with the common properties
+	apiGroupVersion(p.TypeMeta, &node) // add kind, apigroup and version
+	// Extract the properties specific to this type
+	if p.Spec.NumberOfClusters != nil {
+		node.Properties["numberOfClusters"] = int64(*p.Spec.NumberOfClusters)
+	}
+	node.Properties["numberOfSelectedClusters"] = int64(p.Status.NumberOfSelectedClusters)
+	if len(p.Spec.ClusterSets) > 0 {
+		node.Properties["clusterSets"] = p.Spec.ClusterSets
+	}
+
+	predicates := make([]string, 0, len(p.Spec.Predicates))
+	for _, predicate := range p.Spec.Predicates {
+		if summary := formatClusterSelector(predicate.RequiredClusterSelector); summary != "" {
+			predicates = append(predicates, summary)
+		}
+	}
+	if len(predicates) > 0 {
+		node.Properties["predicates"] = predicates
+	}
+
+	tolerations := make([]string, 0, len(p.Spec.Tolerations))
+	for _, t := range p.Spec.Tolerations {
+		tolerations = append(tolerations, formatToleration(t))
+	}
+	if len(tolerations) > 0 {
+		node.Properties["tolerations"] = tolerations
+	}
+
+	return &PlacementResource{node: node, ClusterSets: p.Spec.ClusterSets}
+}
+
+// Summarizes a predicate with its label and claim selectors, for example
+// labels:cloud=Amazon,environment in (dev,qa) claims:region=us-east-1
+func formatClusterSelector(s cluster.ClusterSelector) string {
+	parts := []string{}
+	if len(s.LabelSelector.MatchLabels) > 0 || len(s.LabelSelector.MatchExpressions) > 0 {
+		parts = append(parts, "labels:"+metav1.FormatLabelSelector(&s.LabelSelector))
+	}
+	if len(s.ClaimSelector.MatchExpressions) > 0 {
+		claims := metav1.LabelSelector{MatchExpressions: s.ClaimSelector.MatchExpressions}
+		parts = append(parts, "claims:"+metav1.FormatLabelSelector(&claims))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Formats a toleration like the taints of the managed clusters, key=value:Effect. A toleration with the Exists
+// operator doesn't have a value, and one without key tolerates all the taints.
+func formatToleration(t cluster.Toleration) string {
+	ret := t.Key
+	if ret == "" {
+		ret = "*"
+	}
+	if t.Operator != cluster.TolerationOpExists && t.Value != "" {
+		ret += "=" + t.Value
+	}
+	if t.Effect != "" {
+		ret += ":" + string(t.Effect)
+	}
+	return ret
+}
+
+// BuildNode construct the node for the Placement Resources
+func (p PlacementResource) BuildNode() Node {
+	return p.node
+}
+
+// BuildEdges construct the edges for the Placement Resources
+func (p PlacementResource) BuildEdges(ns NodeStore) []Edge {
+	// ManagedClusterSets are cluster scoped.
+	nodeInfo := NodeInfo{
+		Name:      p.node.Properties["name"].(string),
+		NameSpace: "_NONE",
+		UID:       p.node.UID,
+		EdgeType:  "selectsFrom",
+		Kind:      p.node.Properties["kind"].(string)}
+
+	// selectsFrom edges to the ManagedClusterSets in Spec.ClusterSets. Placements without clusterSets select from
+	// all the sets bound to the namespace, those don't have edges.
+	clusterSets := make(map[string]struct{}, len(p.ClusterSets))
+	for _, set := range p.ClusterSets {
+		clusterSets[set] = struct{}{}
+	}
+	return edgesByDestinationName(clusterSets, "ManagedClusterSet", nodeInfo, ns, []string{})
+}
@@ -0,0 +1,54 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	"testing"
+
+	cluster "open-cluster-management.io/api/cluster/v1beta1"
+)
+
+func TestTransformPlacement(t *testing.T) {
+	var p cluster.Placement
+	UnmarshalFile("placement.json", &p, t)
+	node := PlacementResourceBuilder(&p, testOptions).BuildNode()
+
+	// Test only the fields that exist in placement - the common test will test the other bits
+	AssertEqual("kind", node.Properties["kind"], "Placement", t)
+	AssertEqual("apigroup", node.Properties["apigroup"], "cluster.open-cluster-management.io", t)
+	AssertEqual("numberOfClusters", node.Properties["numberOfClusters"], int64(2), t)
+	AssertEqual("numberOfSelectedClusters", node.Properties["numberOfSelectedClusters"], int64(2), t)
+	AssertDeepEqual("clusterSets", node.Properties["clusterSets"], []string{"default", "global"}, t)
+	AssertDeepEqual("predicates", node.Properties["predicates"], []string{
+		"labels:cloud=Amazon,environment in (dev,qa) claims:region.open-cluster-management.io in (us-east-1)"}, t)
+	AssertDeepEqual("tolerations", node.Properties["tolerations"],
+		[]string{"cluster.open-cluster-management.io/unreachable", "gpu=true:NoSelect"}, t)
+}
+
+func TestFormatToleration(t *testing.T) {
+	AssertEqual("Exists without key", formatToleration(cluster.Toleration{Operator: cluster.TolerationOpExists}),
+		"*", t)
+	AssertEqual("Exists with effect", formatToleration(cluster.Toleration{Key: "gpu",
+		Operator: cluster.TolerationOpExists, Effect: "NoSelect"}), "gpu:NoSelect", t)
+	AssertEqual("Equal by default", formatToleration(cluster.Toleration{Key: "gpu", Value: "true"}), "gpu=true", t)
+}
+
+func TestPlacementBuildEdges(t *testing.T) {
+	// Build a fake NodeStore with nodes needed to generate edges.
+	nodes := []Node{{
+		UID:        "uuid-123-managedclusterset",
+		Properties: map[string]interface{}{"kind": "ManagedClusterSet", "name": "global"},
+	}}
+	nodeStore := BuildFakeNodeStore(nodes)
+
+	// Build edges from mock resource placement.json
+	var p cluster.Placement
+	UnmarshalFile("placement.json", &p, t)
+	edges := PlacementResourceBuilder(&p, testOptions).BuildEdges(nodeStore)
+
+	// The default ManagedClusterSet isn't in the NodeStore.
+	AssertEqual("Placement edge total: ", len(edges), 1, t)
+	AssertEqual("Placement selectsFrom", edges[0].EdgeType, EdgeType("selectsFrom"), t)
+	AssertEqual("Placement selectsFrom", edges[0].DestUID, "uuid-123-managedclusterset", t)
+	AssertEqual("Placement selectsFrom", edges[0].DestKind, "ManagedClusterSet", t)
+}
@@ -18,12 +18,14 @@ import (
 
 // PlacementBindingResource ...
 type PlacementBindingResource struct {
-	node Node
+	node         Node
+	PlacementRef policy.PlacementSubject
+	Subjects     []policy.Subject
 }
 
 // PlacementBindingResourceBuilder ...
-func PlacementBindingResourceBuilder(p *policy.PlacementBinding) *PlacementBindingResource {
-	node := transformCommon(p)         // Start off with the common properties
+func PlacementBindingResourceBuilder(p *policy.PlacementBinding, opts Options) *PlacementBindingResource {
+	node := transformCommon(p, opts)   // Start off with the common properties
 	apiGroupVersion(p.TypeMeta, &node) // add kind, apigroup and version
 	// Extract the properties specific to this type
 	name := p.PlacementRef.Name
@@ -39,7 +41,7 @@ func PlacementBindingResourceBuilder(p *policy.PlacementBinding) *PlacementBindi
 	}
 	node.Properties["subject"] = subjects
 
-	return &PlacementBindingResource{node: node}
+	return &PlacementBindingResource{node: node, PlacementRef: p.PlacementRef, Subjects: p.Subjects}
 }
 
 // BuildNode construct the node for the PlacementBindingResource Resources
@@ -49,6 +51,27 @@ func (p PlacementBindingResource) BuildNode() Node {
 
 // BuildEdges construct the edges for the PlacementBindingResource Resources
 func (p PlacementBindingResource) BuildEdges(ns NodeStore) []Edge {
-	//no op for now to implement interface
-	return []Edge{}
+	ret := []Edge{}
+	nodeInfo := NodeInfo{
+		Name:      p.node.Properties["name"].(string),
+		NameSpace: p.node.Properties["namespace"].(string),
+		UID:       p.node.UID,
+		EdgeType:  "refersTo",
+		Kind:      p.node.Properties["kind"].(string)}
+
+	// refersTo edge to the Placement or PlacementRule that selects the clusters.
+	if p.PlacementRef.Kind == "Placement" || p.PlacementRef.Kind == "PlacementRule" {
+		ret = append(ret, edgesByDestinationName(map[string]struct{}{p.PlacementRef.Name: {}}, p.PlacementRef.Kind,
+			nodeInfo, ns, []string{})...)
+	}
+
+	// bindsTo edges to the Policies and PolicySets placed on the clusters.
+	nodeInfo.EdgeType = "bindsTo"
+	for _, s := range p.Subjects {
+		if s.Kind == "Policy" || s.Kind == "PolicySet" {
+			ret = append(ret, edgesByDestinationName(map[string]struct{}{s.Name: {}}, s.Kind, nodeInfo, ns,
+				[]string{})...)
+		}
+	}
+	return ret
 }
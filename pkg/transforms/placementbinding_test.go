@@ -19,10 +19,33 @@ import (
 func TestTransformPlacementBinding(t *testing.T) {
 	var p policy.PlacementBinding
 	UnmarshalFile("placementbinding.json", &p, t)
-	node := PlacementBindingResourceBuilder(&p).BuildNode()
+	node := PlacementBindingResourceBuilder(&p, testOptions).BuildNode()
 
 	// Test only the fields that exist in placementbinding - the common test will test the other bits
 	AssertEqual("kind", node.Properties["kind"], "PlacementBinding", t)
 	AssertEqual("placementpolicy", node.Properties["placementpolicy"], "foo-test (PlacementPolicy)", t)
 	AssertDeepEqual("subject", node.Properties["subject"], []string{"foo-test (Deployable)"}, t)
 }
+
+func TestPlacementBindingBuildEdges(t *testing.T) {
+	// Build a fake NodeStore with nodes needed to generate edges.
+	nodes := []Node{{
+		UID:        "uuid-123-placementrule",
+		Properties: map[string]interface{}{"kind": "PlacementRule", "namespace": "default", "name": "placement-policy-01"},
+	}, {
+		UID:        "uuid-123-policy",
+		Properties: map[string]interface{}{"kind": "Policy", "namespace": "default", "name": "policy-01"},
+	}}
+	nodeStore := BuildFakeNodeStore(nodes)
+
+	// Build edges from mock resource policy-placementbinding.json
+	var p policy.PlacementBinding
+	UnmarshalFile("policy-placementbinding.json", &p, t)
+	edges := PlacementBindingResourceBuilder(&p, testOptions).BuildEdges(nodeStore)
+
+	AssertEqual("PlacementBinding edge total: ", len(edges), 2, t)
+	AssertEqual("PlacementBinding refersTo", edges[0].EdgeType, EdgeType("refersTo"), t)
+	AssertEqual("PlacementBinding refersTo", edges[0].DestUID, "uuid-123-placementrule", t)
+	AssertEqual("PlacementBinding bindsTo", edges[1].EdgeType, EdgeType("bindsTo"), t)
+	AssertEqual("PlacementBinding bindsTo", edges[1].DestUID, "uuid-123-policy", t)
+}
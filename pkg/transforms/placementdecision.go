@@ -0,0 +1,49 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	cluster "open-cluster-management.io/api/cluster/v1beta1"
+)
+
+// PlacementDecisionResource ...
+type PlacementDecisionResource struct {
+	node      Node
+	Placement string
+}
+
+// PlacementDecisionResourceBuilder ...
+func PlacementDecisionResourceBuilder(p *cluster.PlacementDecision, opts Options) *PlacementDecisionResource {
+	node := transformCommon(p, opts)   // Start off with the common properties
+	apiGroupVersion(p.TypeMeta, &node) // add kind, apigroup and version
+	// Extract the properties specific to this type
+	clusters := make([]string, 0, len(p.Status.Decisions))
+	for _, d := range p.Status.Decisions {
+		clusters = append(clusters, d.ClusterName)
+	}
+	node.Properties["selectedClusters"] = clusters
+
+	// The placement label has the name of the Placement in the same namespace.
+	return &PlacementDecisionResource{node: node, Placement: p.GetLabels()[cluster.PlacementLabel]}
+}
+
+// BuildNode construct the node for the PlacementDecision Resources
+func (p PlacementDecisionResource) BuildNode() Node {
+	return p.node
+}
+
+// BuildEdges construct the edges for the PlacementDecision Resources
+func (p PlacementDecisionResource) BuildEdges(ns NodeStore) []Edge {
+	if p.Placement == "" {
+		return []Edge{}
+	}
+	nodeInfo := NodeInfo{
+		Name:      p.node.Properties["name"].(string),
+		NameSpace: p.node.Properties["namespace"].(string),
+		UID:       p.node.UID,
+		EdgeType:  "decidedBy",
+		Kind:      p.node.Properties["kind"].(string)}
+
+	// decidedBy edge to the Placement that made the decision.
+	return edgesByDestinationName(map[string]struct{}{p.Placement: {}}, "Placement", nodeInfo, ns, []string{})
+}
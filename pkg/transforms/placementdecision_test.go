@@ -0,0 +1,40 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	"testing"
+
+	cluster "open-cluster-management.io/api/cluster/v1beta1"
+)
+
+func TestTransformPlacementDecision(t *testing.T) {
+	var p cluster.PlacementDecision
+	UnmarshalFile("placementdecision.json", &p, t)
+	node := PlacementDecisionResourceBuilder(&p, testOptions).BuildNode()
+
+	// Test only the fields that exist in placementdecision - the common test will test the other bits
+	AssertEqual("kind", node.Properties["kind"], "PlacementDecision", t)
+	AssertDeepEqual("selectedClusters", node.Properties["selectedClusters"],
+		[]string{"cluster-east", "cluster-west"}, t)
+	// The ownedBy edge to the Placement is kept next to the decidedBy edge.
+	AssertEqual("OwnerUID", node.Metadata["OwnerUID"], "local-cluster/1c4d2b6e-7a0f-4b8e-9d3c-5f6a7b8c9d01", t)
+}
+
+func TestPlacementDecisionBuildEdges(t *testing.T) {
+	// Build a fake NodeStore with nodes needed to generate edges.
+	nodes := []Node{{
+		UID:        "uuid-123-placement",
+		Properties: map[string]interface{}{"kind": "Placement", "namespace": "default", "name": "placement-01"},
+	}}
+	nodeStore := BuildFakeNodeStore(nodes)
+
+	// Build edges from mock resource placementdecision.json
+	var p cluster.PlacementDecision
+	UnmarshalFile("placementdecision.json", &p, t)
+	edges := PlacementDecisionResourceBuilder(&p, testOptions).BuildEdges(nodeStore)
+
+	AssertEqual("PlacementDecision edge total: ", len(edges), 1, t)
+	AssertEqual("PlacementDecision decidedBy", edges[0].EdgeType, EdgeType("decidedBy"), t)
+	AssertEqual("PlacementDecision decidedBy", edges[0].DestUID, "uuid-123-placement", t)
+}
@@ -20,8 +20,8 @@ type PlacementRuleResource struct {
 }
 
 // PlacementRuleResourceBuilder ...
-func PlacementRuleResourceBuilder(p *app.PlacementRule) *PlacementRuleResource {
-	node := transformCommon(p)         // Start off with the common properties
+func PlacementRuleResourceBuilder(p *app.PlacementRule, opts Options) *PlacementRuleResource {
+	node := transformCommon(p, opts)   // Start off with the common properties
 	apiGroupVersion(p.TypeMeta, &node) // add kind, apigroup and version
 	// Add replicas property
 	if p.Spec.ClusterReplicas != nil {
@@ -12,6 +12,7 @@ package transforms
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/golang/glog"
@@ -20,12 +21,13 @@ import (
 
 // PodResource ...
 type PodResource struct {
-	node Node
-	Spec v1.PodSpec
+	node   Node
+	Spec   v1.PodSpec
+	images []Node // Synthetic ContainerImage nodes.
 }
 
 // PodResourceBuilder ...
-func PodResourceBuilder(p *v1.Pod) *PodResource {
+func PodResourceBuilder(p *v1.Pod, opts Options) *PodResource {
 	// Loop over spec to get the container and image names
 	var containers []string
 	var images []string
@@ -104,7 +106,7 @@ func PodResourceBuilder(p *v1.Pod) *PodResource {
 		reason = "Terminating"
 	}
 
-	node := transformCommon(p) // Start off with the common properties
+	node := transformCommon(p, opts) // Start off with the common properties
 	ownerReferences := p.ObjectMeta.OwnerReferences
 
 	apiGroupVersion(p.TypeMeta, &node) // add kind, apigroup and version
@@ -116,15 +118,114 @@ func PodResourceBuilder(p *v1.Pod) *PodResource {
 	node.Properties["container"] = containers
 	node.Properties["image"] = images
 	node.Properties["startedAt"] = ""
+	if p.Spec.NodeName != "" && p.Status.Phase != v1.PodSucceeded && p.Status.Phase != v1.PodFailed {
+		node.Metadata["NodeName"] = p.Spec.NodeName // Used to compute the resources requested on the Node.
+	}
+	if p.Spec.ServiceAccountName != "" {
+		node.Properties["serviceAccount"] = p.Spec.ServiceAccountName
+	}
 	if len(ownerReferences) > 0 &&
 		(ownerReferences[0].Kind == "ReplicationController" || ownerReferences[0].Kind == "ReplicaSet") {
-		node.Properties["_ownerUID"] = ownerRefUID(ownerReferences)
+		node.Properties["_ownerUID"] = ownerRefUID(ownerReferences, opts)
 	}
 	if p.Status.StartTime != nil {
 		node.Properties["startedAt"] = p.Status.StartTime.UTC().Format(time.RFC3339)
 	}
+	if p.Status.QOSClass != "" {
+		node.Properties["qosClass"] = string(p.Status.QOSClass)
+	}
+	if p.Spec.PriorityClassName != "" {
+		node.Properties["priorityClass"] = p.Spec.PriorityClassName
+	}
+	if len(p.Spec.NodeSelector) > 0 {
+		node.Properties["nodeSelector"] = p.Spec.NodeSelector
+	}
+	addPodResources(p, &node)
+	addContainerStates(p, &node)
 
-	return &PodResource{node: node, Spec: p.Spec}
+	return &PodResource{node: node, Spec: p.Spec, images: podContainerImages(p, opts)}
+}
+
+// Adds the CPU (millicores) and memory (bytes) requests and limits of the pod.
+// A limit is only added when all the containers have it, so pods without limits don't have the property.
+func addPodResources(p *v1.Pod, node *Node) {
+	if v, ok := podResource(p, v1.ResourceCPU, false); ok {
+		node.Properties["cpuRequest"] = v
+	}
+	if v, ok := podResource(p, v1.ResourceCPU, true); ok {
+		node.Properties["cpuLimit"] = v
+	}
+	if v, ok := podResource(p, v1.ResourceMemory, false); ok {
+		node.Properties["memoryRequest"] = v
+	}
+	if v, ok := podResource(p, v1.ResourceMemory, true); ok {
+		node.Properties["memoryLimit"] = v
+	}
+}
+
+// Returns the request or limit of the pod for the resource. Like the scheduler, the pod needs the sum of its
+// containers or the largest init container, whichever is higher, plus the pod overhead.
+// Returns false if no container sets it, or for limits, if any container doesn't set it.
+func podResource(p *v1.Pod, name v1.ResourceName, limit bool) (int64, bool) {
+	value := func(resources v1.ResourceRequirements) (int64, bool) {
+		list := resources.Requests
+		if limit {
+			list = resources.Limits
+		}
+		q, ok := list[name]
+		if name == v1.ResourceCPU {
+			return q.MilliValue(), ok
+		}
+		return q.Value(), ok
+	}
+
+	total, found := int64(0), false
+	for _, c := range p.Spec.Containers {
+		v, ok := value(c.Resources)
+		if !ok && limit {
+			return 0, false // Unlimited.
+		}
+		total += v
+		found = found || ok
+	}
+	for _, c := range p.Spec.InitContainers {
+		if v, ok := value(c.Resources); ok {
+			found = true
+			if v > total {
+				total = v
+			}
+		}
+	}
+	if overhead, ok := value(v1.ResourceRequirements{Requests: p.Spec.Overhead, Limits: p.Spec.Overhead}); ok {
+		total += overhead
+	}
+	return total, found
+}
+
+// Adds the number of ready containers and the state of each container as name=state, i.e. app=running or
+// app=waiting:CrashLoopBackOff
+func addContainerStates(p *v1.Pod, node *Node) {
+	ready := int64(0)
+	states := make([]string, 0, len(p.Status.ContainerStatuses))
+	for _, c := range p.Status.ContainerStatuses {
+		if c.Ready {
+			ready++
+		}
+		state := "unknown"
+		switch {
+		case c.State.Running != nil:
+			state = "running"
+		case c.State.Waiting != nil:
+			state = "waiting:" + c.State.Waiting.Reason
+		case c.State.Terminated != nil:
+			state = "terminated:" + c.State.Terminated.Reason
+		}
+		states = append(states, c.Name+"="+strings.TrimSuffix(state, ":"))
+	}
+	node.Properties["readyContainers"] = ready
+	if len(states) > 0 {
+		node.Properties["containerState"] = states
+	}
 }
 
 // BuildNode construct the node for the Pod Resources
@@ -132,6 +233,11 @@ func (p PodResource) BuildNode() Node {
 	return p.node
 }
 
+// BuildSyntheticNodes returns the ContainerImage nodes for the images used by the Pod
+func (p PodResource) BuildSyntheticNodes() []Node {
+	return p.images
+}
+
 // BuildEdges construct the edges for the Pod Resources
 func (p PodResource) BuildEdges(ns NodeStore) []Edge {
 	ret := make([]Edge, 0, 8)
@@ -146,46 +252,7 @@ func (p PodResource) BuildEdges(ns NodeStore) []Edge {
 		Kind:      p.node.Properties["kind"].(string)}
 
 	// attachedTo edges
-	secretMap := make(map[string]struct{})
-	configmapMap := make(map[string]struct{})
-	volumeClaimMap := make(map[string]struct{})
-	volumeMap := make(map[string]struct{})
-
-	// Parse the pod's spec to create a list of all the secrets, configmaps and volumes it is attached to
-	for _, container := range p.Spec.Containers {
-		for _, envVal := range container.Env {
-			if envVal.ValueFrom != nil {
-				if envVal.ValueFrom.SecretKeyRef != nil {
-					secretMap[envVal.ValueFrom.SecretKeyRef.Name] = struct{}{}
-				} else if envVal.ValueFrom.ConfigMapKeyRef != nil {
-					configmapMap[envVal.ValueFrom.ConfigMapKeyRef.Name] = struct{}{}
-				}
-			}
-		}
-	}
-
-	for _, volume := range p.Spec.Volumes {
-		if volume.Secret != nil {
-			secretMap[volume.Secret.SecretName] = struct{}{}
-		} else if volume.ConfigMap != nil {
-			configmapMap[volume.ConfigMap.Name] = struct{}{}
-		} else if volume.PersistentVolumeClaim != nil {
-			volumeClaimName := volume.PersistentVolumeClaim.ClaimName
-			volumeClaimMap[volumeClaimName] = struct{}{}
-			if pvClaimNode, ok := ns.ByKindNamespaceName["PersistentVolumeClaim"][nodeInfo.NameSpace][volumeClaimName]; ok {
-				if volName, ok := pvClaimNode.Properties["volumeName"].(string); ok && pvClaimNode.Properties["volumeName"] != "" {
-					volumeMap[volName] = struct{}{}
-				}
-			}
-		}
-	}
-
-	// Create all 'attachedTo' edges between pod and nodes of a specific kind(secrets, configmaps, volumeClaims, volumes)
-	ret = append(ret, edgesByDestinationName(secretMap, "Secret", nodeInfo, ns, []string{})...)
-	ret = append(ret, edgesByDestinationName(configmapMap, "ConfigMap", nodeInfo, ns, []string{})...)
-	ret = append(ret, edgesByDestinationName(volumeClaimMap, "PersistentVolumeClaim", nodeInfo, ns, []string{})...)
-	nodeInfo.NameSpace = "_NONE"
-	ret = append(ret, edgesByDestinationName(volumeMap, "PersistentVolume", nodeInfo, ns, []string{})...)
+	ret = append(ret, edgesByPodSpec(p.Spec, nodeInfo, ns)...)
 
 	// runsOn edges
 	if p.Spec.NodeName != "" {
@@ -206,5 +273,25 @@ func (p PodResource) BuildEdges(ns NodeStore) []Edge {
 				p.node.Properties["namespace"].(string)+"/"+p.node.Properties["name"].(string), "_NONE/"+nodeName)
 		}
 	}
+
+	// runsAs edges
+	if p.Spec.ServiceAccountName != "" {
+		nodeInfo.EdgeType = "runsAs"
+		serviceAccount := map[string]struct{}{p.Spec.ServiceAccountName: {}}
+		ret = append(ret, edgesByDestinationName(serviceAccount, "ServiceAccount", nodeInfo, ns, []string{})...)
+	}
+
+	// usesImage edges. The ContainerImage nodes are added by the reconciler with the pod.
+	for _, image := range p.images {
+		if _, ok := ns.ByUID[image.UID]; ok {
+			ret = append(ret, Edge{
+				SourceUID:  UID,
+				DestUID:    image.UID,
+				EdgeType:   "usesImage",
+				SourceKind: nodeInfo.Kind,
+				DestKind:   "ContainerImage",
+			})
+		}
+	}
 	return ret
 }
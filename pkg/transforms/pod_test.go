@@ -15,12 +15,13 @@ import (
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 func TestTransformPod(t *testing.T) {
 	var p v1.Pod
 	UnmarshalFile("pod.json", &p, t)
-	node := PodResourceBuilder(&p).BuildNode()
+	node := PodResourceBuilder(&p, testOptions).BuildNode()
 
 	// Build time struct matching time in test data
 	date := time.Date(2019, 02, 21, 21, 30, 33, 0, time.UTC)
@@ -36,12 +37,61 @@ func TestTransformPod(t *testing.T) {
 	AssertEqual("startedAt", node.Properties["startedAt"], date.UTC().Format(time.RFC3339), t)
 	AssertEqual("status", node.Properties["status"], string(v1.PodRunning), t)
 	AssertEqual("_ownerUID", node.Properties["_ownerUID"], "local-cluster/eb762405-361f-11e9-85ca-00163e019656", t)
+	AssertEqual("qosClass", node.Properties["qosClass"], "BestEffort", t)
+	AssertEqual("readyContainers", node.Properties["readyContainers"], int64(1), t)
+	AssertDeepEqual("containerState", node.Properties["containerState"], []string{"fake-pod=running"}, t)
+	AssertEqual("cpuRequest", node.Properties["cpuRequest"], nil, t)
+	AssertEqual("memoryLimit", node.Properties["memoryLimit"], nil, t)
+}
+
+func TestTransformPodResources(t *testing.T) {
+	var p v1.Pod
+	UnmarshalFile("pod.json", &p, t)
+	p.Spec.Containers[0].Resources = v1.ResourceRequirements{
+		Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("250m"), v1.ResourceMemory: resource.MustParse("64Mi")},
+		Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("128Mi")},
+	}
+	p.Spec.Containers = append(p.Spec.Containers, v1.Container{
+		Name: "sidecar",
+		Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")},
+			Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("200m")},
+		},
+	})
+	p.Spec.InitContainers = []v1.Container{{
+		Name: "init",
+		Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceMemory: resource.MustParse("256Mi")},
+		},
+	}}
+	p.Spec.NodeSelector = map[string]string{"kubernetes.io/os": "linux"}
+	p.Spec.PriorityClassName = "high-priority"
+	p.Status.QOSClass = v1.PodQOSBurstable
+	p.Status.ContainerStatuses = append(p.Status.ContainerStatuses, v1.ContainerStatus{
+		Name:  "sidecar",
+		State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+	})
+	node := PodResourceBuilder(&p, testOptions).BuildNode()
+
+	AssertEqual("cpuRequest", node.Properties["cpuRequest"], int64(350), t)
+	AssertEqual("cpuLimit", node.Properties["cpuLimit"], int64(1200), t)
+	// The init container requests more memory than the containers.
+	AssertEqual("memoryRequest", node.Properties["memoryRequest"], int64(256*1024*1024), t)
+	// The sidecar doesn't have a memory limit.
+	AssertEqual("memoryLimit", node.Properties["memoryLimit"], nil, t)
+	AssertEqual("qosClass", node.Properties["qosClass"], "Burstable", t)
+	AssertEqual("priorityClass", node.Properties["priorityClass"], "high-priority", t)
+	AssertDeepEqual("nodeSelector", node.Properties["nodeSelector"], map[string]string{"kubernetes.io/os": "linux"}, t)
+	AssertEqual("readyContainers", node.Properties["readyContainers"], int64(1), t)
+	AssertDeepEqual("containerState", node.Properties["containerState"],
+		[]string{"fake-pod=running", "sidecar=waiting:CrashLoopBackOff"}, t)
+	AssertEqual("status", node.Properties["status"], "CrashLoopBackOff", t)
 }
 
 func TestTransformPodInitWaiting(t *testing.T) {
 	var p v1.Pod
 	UnmarshalFile("pod-init-waiting.json", &p, t)
-	node := PodResourceBuilder(&p).BuildNode()
+	node := PodResourceBuilder(&p, testOptions).BuildNode()
 
 	AssertEqual("podIP", node.Properties["podIP"], "2.2.2.3", t)
 	AssertEqual("restarts", node.Properties["restarts"], int64(2), t)
@@ -51,7 +101,7 @@ func TestTransformPodInitWaiting(t *testing.T) {
 func TestTransformPodInitFailed(t *testing.T) {
 	var p v1.Pod
 	UnmarshalFile("pod-init-failed.json", &p, t)
-	node := PodResourceBuilder(&p).BuildNode()
+	node := PodResourceBuilder(&p, testOptions).BuildNode()
 
 	// Test only status of pood with a completed init container
 	AssertEqual("status", node.Properties["status"], "Init:ExitCode:255", t)
@@ -84,7 +134,7 @@ func TestPodBuildEdges(t *testing.T) {
 	// Build edges from mock resource pod.json
 	var p v1.Pod
 	UnmarshalFile("pod.json", &p, t)
-	edges := PodResourceBuilder(&p).BuildEdges(nodeStore)
+	edges := PodResourceBuilder(&p, testOptions).BuildEdges(nodeStore)
 
 	// Verify created edges.
 	AssertEqual("Pod edge total: ", len(edges), 5, t)
@@ -94,3 +144,34 @@ func TestPodBuildEdges(t *testing.T) {
 	AssertEqual("Pod attachedTo", edges[3].DestKind, "PersistentVolume", t)
 	AssertEqual("Pod runsOn", edges[4].DestKind, "Node", t)
 }
+
+func TestPodBuildEdgesServiceAccount(t *testing.T) {
+	nodes := []Node{{
+		UID:        "uuid-123-serviceaccount",
+		Properties: map[string]interface{}{"kind": "ServiceAccount", "namespace": "default", "name": "default"},
+	}}
+	nodeStore := BuildFakeNodeStore(nodes)
+
+	var p v1.Pod
+	UnmarshalFile("pod.json", &p, t)
+	edges := PodResourceBuilder(&p, testOptions).BuildEdges(nodeStore)
+
+	AssertEqual("Pod edge total: ", len(edges), 1, t)
+	AssertEqual("Pod runsAs", edges[0].EdgeType, EdgeType("runsAs"), t)
+	AssertEqual("Pod runsAs", edges[0].DestKind, "ServiceAccount", t)
+}
+
+func TestPodBuildEdgesContainerImage(t *testing.T) {
+	var p v1.Pod
+	UnmarshalFile("pod.json", &p, t)
+	pod := PodResourceBuilder(&p, testOptions)
+	image := pod.BuildSyntheticNodes()[0]
+
+	// The ContainerImage is added to the store by the reconciler with the pod.
+	edges := pod.BuildEdges(BuildFakeNodeStore([]Node{image}))
+
+	AssertEqual("Pod edge total: ", len(edges), 1, t)
+	AssertEqual("Pod usesImage", edges[0].EdgeType, EdgeType("usesImage"), t)
+	AssertEqual("Pod usesImage", edges[0].DestUID, image.UID, t)
+	AssertEqual("Pod usesImage", edges[0].DestKind, "ContainerImage", t)
+}
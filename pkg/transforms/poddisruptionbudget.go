@@ -0,0 +1,67 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	"github.com/golang/glog"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PodDisruptionBudgetResource ...
+type PodDisruptionBudgetResource struct {
+	node     Node
+	Selector *metav1.LabelSelector
+}
+
+// PodDisruptionBudgetResourceBuilder ...
+func PodDisruptionBudgetResourceBuilder(p *policyv1.PodDisruptionBudget, opts Options) *PodDisruptionBudgetResource {
+	node := transformCommon(p, opts)   // Start off with the common properties
+	apiGroupVersion(p.TypeMeta, &node) // add kind, apigroup and version
+	// Extract the properties specific to this type
+	// minAvailable and maxUnavailable can be a number or a percentage, so they are kept as strings.
+	if p.Spec.MinAvailable != nil {
+		node.Properties["minAvailable"] = p.Spec.MinAvailable.String()
+	}
+	if p.Spec.MaxUnavailable != nil {
+		node.Properties["maxUnavailable"] = p.Spec.MaxUnavailable.String()
+	}
+	node.Properties["currentHealthy"] = int64(p.Status.CurrentHealthy)
+	node.Properties["desiredHealthy"] = int64(p.Status.DesiredHealthy)
+	node.Properties["expectedPods"] = int64(p.Status.ExpectedPods)
+	node.Properties["disruptionsAllowed"] = int64(p.Status.DisruptionsAllowed)
+
+	return &PodDisruptionBudgetResource{node: node, Selector: p.Spec.Selector}
+}
+
+// BuildNode construct the node for the PodDisruptionBudget Resources
+func (p PodDisruptionBudgetResource) BuildNode() Node {
+	return p.node
+}
+
+// BuildEdges construct the edges for the PodDisruptionBudget Resources
+func (p PodDisruptionBudgetResource) BuildEdges(ns NodeStore) []Edge {
+	ret := []Edge{}
+	if p.Selector == nil {
+		// A null selector selects no pods.
+		return ret
+	}
+	selector, err := metav1.LabelSelectorAsSelector(p.Selector)
+	if err != nil {
+		glog.V(2).Infof("Invalid selector in PodDisruptionBudget %s/%s. Not building protects edges. %s",
+			p.node.Properties["namespace"], p.node.Properties["name"], err)
+		return ret
+	}
+
+	// protects edges. An empty selector selects all the pods in the namespace.
+	for _, pod := range podsBySelector(ns, p.node.Properties["namespace"].(string), selector) {
+		ret = append(ret, Edge{
+			SourceUID:  p.node.UID,
+			DestUID:    pod.UID,
+			EdgeType:   "protects",
+			SourceKind: p.node.Properties["kind"].(string),
+			DestKind:   "Pod",
+		})
+	}
+	return ret
+}
@@ -0,0 +1,57 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTransformPodDisruptionBudget(t *testing.T) {
+	var p v1.PodDisruptionBudget
+	UnmarshalFile("poddisruptionbudget.json", &p, t)
+	node := PodDisruptionBudgetResourceBuilder(&p, testOptions).BuildNode()
+
+	// Test only the fields that exist in poddisruptionbudget - the common test will test the other bits
+	AssertEqual("maxUnavailable", node.Properties["maxUnavailable"], "25%", t)
+	AssertEqual("minAvailable", node.Properties["minAvailable"], nil, t)
+	AssertEqual("currentHealthy", node.Properties["currentHealthy"], int64(3), t)
+	AssertEqual("desiredHealthy", node.Properties["desiredHealthy"], int64(3), t)
+	AssertEqual("expectedPods", node.Properties["expectedPods"], int64(4), t)
+	AssertEqual("disruptionsAllowed", node.Properties["disruptionsAllowed"], int64(0), t)
+}
+
+func TestPodDisruptionBudgetBuildEdges(t *testing.T) {
+	// Build a fake NodeStore with nodes needed to generate edges.
+	nodes := []Node{{
+		UID: "uuid-123-pod",
+		Properties: map[string]interface{}{"kind": "Pod", "namespace": "default", "name": "test-pod",
+			"label": map[string]string{"app": "test-fixture"}},
+	}}
+	nodeStore := BuildFakeNodeStore(nodes)
+
+	// Build edges from mock resource poddisruptionbudget.json
+	var p v1.PodDisruptionBudget
+	UnmarshalFile("poddisruptionbudget.json", &p, t)
+	edges := PodDisruptionBudgetResourceBuilder(&p, testOptions).BuildEdges(nodeStore)
+
+	AssertEqual("PodDisruptionBudget edge total: ", len(edges), 1, t)
+	AssertEqual("PodDisruptionBudget protects", edges[0].EdgeType, EdgeType("protects"), t)
+	AssertEqual("PodDisruptionBudget protects", edges[0].DestUID, "uuid-123-pod", t)
+
+	// A null selector selects no pods.
+	p.Spec.Selector = nil
+	edges = PodDisruptionBudgetResourceBuilder(&p, testOptions).BuildEdges(nodeStore)
+
+	AssertEqual("PodDisruptionBudget edge total: ", len(edges), 0, t)
+
+	// An empty selector selects all the pods in the namespace, including the pods without labels.
+	delete(nodes[0].Properties, "label")
+	p.Spec.Selector = &metav1.LabelSelector{}
+	edges = PodDisruptionBudgetResourceBuilder(&p, testOptions).BuildEdges(BuildFakeNodeStore(nodes))
+
+	AssertEqual("PodDisruptionBudget edge total: ", len(edges), 1, t)
+	AssertEqual("PodDisruptionBudget protects", edges[0].DestUID, "uuid-123-pod", t)
+}
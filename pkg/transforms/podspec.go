@@ -0,0 +1,95 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// Builds the attachedTo edges from a pod spec to the Secrets, ConfigMaps, PersistentVolumeClaims and the
+// PersistentVolumes bound to the claims. Used for Pods, and for the pod template of the workloads, so a
+// Deployment scaled to zero or a suspended CronJob still show their dependencies.
+// The additionalClaims are the names of other claims used by the pods, i.e. from volumeClaimTemplates.
+func edgesByPodSpec(spec v1.PodSpec, nodeInfo NodeInfo, ns NodeStore, additionalClaims ...string) []Edge {
+	ret := []Edge{}
+	nodeInfo.EdgeType = "attachedTo"
+
+	secretMap := make(map[string]struct{})
+	configmapMap := make(map[string]struct{})
+	volumeClaimMap := make(map[string]struct{})
+	volumeMap := make(map[string]struct{})
+
+	// Parse the pod's spec to create a list of all the secrets, configmaps and volumes it is attached to
+	containers := append(append([]v1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, container := range containers {
+		for _, envVal := range container.Env {
+			if envVal.ValueFrom != nil {
+				if envVal.ValueFrom.SecretKeyRef != nil {
+					secretMap[envVal.ValueFrom.SecretKeyRef.Name] = struct{}{}
+				} else if envVal.ValueFrom.ConfigMapKeyRef != nil {
+					configmapMap[envVal.ValueFrom.ConfigMapKeyRef.Name] = struct{}{}
+				}
+			}
+		}
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil {
+				secretMap[envFrom.SecretRef.Name] = struct{}{}
+			} else if envFrom.ConfigMapRef != nil {
+				configmapMap[envFrom.ConfigMapRef.Name] = struct{}{}
+			}
+		}
+	}
+
+	for _, secret := range spec.ImagePullSecrets {
+		secretMap[secret.Name] = struct{}{}
+	}
+
+	for _, volume := range spec.Volumes {
+		if volume.Secret != nil {
+			secretMap[volume.Secret.SecretName] = struct{}{}
+		} else if volume.ConfigMap != nil {
+			configmapMap[volume.ConfigMap.Name] = struct{}{}
+		} else if volume.PersistentVolumeClaim != nil {
+			volumeClaimMap[volume.PersistentVolumeClaim.ClaimName] = struct{}{}
+		} else if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.Secret != nil {
+					secretMap[source.Secret.Name] = struct{}{}
+				} else if source.ConfigMap != nil {
+					configmapMap[source.ConfigMap.Name] = struct{}{}
+				}
+			}
+		}
+	}
+	for _, claim := range additionalClaims {
+		volumeClaimMap[claim] = struct{}{}
+	}
+
+	for volumeClaimName := range volumeClaimMap {
+		if pvClaimNode, ok := ns.ByKindNamespaceName["PersistentVolumeClaim"][nodeInfo.NameSpace][volumeClaimName]; ok {
+			if volName, ok := pvClaimNode.Properties["volumeName"].(string); ok && volName != "" {
+				volumeMap[volName] = struct{}{}
+			}
+		}
+	}
+
+	// Create all 'attachedTo' edges between pod and nodes of a specific kind(secrets, configmaps, volumeClaims, volumes)
+	ret = append(ret, edgesByDestinationName(secretMap, "Secret", nodeInfo, ns, []string{})...)
+	ret = append(ret, edgesByDestinationName(configmapMap, "ConfigMap", nodeInfo, ns, []string{})...)
+	ret = append(ret, edgesByDestinationName(volumeClaimMap, "PersistentVolumeClaim", nodeInfo, ns, []string{})...)
+	nodeInfo.NameSpace = "_NONE"
+	ret = append(ret, edgesByDestinationName(volumeMap, "PersistentVolume", nodeInfo, ns, []string{})...)
+
+	return ret
+}
+
+// Builds the attachedTo edges from the pod template of a workload. See edgesByPodSpec.
+func edgesByPodTemplate(node Node, spec v1.PodSpec, ns NodeStore, additionalClaims ...string) []Edge {
+	namespace, _ := node.Properties["namespace"].(string)
+	nodeInfo := NodeInfo{
+		Name:      node.Properties["name"].(string),
+		NameSpace: namespace,
+		UID:       node.UID,
+		Kind:      node.Properties["kind"].(string)}
+	return edgesByPodSpec(spec, nodeInfo, ns, additionalClaims...)
+}
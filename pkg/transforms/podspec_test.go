@@ -0,0 +1,50 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestEdgesByPodSpec(t *testing.T) {
+	// Build a fake NodeStore with nodes needed to generate edges.
+	nodes := []Node{{
+		UID:        "uuid-123-secret",
+		Properties: map[string]interface{}{"kind": "Secret", "namespace": "default", "name": "pull-secret"},
+	}, {
+		UID:        "uuid-123-configmap",
+		Properties: map[string]interface{}{"kind": "ConfigMap", "namespace": "default", "name": "projected-configmap"},
+	}}
+	nodeStore := BuildFakeNodeStore(nodes)
+
+	spec := v1.PodSpec{
+		InitContainers: []v1.Container{{
+			Name: "init",
+			EnvFrom: []v1.EnvFromSource{{
+				SecretRef: &v1.SecretEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: "env-secret"}},
+			}},
+		}},
+		ImagePullSecrets: []v1.LocalObjectReference{{Name: "pull-secret"}},
+		Volumes: []v1.Volume{{
+			Name: "projected",
+			VolumeSource: v1.VolumeSource{Projected: &v1.ProjectedVolumeSource{
+				Sources: []v1.VolumeProjection{{
+					ConfigMap: &v1.ConfigMapProjection{
+						LocalObjectReference: v1.LocalObjectReference{Name: "projected-configmap"},
+					},
+				}},
+			}},
+		}},
+	}
+	nodeInfo := NodeInfo{Name: "test-deployment", NameSpace: "default", UID: "uuid-123-deployment", Kind: "Deployment"}
+	edges := edgesByPodSpec(spec, nodeInfo, nodeStore)
+
+	// The env-secret isn't in the NodeStore.
+	AssertEqual("Pod spec edge total:", len(edges), 2, t)
+	AssertEqual("Pod spec attachedTo", edges[0].DestUID, "uuid-123-secret", t)
+	AssertEqual("Pod spec attachedTo", edges[0].EdgeType, EdgeType("attachedTo"), t)
+	AssertEqual("Pod spec attachedTo", edges[1].DestUID, "uuid-123-configmap", t)
+	AssertEqual("Pod spec attachedTo", edges[1].SourceKind, "Deployment", t)
+}
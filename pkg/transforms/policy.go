@@ -11,17 +11,24 @@ Copyright (c) 2020 Red Hat, Inc.
 package transforms
 
 import (
+	"encoding/json"
+	"strings"
+
+	"github.com/golang/glog"
 	p "github.com/stolostron/governance-policy-propagator/api/v1"
 )
 
+// Ordered from the lowest to the highest severity. The policy has the highest severity of its templates.
+var policySeverities = []string{"low", "medium", "high", "critical"}
+
 // PolicyResource ...
 type PolicyResource struct {
 	node Node
 }
 
 // PolicyResourceBuilder ...
-func PolicyResourceBuilder(p *p.Policy) *PolicyResource {
-	node := transformCommon(p)         // Start off with the common properties
+func PolicyResourceBuilder(p *p.Policy, opts Options) *PolicyResource {
+	node := transformCommon(p, opts)   // Start off with the common properties
 	apiGroupVersion(p.TypeMeta, &node) // add kind, apigroup and version
 	// Extract the properties specific to this type
 	node.Properties["remediationAction"] = string(p.Spec.RemediationAction)
@@ -32,14 +39,95 @@ func PolicyResourceBuilder(p *p.Policy) *PolicyResource {
 	if p.Status.ComplianceState != "" {
 		node.Properties["compliant"] = string(p.Status.ComplianceState)
 	}
+	if len(p.Status.Status) > 0 {
+		addClusterCompliance(p.Status.Status, &node)
+	}
+	addTemplateProperties(p.Spec.PolicyTemplates, &node)
+	for _, annotation := range []string{"standards", "categories", "controls"} {
+		values := splitAnnotation(p.GetAnnotations()["policy.open-cluster-management.io/"+annotation])
+		if len(values) > 0 {
+			node.Properties[annotation] = values
+		}
+	}
+
 	pnamespace, okns := p.ObjectMeta.Labels["parent-namespace"]
 	ppolicy, okpp := p.ObjectMeta.Labels["parent-policy"]
+	root := p.ObjectMeta.Labels["policy.open-cluster-management.io/root-policy"]
 	if okns && okpp {
 		node.Properties["_parentPolicy"] = pnamespace + "/" + ppolicy
+	} else if strings.Contains(root, ".") {
+		// The root-policy label is <namespace>.<name>, namespaces can't have dots.
+		node.Properties["_parentPolicy"] = strings.Replace(root, ".", "/", 1)
 	}
 	return &PolicyResource{node: node}
 }
 
+// Adds the number of clusters where the root policy is compliant, noncompliant and pending.
+// A cluster without compliance state hasn't reported it yet, so it's pending.
+func addClusterCompliance(status []*p.CompliancePerClusterStatus, node *Node) {
+	compliant, noncompliant, pending := int64(0), int64(0), int64(0)
+	for _, s := range status {
+		switch s.ComplianceState {
+		case p.Compliant:
+			compliant++
+		case p.NonCompliant:
+			noncompliant++
+		default:
+			pending++
+		}
+	}
+	node.Properties["compliantClusters"] = compliant
+	node.Properties["noncompliantClusters"] = noncompliant
+	node.Properties["pendingClusters"] = pending
+}
+
+// Adds the kinds of the policy templates, i.e. ConfigurationPolicy, and the highest severity of the templates.
+func addTemplateProperties(templates []*p.PolicyTemplate, node *Node) {
+	kinds := make(map[string]struct{})
+	severity := -1
+	for _, t := range templates {
+		if t == nil || len(t.ObjectDefinition.Raw) == 0 {
+			continue
+		}
+		template := struct {
+			Kind string `json:"kind"`
+			Spec struct {
+				Severity string `json:"severity"`
+			} `json:"spec"`
+		}{}
+		if err := json.Unmarshal(t.ObjectDefinition.Raw, &template); err != nil {
+			glog.V(3).Infof("Unable to read policy template of %s/%s. %s", node.Properties["namespace"],
+				node.Properties["name"], err)
+			continue
+		}
+		if template.Kind != "" {
+			kinds[template.Kind] = struct{}{}
+		}
+		for i, s := range policySeverities {
+			if strings.EqualFold(template.Spec.Severity, s) && i > severity {
+				severity = i
+			}
+		}
+	}
+	if len(kinds) > 0 {
+		node.Properties["templateKinds"] = sortedKeys(kinds)
+	}
+	if severity >= 0 {
+		node.Properties["severity"] = policySeverities[severity]
+	}
+}
+
+// Splits a comma separated annotation, i.e. the standards of the policy.
+func splitAnnotation(annotation string) []string {
+	values := []string{}
+	for _, value := range strings.Split(annotation, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 // BuildNode construct the node for Policy Resources
 func (p PolicyResource) BuildNode() Node {
 	return p.node
@@ -47,6 +135,25 @@ func (p PolicyResource) BuildNode() Node {
 
 // BuildEdges construct the edges for Policy Resources
 func (p PolicyResource) BuildEdges(ns NodeStore) []Edge {
-	//no op for now to implement interface
-	return []Edge{}
+	ret := []Edge{}
+	parent, ok := p.node.Properties["_parentPolicy"].(string)
+	if !ok {
+		return ret
+	}
+
+	// propagatedFrom edge from the replicated policy to the root policy.
+	namespaceName := strings.SplitN(parent, "/", 2)
+	if dest, ok := ns.ByKindNamespaceName["Policy"][namespaceName[0]][namespaceName[1]]; ok {
+		ret = append(ret, Edge{
+			SourceUID:  p.node.UID,
+			DestUID:    dest.UID,
+			EdgeType:   "propagatedFrom",
+			SourceKind: p.node.Properties["kind"].(string),
+			DestKind:   "Policy",
+		})
+	} else {
+		glog.V(4).Infof("For Policy %s/%s, propagatedFrom edge not created as root policy %s not found",
+			p.node.Properties["namespace"], p.node.Properties["name"], parent)
+	}
+	return ret
 }
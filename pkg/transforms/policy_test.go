@@ -19,10 +19,49 @@ import (
 func TestTransformPolicy(t *testing.T) {
 	var p policy.Policy
 	UnmarshalFile("policy.json", &p, t)
-	node := PolicyResourceBuilder(&p).BuildNode()
+	node := PolicyResourceBuilder(&p, testOptions).BuildNode()
 
 	// Test only the fields that exist in policy - the common test will test the other bits
 	AssertEqual("remediationAction", node.Properties["remediationAction"], "enforce", t)
 	AssertEqual("disabled", node.Properties["disabled"], false, t)
 	AssertEqual("numRules", node.Properties["numRules"], 1, t)
 }
+
+func TestTransformRootPolicy(t *testing.T) {
+	var p policy.Policy
+	UnmarshalFile("parent-policy.json", &p, t)
+	node := PolicyResourceBuilder(&p, testOptions).BuildNode()
+
+	AssertEqual("compliantClusters", node.Properties["compliantClusters"], int64(1), t)
+	AssertEqual("noncompliantClusters", node.Properties["noncompliantClusters"], int64(1), t)
+	AssertEqual("pendingClusters", node.Properties["pendingClusters"], int64(1), t)
+	AssertDeepEqual("templateKinds", node.Properties["templateKinds"], []string{"ConfigurationPolicy"}, t)
+	AssertEqual("severity", node.Properties["severity"], "low", t)
+	AssertDeepEqual("standards", node.Properties["standards"], []string{"NIST-CSF", "NIST SP 800-53"}, t)
+	AssertDeepEqual("categories", node.Properties["categories"], []string{"PR.PT Protective Technology"}, t)
+	AssertDeepEqual("controls", node.Properties["controls"], []string{"PR.PT-3 Least Functionality"}, t)
+	AssertEqual("_parentPolicy", node.Properties["_parentPolicy"], nil, t)
+}
+
+func TestPolicyBuildEdges(t *testing.T) {
+	// Build a fake NodeStore with nodes needed to generate edges.
+	nodes := []Node{{
+		UID:        "local-cluster/82825211-afff-48d8-8522-ba1bf1cd1d04",
+		Properties: map[string]interface{}{"kind": "Policy", "namespace": "default", "name": "policy-01"},
+	}}
+	nodeStore := BuildFakeNodeStore(nodes)
+
+	// Build edges from mock resource policy.json, a policy replicated to the cluster namespace.
+	var p policy.Policy
+	UnmarshalFile("policy.json", &p, t)
+	resource := PolicyResourceBuilder(&p, testOptions)
+	edges := resource.BuildEdges(nodeStore)
+
+	AssertEqual("_parentPolicy", resource.BuildNode().Properties["_parentPolicy"], "default/policy-01", t)
+	AssertEqual("Policy edge total: ", len(edges), 1, t)
+	AssertEqual("Policy propagatedFrom", edges[0].EdgeType, EdgeType("propagatedFrom"), t)
+	AssertEqual("Policy propagatedFrom", edges[0].DestUID, "local-cluster/82825211-afff-48d8-8522-ba1bf1cd1d04", t)
+	// The ownedBy edge to the root policy is kept next to the propagatedFrom edge.
+	AssertEqual("OwnerUID", resource.BuildNode().GetMetadata("OwnerUID"),
+		"local-cluster/82825211-afff-48d8-8522-ba1bf1cd1d04", t)
+}
@@ -43,8 +43,8 @@ type PolicyReportResource struct {
 }
 
 // PolicyReportResourceBuilder ...
-func PolicyReportResourceBuilder(pr *PolicyReport) *PolicyReportResource {
-	node := transformCommon(pr) // Start off with the common properties
+func PolicyReportResourceBuilder(pr *PolicyReport, opts Options) *PolicyReportResource {
+	node := transformCommon(pr, opts) // Start off with the common properties
 
 	gvk := pr.GroupVersionKind()
 	node.Properties["kind"] = gvk.Kind
@@ -10,7 +10,7 @@ import (
 func TestTransformPolicyReport(t *testing.T) {
 	var pr PolicyReport
 	UnmarshalFile("policyreport.json", &pr, t)
-	node := PolicyReportResourceBuilder(&pr).BuildNode()
+	node := PolicyReportResourceBuilder(&pr, testOptions).BuildNode()
 
 	// Test unique fields that exist in policy report and are shown in UI - the common test will test the other bits
 	AssertDeepEqual("category Length", len(node.Properties["category"].([]string)), 5, t)
@@ -32,7 +32,7 @@ func TestPolicyReportBuildEdges(t *testing.T) {
 	// Build edges from mock resource policyreport.json
 	var pr PolicyReport
 	UnmarshalFile("policyreport.json", &pr, t)
-	edges := PolicyReportResourceBuilder(&pr).BuildEdges(nodeStore)
+	edges := PolicyReportResourceBuilder(&pr, testOptions).BuildEdges(nodeStore)
 
 	// Validate results
 	AssertEqual("PolicyReport has no edges:", len(edges), 0, t)
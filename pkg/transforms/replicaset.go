@@ -12,16 +12,18 @@ package transforms
 
 import (
 	v1 "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
 )
 
 // ReplicaSetResource ...
 type ReplicaSetResource struct {
-	node Node
+	node    Node
+	PodSpec core.PodSpec // Spec of the pod template, used to build the edges.
 }
 
 // ReplicaSetResourceBuilder ...
-func ReplicaSetResourceBuilder(r *v1.ReplicaSet) *ReplicaSetResource {
-	node := transformCommon(r)         // Start off with the common properties
+func ReplicaSetResourceBuilder(r *v1.ReplicaSet, opts Options) *ReplicaSetResource {
+	node := transformCommon(r, opts)   // Start off with the common properties
 	apiGroupVersion(r.TypeMeta, &node) // add kind, apigroup and version
 	// Extract the properties specific to this type
 	node.Properties["current"] = int64(r.Status.Replicas)
@@ -30,7 +32,7 @@ func ReplicaSetResourceBuilder(r *v1.ReplicaSet) *ReplicaSetResource {
 		node.Properties["desired"] = int64(*r.Spec.Replicas)
 	}
 
-	return &ReplicaSetResource{node: node}
+	return &ReplicaSetResource{node: node, PodSpec: r.Spec.Template.Spec}
 }
 
 // BuildNode construct the node for ReplicaSet Resources
@@ -40,6 +42,5 @@ func (r ReplicaSetResource) BuildNode() Node {
 
 // BuildEdges construct the edges for ReplicaSet Resources
 func (r ReplicaSetResource) BuildEdges(ns NodeStore) []Edge {
-	//no op for now to implement interface
-	return []Edge{}
+	return edgesByPodTemplate(r.node, r.PodSpec, ns)
 }
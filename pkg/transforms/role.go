@@ -0,0 +1,105 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	"sort"
+
+	rbac "k8s.io/api/rbac/v1"
+)
+
+// RoleResource is used for Roles and ClusterRoles.
+type RoleResource struct {
+	node Node
+}
+
+// RoleResourceBuilder ...
+func RoleResourceBuilder(r *rbac.Role, opts Options) *RoleResource {
+	node := transformCommon(r, opts)   // Start off with the common properties
+	apiGroupVersion(r.TypeMeta, &node) // add kind, apigroup and version
+	addRuleProperties(r.Rules, &node)
+	return &RoleResource{node: node}
+}
+
+// ClusterRoleResourceBuilder ...
+func ClusterRoleResourceBuilder(r *rbac.ClusterRole, opts Options) *RoleResource {
+	node := transformCommon(r, opts)   // Start off with the common properties
+	apiGroupVersion(r.TypeMeta, &node) // add kind, apigroup and version
+	addRuleProperties(r.Rules, &node)
+	if r.AggregationRule != nil {
+		node.Properties["aggregated"] = true // Rules are filled by the controller from the selected ClusterRoles.
+	}
+	return &RoleResource{node: node}
+}
+
+// Summarizes the rules as the verbs and resources they allow, so roles can be searched by what they grant.
+// Resources include the API group, i.e. deployments.apps. Non-resource URLs are listed separately.
+// The verbs and resources are flattened across the rules, so the rules are also listed as verb:resource pairs,
+// i.e. get:secrets, to search for a verb allowed on a resource. Wildcards are kept as *, i.e. *:secrets.
+func addRuleProperties(rules []rbac.PolicyRule, node *Node) {
+	verbs := make(map[string]struct{})
+	resources := make(map[string]struct{})
+	pairs := make(map[string]struct{})
+	urls := make(map[string]struct{})
+	for _, rule := range rules {
+		for _, verb := range rule.Verbs {
+			verbs[verb] = struct{}{}
+		}
+		ruleResources := []string{}
+		for _, resource := range rule.Resources {
+			if len(rule.APIGroups) == 0 {
+				ruleResources = append(ruleResources, resource)
+			}
+			for _, group := range rule.APIGroups {
+				if group == "" {
+					ruleResources = append(ruleResources, resource)
+				} else {
+					ruleResources = append(ruleResources, resource+"."+group)
+				}
+			}
+		}
+		for _, resource := range ruleResources {
+			resources[resource] = struct{}{}
+			for _, verb := range rule.Verbs {
+				pairs[verb+":"+resource] = struct{}{}
+			}
+		}
+		for _, url := range rule.NonResourceURLs {
+			urls[url] = struct{}{}
+		}
+	}
+	node.Properties["ruleCount"] = int64(len(rules))
+	if len(pairs) > 0 {
+		node.Properties["rules"] = sortedKeys(pairs)
+	}
+	if len(verbs) > 0 {
+		node.Properties["verbs"] = sortedKeys(verbs)
+	}
+	if len(resources) > 0 {
+		node.Properties["resources"] = sortedKeys(resources)
+	}
+	if len(urls) > 0 {
+		node.Properties["nonResourceURLs"] = sortedKeys(urls)
+	}
+}
+
+// Returns the keys of the set in order, so the properties don't change between resyncs.
+func sortedKeys(set map[string]struct{}) []string {
+	ret := make([]string, 0, len(set))
+	for key := range set {
+		ret = append(ret, key)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// BuildNode construct the node for the Role and ClusterRole Resources
+func (r RoleResource) BuildNode() Node {
+	return r.node
+}
+
+// BuildEdges construct the edges for the Role and ClusterRole Resources
+// The edges to the roles are built from the RoleBindings and ClusterRoleBindings.
+func (r RoleResource) BuildEdges(ns NodeStore) []Edge {
+	return []Edge{}
+}
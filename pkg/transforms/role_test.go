@@ -0,0 +1,40 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/rbac/v1"
+)
+
+func TestTransformClusterRole(t *testing.T) {
+	var r v1.ClusterRole
+	UnmarshalFile("clusterrole.json", &r, t)
+	node := ClusterRoleResourceBuilder(&r, testOptions).BuildNode()
+
+	// Test only the fields that exist in role - the common test will test the other bits
+	AssertEqual("kind", node.Properties["kind"], "ClusterRole", t)
+	AssertEqual("ruleCount", node.Properties["ruleCount"], int64(3), t)
+	AssertDeepEqual("rules", node.Properties["rules"], []string{"get:configmaps", "get:deployments.apps", "get:secrets",
+		"list:configmaps", "list:secrets", "watch:configmaps", "watch:secrets"}, t)
+	AssertDeepEqual("verbs", node.Properties["verbs"], []string{"get", "list", "watch"}, t)
+	AssertDeepEqual("resources", node.Properties["resources"], []string{"configmaps", "deployments.apps", "secrets"}, t)
+	AssertDeepEqual("nonResourceURLs", node.Properties["nonResourceURLs"], []string{"/healthz"}, t)
+}
+
+func TestRoleRulesDontCrossMatch(t *testing.T) {
+	r := v1.Role{Rules: []v1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}},
+		{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"list"}},
+		{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"watch"}},
+	}}
+	r.Name = "test-role"
+	r.Namespace = "default"
+	node := RoleResourceBuilder(&r, testOptions).BuildNode()
+
+	// The flattened verbs and resources would match list on secrets, the rules don't.
+	AssertDeepEqual("verbs", node.Properties["verbs"], []string{"get", "list", "watch"}, t)
+	AssertDeepEqual("resources", node.Properties["resources"], []string{"*.*", "pods", "secrets"}, t)
+	AssertDeepEqual("rules", node.Properties["rules"], []string{"get:secrets", "list:pods", "watch:*.*"}, t)
+}
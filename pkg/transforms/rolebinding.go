@@ -0,0 +1,89 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	rbac "k8s.io/api/rbac/v1"
+)
+
+// RoleBindingResource is used for RoleBindings and ClusterRoleBindings.
+type RoleBindingResource struct {
+	node     Node
+	RoleRef  rbac.RoleRef
+	Subjects []rbac.Subject
+}
+
+// RoleBindingResourceBuilder ...
+func RoleBindingResourceBuilder(r *rbac.RoleBinding, opts Options) *RoleBindingResource {
+	node := transformCommon(r, opts)   // Start off with the common properties
+	apiGroupVersion(r.TypeMeta, &node) // add kind, apigroup and version
+	addBindingProperties(r.RoleRef, r.Subjects, &node)
+	return &RoleBindingResource{node: node, RoleRef: r.RoleRef, Subjects: r.Subjects}
+}
+
+// ClusterRoleBindingResourceBuilder ...
+func ClusterRoleBindingResourceBuilder(r *rbac.ClusterRoleBinding, opts Options) *RoleBindingResource {
+	node := transformCommon(r, opts)   // Start off with the common properties
+	apiGroupVersion(r.TypeMeta, &node) // add kind, apigroup and version
+	addBindingProperties(r.RoleRef, r.Subjects, &node)
+	return &RoleBindingResource{node: node, RoleRef: r.RoleRef, Subjects: r.Subjects}
+}
+
+// Adds the role as Kind/name, and the subjects as Kind/name or Kind/namespace/name for ServiceAccounts.
+func addBindingProperties(roleRef rbac.RoleRef, subjects []rbac.Subject, node *Node) {
+	node.Properties["role"] = roleRef.Kind + "/" + roleRef.Name
+	subjectKinds := make(map[string]struct{})
+	subjectNames := make([]string, 0, len(subjects))
+	for _, s := range subjects {
+		subjectKinds[s.Kind] = struct{}{}
+		if s.Namespace != "" {
+			subjectNames = append(subjectNames, s.Kind+"/"+s.Namespace+"/"+s.Name)
+		} else {
+			subjectNames = append(subjectNames, s.Kind+"/"+s.Name)
+		}
+	}
+	if len(subjects) > 0 {
+		node.Properties["subject"] = subjectNames
+		node.Properties["subjectKind"] = sortedKeys(subjectKinds)
+	}
+}
+
+// BuildNode construct the node for the RoleBinding and ClusterRoleBinding Resources
+func (r RoleBindingResource) BuildNode() Node {
+	return r.node
+}
+
+// BuildEdges construct the edges for the RoleBinding and ClusterRoleBinding Resources
+func (r RoleBindingResource) BuildEdges(ns NodeStore) []Edge {
+	ret := []Edge{}
+	namespace, _ := r.node.Properties["namespace"].(string) // Empty for ClusterRoleBindings.
+	nodeInfo := NodeInfo{
+		Name:      r.node.Properties["name"].(string),
+		NameSpace: namespace,
+		UID:       r.node.UID,
+		EdgeType:  "refersTo",
+		Kind:      r.node.Properties["kind"].(string)}
+
+	// refersTo edges. A RoleBinding can refer to a ClusterRole, to grant its rules in the binding namespace.
+	roleInfo := nodeInfo
+	if r.RoleRef.Kind == "ClusterRole" {
+		roleInfo.NameSpace = "_NONE"
+	}
+	ret = append(ret, edgesByDestinationName(map[string]struct{}{r.RoleRef.Name: {}}, r.RoleRef.Kind, roleInfo, ns,
+		[]string{})...)
+
+	// bindsTo edges. Users and Groups aren't resources in the cluster, so they don't have edges.
+	for _, s := range r.Subjects {
+		if s.Kind != rbac.ServiceAccountKind {
+			continue
+		}
+		subjectInfo := nodeInfo
+		subjectInfo.EdgeType = "bindsTo"
+		if s.Namespace != "" {
+			subjectInfo.NameSpace = s.Namespace
+		}
+		ret = append(ret, edgesByDestinationName(map[string]struct{}{s.Name: {}}, "ServiceAccount", subjectInfo, ns,
+			[]string{})...)
+	}
+	return ret
+}
@@ -0,0 +1,44 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/rbac/v1"
+)
+
+func TestTransformRoleBinding(t *testing.T) {
+	var r v1.RoleBinding
+	UnmarshalFile("rolebinding.json", &r, t)
+	node := RoleBindingResourceBuilder(&r, testOptions).BuildNode()
+
+	// Test only the fields that exist in rolebinding - the common test will test the other bits
+	AssertEqual("role", node.Properties["role"], "ClusterRole/test-secret-reader", t)
+	AssertDeepEqual("subject", node.Properties["subject"], []string{"ServiceAccount/default/test-sa", "User/alice"}, t)
+	AssertDeepEqual("subjectKind", node.Properties["subjectKind"], []string{"ServiceAccount", "User"}, t)
+}
+
+func TestRoleBindingBuildEdges(t *testing.T) {
+	// Build a fake NodeStore with nodes needed to generate edges.
+	nodes := []Node{{
+		UID:        "uuid-123-clusterrole",
+		Properties: map[string]interface{}{"kind": "ClusterRole", "name": "test-secret-reader"},
+	}, {
+		UID:        "uuid-123-serviceaccount",
+		Properties: map[string]interface{}{"kind": "ServiceAccount", "namespace": "default", "name": "test-sa"},
+	}}
+	nodeStore := BuildFakeNodeStore(nodes)
+
+	// Build edges from mock resource rolebinding.json
+	var r v1.RoleBinding
+	UnmarshalFile("rolebinding.json", &r, t)
+	edges := RoleBindingResourceBuilder(&r, testOptions).BuildEdges(nodeStore)
+
+	// Verify created edges. The User subject doesn't have an edge.
+	AssertEqual("RoleBinding edge total: ", len(edges), 2, t)
+	AssertEqual("RoleBinding refersTo", edges[0].EdgeType, EdgeType("refersTo"), t)
+	AssertEqual("RoleBinding refersTo", edges[0].DestUID, "uuid-123-clusterrole", t)
+	AssertEqual("RoleBinding bindsTo", edges[1].EdgeType, EdgeType("bindsTo"), t)
+	AssertEqual("RoleBinding bindsTo", edges[1].DestUID, "uuid-123-serviceaccount", t)
+}
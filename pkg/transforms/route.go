@@ -0,0 +1,119 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// RouteResource ...
+type RouteResource struct {
+	node Node
+	Spec RouteSpec
+}
+
+// OpenShift Route (route.openshift.io/v1). Defined here because the vendored openshift/api
+// predates spec.tls.externalCertificate.
+type Route struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              RouteSpec   `json:"spec"`
+	Status            RouteStatus `json:"status,omitempty"`
+}
+
+type RouteSpec struct {
+	Host              string                 `json:"host,omitempty"`
+	Path              string                 `json:"path,omitempty"`
+	To                RouteTargetReference   `json:"to"`
+	AlternateBackends []RouteTargetReference `json:"alternateBackends,omitempty"`
+	Port              *RoutePort             `json:"port,omitempty"`
+	TLS               *RouteTLSConfig        `json:"tls,omitempty"`
+}
+
+type RouteTargetReference struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Weight *int32 `json:"weight,omitempty"`
+}
+
+type RoutePort struct {
+	TargetPort intstr.IntOrString `json:"targetPort"`
+}
+
+type RouteTLSConfig struct {
+	Termination                   string                `json:"termination"`
+	InsecureEdgeTerminationPolicy string                `json:"insecureEdgeTerminationPolicy,omitempty"`
+	ExternalCertificate           *RouteSecretReference `json:"externalCertificate,omitempty"`
+}
+
+type RouteSecretReference struct {
+	Name string `json:"name"`
+}
+
+type RouteStatus struct {
+	Ingress []RouteIngress `json:"ingress,omitempty"`
+}
+
+type RouteIngress struct {
+	Host       string `json:"host,omitempty"`
+	RouterName string `json:"routerName,omitempty"`
+}
+
+// RouteResourceBuilder ...
+func RouteResourceBuilder(r *Route, opts Options) *RouteResource {
+	node := transformCommon(r, opts)   // Start off with the common properties
+	apiGroupVersion(r.TypeMeta, &node) // add kind, apigroup and version
+	// Extract the properties specific to this type
+	node.Properties["host"] = r.Spec.Host
+	if r.Spec.Host == "" && len(r.Status.Ingress) > 0 {
+		node.Properties["host"] = r.Status.Ingress[0].Host // Generated by the router.
+	}
+	if r.Spec.Path != "" {
+		node.Properties["path"] = r.Spec.Path
+	}
+	if r.Spec.To.Kind == "Service" || r.Spec.To.Kind == "" {
+		node.Properties["service"] = r.Spec.To.Name
+	}
+	if r.Spec.Port != nil {
+		node.Properties["port"] = r.Spec.Port.TargetPort.String()
+	}
+	if r.Spec.TLS != nil {
+		node.Properties["tlsTermination"] = r.Spec.TLS.Termination
+	}
+	return &RouteResource{node: node, Spec: r.Spec}
+}
+
+// BuildNode construct the node for the Route Resources
+func (r RouteResource) BuildNode() Node {
+	return r.node
+}
+
+// BuildEdges construct the edges for the Route Resources
+func (r RouteResource) BuildEdges(ns NodeStore) []Edge {
+	ret := []Edge{}
+	nodeInfo := NodeInfo{
+		Name:      r.node.Properties["name"].(string),
+		NameSpace: r.node.Properties["namespace"].(string),
+		UID:       r.node.UID,
+		EdgeType:  "routesTo",
+		Kind:      r.node.Properties["kind"].(string)}
+
+	// routesTo edges, including the alternate backends used to split the traffic.
+	services := make(map[string]struct{})
+	for _, target := range append([]RouteTargetReference{r.Spec.To}, r.Spec.AlternateBackends...) {
+		if (target.Kind == "Service" || target.Kind == "") && target.Name != "" {
+			services[target.Name] = struct{}{}
+		}
+	}
+	ret = append(ret, edgesByDestinationName(services, "Service", nodeInfo, ns, []string{})...)
+
+	// uses edges
+	if r.Spec.TLS != nil && r.Spec.TLS.ExternalCertificate != nil && r.Spec.TLS.ExternalCertificate.Name != "" {
+		nodeInfo.EdgeType = "uses"
+		secrets := map[string]struct{}{r.Spec.TLS.ExternalCertificate.Name: {}}
+		ret = append(ret, edgesByDestinationName(secrets, "Secret", nodeInfo, ns, []string{})...)
+	}
+
+	return ret
+}
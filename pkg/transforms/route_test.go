@@ -0,0 +1,44 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package transforms
+
+import (
+	"testing"
+)
+
+func TestTransformRoute(t *testing.T) {
+	var r Route
+	UnmarshalFile("route.json", &r, t)
+	node := RouteResourceBuilder(&r, testOptions).BuildNode()
+
+	// Test only the fields that exist in route - the common test will test the other bits
+	AssertEqual("host", node.Properties["host"], "app.apps.example.com", t)
+	AssertEqual("path", node.Properties["path"], "/api", t)
+	AssertEqual("service", node.Properties["service"], "test-service", t)
+	AssertEqual("port", node.Properties["port"], "http", t)
+	AssertEqual("tlsTermination", node.Properties["tlsTermination"], "edge", t)
+}
+
+func TestRouteBuildEdges(t *testing.T) {
+	// Build a fake NodeStore with nodes needed to generate edges.
+	nodes := []Node{{
+		UID:        "uuid-123-service",
+		Properties: map[string]interface{}{"kind": "Service", "namespace": "default", "name": "test-service"},
+	}, {
+		UID:        "uuid-123-secret",
+		Properties: map[string]interface{}{"kind": "Secret", "namespace": "default", "name": "test-tls-secret"},
+	}}
+	nodeStore := BuildFakeNodeStore(nodes)
+
+	// Build edges from mock resource route.json
+	var r Route
+	UnmarshalFile("route.json", &r, t)
+	edges := RouteResourceBuilder(&r, testOptions).BuildEdges(nodeStore)
+
+	// Verify created edges.
+	AssertEqual("Route edge total: ", len(edges), 2, t)
+	AssertEqual("Route routesTo", edges[0].EdgeType, EdgeType("routesTo"), t)
+	AssertEqual("Route routesTo", edges[0].DestUID, "uuid-123-service", t)
+	AssertEqual("Route uses", edges[1].EdgeType, EdgeType("uses"), t)
+	AssertEqual("Route uses", edges[1].DestUID, "uuid-123-secret", t)
+}
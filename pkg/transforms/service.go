@@ -15,6 +15,7 @@ import (
 	"strings"
 
 	v1 "k8s.io/api/core/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
 )
 
 // ServiceResource ...
@@ -24,8 +25,8 @@ type ServiceResource struct {
 }
 
 // ServiceResourceBuilder ...
-func ServiceResourceBuilder(s *v1.Service) *ServiceResource {
-	node := transformCommon(s) // Start off with the common properties
+func ServiceResourceBuilder(s *v1.Service, opts Options) *ServiceResource {
+	node := transformCommon(s, opts) // Start off with the common properties
 	var ports []string
 	apiGroupVersion(s.TypeMeta, &node) // add kind, apigroup and version
 	// Extract the properties specific to this type
@@ -57,12 +58,11 @@ func (s ServiceResource) BuildNode() Node {
 func (s ServiceResource) BuildEdges(ns NodeStore) []Edge {
 	serviceSelector := s.Spec.Selector
 
-	if serviceSelector == nil {
+	// A service without selector doesn't select any pods, its endpoints are managed externally.
+	if len(serviceSelector) == 0 {
 		return []Edge{}
 	}
 
-	// Future: Match a pod in another namespace , but config will be different in those cases.
-	pods := ns.ByKindNamespaceName["Pod"][s.node.Properties["namespace"].(string)]
 	nodeInfo := NodeInfo{
 		Name:      s.node.Properties["name"].(string),
 		NameSpace: s.node.Properties["namespace"].(string),
@@ -70,25 +70,26 @@ func (s ServiceResource) BuildEdges(ns NodeStore) []Edge {
 		EdgeType:  "usedBy",
 		Kind:      s.node.Properties["kind"].(string)}
 
-	// Inner function to match the service and pod labels
-	match := func(podLabels, serviceSelector map[string]string) bool {
-		for selKey, selVal := range serviceSelector {
-			if podVal, ok := podLabels[selKey]; podVal != selVal || !ok {
-				return false
-			}
-		}
-		return true
-	}
-
 	// usedBy edges
+	// Future: Match a pod in another namespace , but config will be different in those cases.
 	ret := []Edge{}
-	for _, p := range pods {
-		if podLabels, ok := p.Properties["label"].(map[string]string); ok {
-			if match(podLabels, serviceSelector) {
-				ret = append(ret, edgesByOwner(p.UID, ns, nodeInfo, []string{})...)
-			}
-		}
+	for _, p := range podsBySelector(ns, nodeInfo.NameSpace, k8slabels.SelectorFromSet(serviceSelector)) {
+		ret = append(ret, edgesByOwner(p.UID, ns, nodeInfo, []string{})...)
 	}
 
 	return ret
 }
+
+// Returns the pods in the namespace whose labels match the selector.
+// Used to build the edges of resources that select pods by label (i.e. Service, NetworkPolicy).
+func podsBySelector(ns NodeStore, namespace string, selector k8slabels.Selector) []Node {
+	ret := []Node{}
+	for _, p := range ns.ByKindNamespaceName["Pod"][namespace] {
+		// Pods without labels don't have the label property, but are still matched by an empty selector.
+		podLabels, _ := p.Properties["label"].(map[string]string)
+		if selector.Matches(k8slabels.Set(podLabels)) {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
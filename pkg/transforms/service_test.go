@@ -18,7 +18,7 @@ import (
 func TestTransformService(t *testing.T) {
 	var s v1.Service
 	UnmarshalFile("service.json", &s, t)
-	node := ServiceResourceBuilder(&s).BuildNode()
+	node := ServiceResourceBuilder(&s, testOptions).BuildNode()
 
 	AssertEqual("kind", node.Properties["kind"], "Service", t)
 }
@@ -35,10 +35,17 @@ func TestServiceBuildEdges(t *testing.T) {
 	// Build edges from mock resource cronjob.json
 	var svc v1.Service
 	UnmarshalFile("service.json", &svc, t)
-	edges := ServiceResourceBuilder(&svc).BuildEdges(nodeStore)
+	edges := ServiceResourceBuilder(&svc, testOptions).BuildEdges(nodeStore)
 
 	// Validate results
 	AssertEqual("Service has no edges:", len(edges), 1, t)
 
 	AssertEqual("Service usedBy: ", edges[0].DestKind, "Pod", t)
+
+	// A service with an empty selector doesn't select any pods, even the pods without labels.
+	delete(nodes[0].Properties, "label")
+	svc.Spec.Selector = map[string]string{}
+	edges = ServiceResourceBuilder(&svc, testOptions).BuildEdges(BuildFakeNodeStore(nodes))
+
+	AssertEqual("Service edge total: ", len(edges), 0, t)
 }
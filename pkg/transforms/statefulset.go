@@ -11,17 +11,22 @@ Copyright (c) 2020 Red Hat, Inc.
 package transforms
 
 import (
+	"strings"
+
 	v1 "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
 )
 
 // StatefulSetResource ...
 type StatefulSetResource struct {
-	node Node
+	node                 Node
+	PodSpec              core.PodSpec // Spec of the pod template, used to build the edges.
+	VolumeClaimTemplates []string     // Names of the volumeClaimTemplates.
 }
 
 // StatefulSetResourceBuilder ...
-func StatefulSetResourceBuilder(s *v1.StatefulSet) *StatefulSetResource {
-	node := transformCommon(s)         // Start off with the common properties
+func StatefulSetResourceBuilder(s *v1.StatefulSet, opts Options) *StatefulSetResource {
+	node := transformCommon(s, opts)   // Start off with the common properties
 	apiGroupVersion(s.TypeMeta, &node) // add kind, apigroup and version
 	// Extract the properties specific to this type
 	node.Properties["current"] = int64(s.Status.Replicas)
@@ -30,7 +35,11 @@ func StatefulSetResourceBuilder(s *v1.StatefulSet) *StatefulSetResource {
 		node.Properties["desired"] = int64(*s.Spec.Replicas)
 	}
 
-	return &StatefulSetResource{node: node}
+	claimTemplates := make([]string, 0, len(s.Spec.VolumeClaimTemplates))
+	for _, template := range s.Spec.VolumeClaimTemplates {
+		claimTemplates = append(claimTemplates, template.Name)
+	}
+	return &StatefulSetResource{node: node, PodSpec: s.Spec.Template.Spec, VolumeClaimTemplates: claimTemplates}
 }
 
 // BuildNode construct the node for the StatefulSet Resources
@@ -40,6 +49,37 @@ func (s StatefulSetResource) BuildNode() Node {
 
 // BuildEdges construct the edges for the StatefulSet Resources
 func (s StatefulSetResource) BuildEdges(ns NodeStore) []Edge {
-	//no op for now to implement interface
-	return []Edge{}
+	return edgesByPodTemplate(s.node, s.PodSpec, ns, s.volumeClaims(ns)...)
+}
+
+// Returns the names of the claims created from the volumeClaimTemplates, named <template>-<statefulset>-<ordinal>.
+// Claims are kept when the StatefulSet is scaled down, so they are matched by name instead of using the replicas.
+func (s StatefulSetResource) volumeClaims(ns NodeStore) []string {
+	ret := []string{}
+	if len(s.VolumeClaimTemplates) == 0 {
+		return ret
+	}
+	namespace, _ := s.node.Properties["namespace"].(string)
+	for name := range ns.ByKindNamespaceName["PersistentVolumeClaim"][namespace] {
+		for _, template := range s.VolumeClaimTemplates {
+			prefix := template + "-" + s.node.Properties["name"].(string) + "-"
+			if ordinal := strings.TrimPrefix(name, prefix); ordinal != name && isOrdinal(ordinal) {
+				ret = append(ret, name)
+			}
+		}
+	}
+	return ret
+}
+
+// Returns true if the string is a StatefulSet pod ordinal, i.e. 0 or 12
+func isOrdinal(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
 }
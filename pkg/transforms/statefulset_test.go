@@ -14,12 +14,14 @@ import (
 	"testing"
 
 	v1 "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestTransformStatefulSet(t *testing.T) {
 	var s v1.StatefulSet
 	UnmarshalFile("statefulset.json", &s, t)
-	node := StatefulSetResourceBuilder(&s).BuildNode()
+	node := StatefulSetResourceBuilder(&s, testOptions).BuildNode()
 
 	// Test only the fields that exist in stateful set - the common test will test the other bits
 	AssertEqual("current", node.Properties["current"], int64(1), t)
@@ -34,8 +36,36 @@ func TestStatefulSetBuildEdges(t *testing.T) {
 	// Build edges from mock resource statefulset.json
 	var ss v1.StatefulSet
 	UnmarshalFile("statefulset.json", &ss, t)
-	edges := StatefulSetResourceBuilder(&ss).BuildEdges(nodeStore)
+	edges := StatefulSetResourceBuilder(&ss, testOptions).BuildEdges(nodeStore)
 
 	// Validate results
 	AssertEqual("StatefulSet has no edges:", len(edges), 0, t)
 }
+
+func TestStatefulSetBuildEdgesPodTemplate(t *testing.T) {
+	// Build a fake NodeStore with nodes needed to generate edges.
+	nodes := []Node{{
+		UID:        "uuid-123-secret",
+		Properties: map[string]interface{}{"kind": "Secret", "namespace": "default", "name": "release-fake-set-foo-secrets"},
+	}, {
+		UID: "uuid-123-pvc",
+		Properties: map[string]interface{}{"kind": "PersistentVolumeClaim", "namespace": "default",
+			"name": "data-release-fake-set-foo-0", "volumeName": "test-pv"},
+	}, {
+		UID:        "uuid-123-pv",
+		Properties: map[string]interface{}{"kind": "PersistentVolume", "name": "test-pv"},
+	}}
+	nodeStore := BuildFakeNodeStore(nodes)
+
+	var ss v1.StatefulSet
+	UnmarshalFile("statefulset.json", &ss, t)
+	ss.Namespace = "default"
+	ss.Spec.VolumeClaimTemplates = []core.PersistentVolumeClaim{{ObjectMeta: metav1.ObjectMeta{Name: "data"}}}
+	edges := StatefulSetResourceBuilder(&ss, testOptions).BuildEdges(nodeStore)
+
+	// The secret from the pod template, and the claim from the volumeClaimTemplates with its volume.
+	AssertEqual("StatefulSet edge total:", len(edges), 3, t)
+	AssertEqual("StatefulSet attachedTo", edges[0].DestKind, "Secret", t)
+	AssertEqual("StatefulSet attachedTo", edges[1].DestKind, "PersistentVolumeClaim", t)
+	AssertEqual("StatefulSet attachedTo", edges[2].DestKind, "PersistentVolume", t)
+}
@@ -24,8 +24,8 @@ type SubscriptionResource struct {
 }
 
 // SubscriptionResourceBuilder ...
-func SubscriptionResourceBuilder(s *app.Subscription) *SubscriptionResource {
-	node := transformCommon(s)
+func SubscriptionResourceBuilder(s *app.Subscription, opts Options) *SubscriptionResource {
+	node := transformCommon(s, opts)
 	apiGroupVersion(s.TypeMeta, &node) // add kind, apigroup and version
 	// Extract the properties specific to this type
 	if s.Spec.Package != "" {
@@ -103,12 +103,16 @@ func (s SubscriptionResource) BuildEdges(ns NodeStore) []Edge {
 		ret = append(ret, edgesByDestinationName(channelMap, "Channel", nodeInfo, ns, []string{})...)
 	}
 	// refersTo edges
-	// Builds edges between subscription and placement rules
+	// Builds edges between subscription and placement rules or placements
 	if s.Spec.Placement != nil && s.Spec.Placement.PlacementRef != nil && s.Spec.Placement.PlacementRef.Name != "" {
 		nodeInfo.EdgeType = "refersTo"
+		placementKind := "PlacementRule" // Default when the kind isn't set.
+		if s.Spec.Placement.PlacementRef.Kind == "Placement" {
+			placementKind = "Placement"
+		}
 		placementRuleMap := make(map[string]struct{})
 		placementRuleMap[s.Spec.Placement.PlacementRef.Name] = struct{}{}
-		ret = append(ret, edgesByDestinationName(placementRuleMap, "PlacementRule", nodeInfo, ns, []string{})...)
+		ret = append(ret, edgesByDestinationName(placementRuleMap, placementKind, nodeInfo, ns, []string{})...)
 	}
 	//subscribesTo edges
 	if len(s.annotations["apps.open-cluster-management.io/deployables"]) > 0 {
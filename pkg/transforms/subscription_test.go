@@ -19,7 +19,7 @@ import (
 func TestTransformSubscription(t *testing.T) {
 	var s v1.Subscription
 	UnmarshalFile("subscription.json", &s, t)
-	node := SubscriptionResourceBuilder(&s).BuildNode()
+	node := SubscriptionResourceBuilder(&s, testOptions).BuildNode()
 
 	// Test only the fields that exist in subscription - the common test will test the other bits
 	AssertEqual("kind", node.Properties["kind"], "Subscription", t)
@@ -31,7 +31,7 @@ func TestTransformSubscription(t *testing.T) {
 func TestTransformSubscriptionWithTimeWindow(t *testing.T) {
 	var s v1.Subscription
 	UnmarshalFile("subscription2.json", &s, t)
-	node := SubscriptionResourceBuilder(&s).BuildNode()
+	node := SubscriptionResourceBuilder(&s, testOptions).BuildNode()
 
 	// Test optional fields that exist in subscription - the common test will test the other bits
 	AssertEqual("timeWindow", node.Properties["timeWindow"], "active", t)
@@ -43,8 +43,31 @@ func TestTransformSubscriptionWithTimeWindow(t *testing.T) {
 func TestTransformSubscriptionWithLocalPlacement(t *testing.T) {
 	var s v1.Subscription
 	UnmarshalFile("subscription3.json", &s, t)
-	node := SubscriptionResourceBuilder(&s).BuildNode()
+	node := SubscriptionResourceBuilder(&s, testOptions).BuildNode()
 
 	// Test optional fields that exist in subscription - the common test will test the other bits
 	AssertEqual("localPlacement", node.Properties["localPlacement"], true, t)
 }
+
+func TestSubscriptionBuildEdgesPlacement(t *testing.T) {
+	// Build a fake NodeStore with nodes needed to generate edges.
+	nodes := []Node{{
+		UID:        "uuid-123-placementrule",
+		Properties: map[string]interface{}{"kind": "PlacementRule", "namespace": "default", "name": "test-placementrule"},
+	}, {
+		UID:        "uuid-123-placement",
+		Properties: map[string]interface{}{"kind": "Placement", "namespace": "default", "name": "test-placement"},
+	}}
+	nodeStore := BuildFakeNodeStore(nodes)
+
+	var s v1.Subscription
+	UnmarshalFile("subscription.json", &s, t)
+	edges := SubscriptionResourceBuilder(&s, testOptions).BuildEdges(nodeStore)
+	AssertEqual("Subscription refersTo PlacementRule", edges[len(edges)-1].DestUID, "uuid-123-placementrule", t)
+
+	s.Spec.Placement.PlacementRef.Kind = "Placement"
+	s.Spec.Placement.PlacementRef.Name = "test-placement"
+	edges = SubscriptionResourceBuilder(&s, testOptions).BuildEdges(nodeStore)
+	AssertEqual("Subscription refersTo Placement", edges[len(edges)-1].EdgeType, EdgeType("refersTo"), t)
+	AssertEqual("Subscription refersTo Placement", edges[len(edges)-1].DestUID, "uuid-123-placement", t)
+}
@@ -74,6 +74,8 @@ func BuildFakeNodeStore(nodes []Node) NodeStore {
 	store := NodeStore{
 		ByUID:               byUID,
 		ByKindNamespaceName: byKindNameNamespace,
+		PodsByNodeName:      IndexPodsByNodeName(byUID),
+		Computed:            make(map[string]map[string]interface{}),
 	}
 
 	return store
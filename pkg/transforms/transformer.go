@@ -14,6 +14,7 @@ import (
 	"runtime/debug"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/golang/glog"
 	ocpapp "github.com/openshift/api/apps/v1"
@@ -21,12 +22,19 @@ import (
 	klusterletaddon "github.com/stolostron/klusterlet-addon-controller/pkg/apis/agent/v1"
 	appDeployable "github.com/stolostron/multicloud-operators-deployable/pkg/apis/apps/v1"
 	rule "github.com/stolostron/multicloud-operators-placementrule/pkg/apis/apps/v1"
+	"github.com/stolostron/search-collector/pkg/queue"
 	apps "k8s.io/api/apps/v1"
+	autoscaling "k8s.io/api/autoscaling/v2"
 	batch "k8s.io/api/batch/v1"
 	batchBeta "k8s.io/api/batch/v1beta1"
 	core "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbac "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	cluster "open-cluster-management.io/api/cluster/v1beta1"
 	acmapp "open-cluster-management.io/multicloud-operators-channel/pkg/apis/apps/v1"
 	appHelmRelease "open-cluster-management.io/multicloud-operators-subscription/pkg/apis/apps/helmrelease/v1"
 	subscription "open-cluster-management.io/multicloud-operators-subscription/pkg/apis/apps/v1"
@@ -75,9 +83,10 @@ func (n Node) GetMetadata(md string) string {
 // this version with other versions that the sender may already have.
 type NodeEvent struct {
 	Node
-	ComputeEdges func(ns NodeStore) []Edge
-	Time         int64
-	Operation    Operation
+	ComputeEdges   func(ns NodeStore) []Edge
+	Time           int64
+	Operation      Operation
+	SyntheticNodes []Node // Nodes that aren't k8s resources, kept by the reconciler while this node references them.
 }
 
 type Deletion struct {
@@ -95,6 +104,9 @@ func NewNodeEvent(event *Event, trans Transform, resourceString string) NodeEven
 	ne.ResourceString = resourceString
 	// Search v-2 , types is expected part of properties
 	ne.Node.Properties["kind_plural"] = resourceString
+	if s, ok := trans.(SyntheticNodeBuilder); ok {
+		ne.SyntheticNodes = s.BuildSyntheticNodes()
+	}
 	return ne
 }
 
@@ -123,19 +135,71 @@ type Transform interface {
 	BuildEdges(ns NodeStore) []Edge
 }
 
+// Implemented by the transforms that also build nodes for things that aren't k8s resources, like the
+// ContainerImages used by a Pod. These nodes don't have a resource of their own, so the reconciler adds them with
+// the node that references them, and deletes them when no node references them anymore.
+type SyntheticNodeBuilder interface {
+	BuildSyntheticNodes() []Node
+}
+
 // Object that handles transformation of k8s objects.
 // To use, create one, call Start(), and begin passing in objects.
 type Transformer struct {
-	Input  chan *Event    // Put your k8s resources and corresponding times in here.
-	Output chan NodeEvent // And receive your aggregator-ready nodes (and times) from here.
+	Input   chan *Event    // Put your k8s resources and corresponding times in here.
+	Output  chan NodeEvent // And receive your aggregator-ready nodes (and times) from here.
+	Options Options        // Options used to build the nodes.
+	// Optional bounded queue that feeds Input, with a lane for each resource. Use Enqueue to send the events.
+	Queue *queue.Queue[*Event]
+}
+
+// Sends the event to the transformer. With a Queue, blocks while the lane for the resource is full.
+func (t Transformer) Enqueue(event *Event) {
+	if t.Queue == nil {
+		t.Input <- event
+		return
+	}
+	key := schema.GroupResource{Group: event.Resource.GroupVersionKind().Group, Resource: event.ResourceString}
+	t.Queue.Put(key.String(), event)
+}
+
+// Options used to build the nodes.
+type Options struct {
+	ClusterName   string // Prefix for the UIDs of the nodes.
+	DeployedInHub bool   // Marks the nodes with _hubClusterResource.
+	// When set, generic resources are built without the additional properties from the transform config.
+	// Set by the resource budget monitor to shed load. Can be nil.
+	ReducedProperties *atomic.Bool
+}
+
+// Returns true if the additional properties of generic resources shouldn't be extracted.
+func (o Options) reducedProperties() bool {
+	return o.ReducedProperties != nil && o.ReducedProperties.Load()
 }
 
 var (
-	NonNSResourceMap map[string]struct{} //store non-namespaced resources in this map
+	NonNSResourceMap map[string]map[string]struct{} // Kinds of the non-namespaced resources, by cluster name.
 	NonNSResMapMutex = sync.RWMutex{}
 )
 
-func NewTransformer(inputChan chan *Event, outputChan chan NodeEvent, numRoutines int) Transformer {
+// Replaces the kinds of the non-namespaced resources in a cluster with the ones from the last discovery.
+func SetNonNSResources(clusterName string, kinds map[string]struct{}) {
+	NonNSResMapMutex.Lock()
+	defer NonNSResMapMutex.Unlock()
+	if NonNSResourceMap == nil {
+		NonNSResourceMap = make(map[string]map[string]struct{})
+	}
+	NonNSResourceMap[clusterName] = kinds
+}
+
+// Returns true if the kind is a non-namespaced resource in the cluster.
+func isNonNamespaced(clusterName, kind string) bool {
+	NonNSResMapMutex.RLock()
+	defer NonNSResMapMutex.RUnlock()
+	_, ok := NonNSResourceMap[clusterName][kind]
+	return ok
+}
+
+func NewTransformer(inputChan chan *Event, outputChan chan NodeEvent, numRoutines int, opts Options) Transformer {
 	glog.Info("Transformer started")
 	nr := numRoutines
 	if numRoutines < 1 {
@@ -145,11 +209,12 @@ func NewTransformer(inputChan chan *Event, outputChan chan NodeEvent, numRoutine
 
 	// start numRoutines threads to handle transformation.
 	for i := 0; i < nr; i++ {
-		go TransformRoutine(inputChan, outputChan)
+		go TransformRoutine(inputChan, outputChan, opts)
 	}
 	return Transformer{
-		Input:  inputChan,
-		Output: outputChan,
+		Input:   inputChan,
+		Output:  outputChan,
+		Options: opts,
 	}
 
 }
@@ -158,8 +223,8 @@ func NewTransformer(inputChan chan *Event, outputChan chan NodeEvent, numRoutine
 // If anything goes wrong in here that requires you to skip the current resource, call panic()
 // and the routine will be spun back up by handleRoutineExit and the bad resource won't be in there
 // because it was already taken out by the previous run.
-func TransformRoutine(input chan *Event, output chan NodeEvent) {
-	defer handleRoutineExit(input, output)
+func TransformRoutine(input chan *Event, output chan NodeEvent, opts Options) {
+	defer handleRoutineExit(input, output, opts)
 	glog.Info("Starting transformer routine")
 
 	for {
@@ -187,7 +252,7 @@ func TransformRoutine(input chan *Event, output chan NodeEvent) {
 			if err != nil {
 				panic(err) // Will be caught by handleRoutineExit
 			}
-			trans = ApplicationResourceBuilder(&typedResource)
+			trans = ApplicationResourceBuilder(&typedResource, opts)
 
 		case [2]string{"Application", "argoproj.io"}:
 			typedResource := ArgoApplication{}
@@ -196,7 +261,16 @@ func TransformRoutine(input chan *Event, output chan NodeEvent) {
 			if err != nil {
 				panic(err) // Will be caught by handleRoutineExit
 			}
-			trans = ArgoApplicationResourceBuilder(&typedResource)
+			trans = ArgoApplicationResourceBuilder(&typedResource, opts)
+
+		case [2]string{"ApplicationSet", "argoproj.io"}:
+			typedResource := ArgoApplicationSet{}
+			err := runtime.DefaultUnstructuredConverter.
+				FromUnstructured(event.Resource.UnstructuredContent(), &typedResource)
+			if err != nil {
+				panic(err) // Will be caught by handleRoutineExit
+			}
+			trans = ArgoApplicationSetResourceBuilder(&typedResource, opts)
 
 		case [2]string{"Channel", APPS_OPEN_CLUSTER_MANAGEMENT_IO}:
 			typedResource := acmapp.Channel{}
@@ -205,7 +279,25 @@ func TransformRoutine(input chan *Event, output chan NodeEvent) {
 			if err != nil {
 				panic(err) // Will be caught by handleRoutineExit
 			}
-			trans = ChannelResourceBuilder(&typedResource)
+			trans = ChannelResourceBuilder(&typedResource, opts)
+
+		case [2]string{"ClusterRole", "rbac.authorization.k8s.io"}:
+			typedResource := rbac.ClusterRole{}
+			err := runtime.DefaultUnstructuredConverter.
+				FromUnstructured(event.Resource.UnstructuredContent(), &typedResource)
+			if err != nil {
+				panic(err) // Will be caught by handleRoutineExit
+			}
+			trans = ClusterRoleResourceBuilder(&typedResource, opts)
+
+		case [2]string{"ClusterRoleBinding", "rbac.authorization.k8s.io"}:
+			typedResource := rbac.ClusterRoleBinding{}
+			err := runtime.DefaultUnstructuredConverter.
+				FromUnstructured(event.Resource.UnstructuredContent(), &typedResource)
+			if err != nil {
+				panic(err) // Will be caught by handleRoutineExit
+			}
+			trans = ClusterRoleBindingResourceBuilder(&typedResource, opts)
 
 		case [2]string{"CronJob", "batch"}:
 			typedResource := batchBeta.CronJob{}
@@ -214,7 +306,7 @@ func TransformRoutine(input chan *Event, output chan NodeEvent) {
 			if err != nil {
 				panic(err) // Will be caught by handleRoutineExit
 			}
-			trans = CronJobResourceBuilder(&typedResource)
+			trans = CronJobResourceBuilder(&typedResource, opts)
 
 		case [2]string{"DaemonSet", "extensions"},
 			[2]string{"DaemonSet", "apps"}:
@@ -224,7 +316,7 @@ func TransformRoutine(input chan *Event, output chan NodeEvent) {
 			if err != nil {
 				panic(err) // Will be caught by handleRoutineExit
 			}
-			trans = DaemonSetResourceBuilder(&typedResource)
+			trans = DaemonSetResourceBuilder(&typedResource, opts)
 
 		case [2]string{"Deployable", APPS_OPEN_CLUSTER_MANAGEMENT_IO}:
 			typedResource := appDeployable.Deployable{}
@@ -233,7 +325,7 @@ func TransformRoutine(input chan *Event, output chan NodeEvent) {
 			if err != nil {
 				panic(err) // Will be caught by handleRoutineExit
 			}
-			trans = AppDeployableResourceBuilder(&typedResource)
+			trans = AppDeployableResourceBuilder(&typedResource, opts)
 
 		case [2]string{"Deployment", "apps"},
 			[2]string{"Deployment", "extensions"}:
@@ -243,7 +335,7 @@ func TransformRoutine(input chan *Event, output chan NodeEvent) {
 			if err != nil {
 				panic(err) // Will be caught by handleRoutineExit
 			}
-			trans = DeploymentResourceBuilder(&typedResource)
+			trans = DeploymentResourceBuilder(&typedResource, opts)
 
 			//This is an ocp specific resource
 		case [2]string{"DeploymentConfig", "apps.openshift.io"}:
@@ -253,7 +345,19 @@ func TransformRoutine(input chan *Event, output chan NodeEvent) {
 			if err != nil {
 				panic(err) // Will be caught by handleRoutineExit
 			}
-			trans = DeploymentConfigResourceBuilder(&typedResource)
+			trans = DeploymentConfigResourceBuilder(&typedResource, opts)
+
+		case [2]string{"GitRepository", "source.toolkit.fluxcd.io"},
+			[2]string{"HelmRepository", "source.toolkit.fluxcd.io"},
+			[2]string{"OCIRepository", "source.toolkit.fluxcd.io"},
+			[2]string{"Bucket", "source.toolkit.fluxcd.io"}:
+			typedResource := FluxSource{}
+			err := runtime.DefaultUnstructuredConverter.
+				FromUnstructured(event.Resource.UnstructuredContent(), &typedResource)
+			if err != nil {
+				panic(err) // Will be caught by handleRoutineExit
+			}
+			trans = FluxSourceResourceBuilder(&typedResource, opts)
 
 			//This is the application's HelmCR of kind HelmRelease.
 		case [2]string{"HelmRelease", APPS_OPEN_CLUSTER_MANAGEMENT_IO}:
@@ -263,7 +367,25 @@ func TransformRoutine(input chan *Event, output chan NodeEvent) {
 			if err != nil {
 				panic(err) // Will be caught by handleRoutineExit
 			}
-			trans = AppHelmCRResourceBuilder(&typedResource)
+			trans = AppHelmCRResourceBuilder(&typedResource, opts)
+
+		case [2]string{"HelmRelease", "helm.toolkit.fluxcd.io"}:
+			typedResource := FluxHelmRelease{}
+			err := runtime.DefaultUnstructuredConverter.
+				FromUnstructured(event.Resource.UnstructuredContent(), &typedResource)
+			if err != nil {
+				panic(err) // Will be caught by handleRoutineExit
+			}
+			trans = FluxHelmReleaseResourceBuilder(&typedResource, opts)
+
+		case [2]string{"HorizontalPodAutoscaler", "autoscaling"}:
+			typedResource := autoscaling.HorizontalPodAutoscaler{}
+			err := runtime.DefaultUnstructuredConverter.
+				FromUnstructured(event.Resource.UnstructuredContent(), &typedResource)
+			if err != nil {
+				panic(err) // Will be caught by handleRoutineExit
+			}
+			trans = HorizontalPodAutoscalerResourceBuilder(&typedResource, opts)
 
 		case [2]string{"KlusterletAddonConfig", "agent.open-cluster-management.io"}:
 			typedResource := klusterletaddon.KlusterletAddonConfig{}
@@ -272,7 +394,25 @@ func TransformRoutine(input chan *Event, output chan NodeEvent) {
 			if err != nil {
 				panic(err) // Will be caught by handleRoutineExit
 			}
-			trans = KlusterletAddonConfigResourceBuilder(&typedResource)
+			trans = KlusterletAddonConfigResourceBuilder(&typedResource, opts)
+
+		case [2]string{"Kustomization", "kustomize.toolkit.fluxcd.io"}:
+			typedResource := FluxKustomization{}
+			err := runtime.DefaultUnstructuredConverter.
+				FromUnstructured(event.Resource.UnstructuredContent(), &typedResource)
+			if err != nil {
+				panic(err) // Will be caught by handleRoutineExit
+			}
+			trans = FluxKustomizationResourceBuilder(&typedResource, opts)
+
+		case [2]string{"Ingress", "networking.k8s.io"}:
+			typedResource := networking.Ingress{}
+			err := runtime.DefaultUnstructuredConverter.
+				FromUnstructured(event.Resource.UnstructuredContent(), &typedResource)
+			if err != nil {
+				panic(err) // Will be caught by handleRoutineExit
+			}
+			trans = IngressResourceBuilder(&typedResource, opts)
 
 		case [2]string{"Job", "batch"}:
 			typedResource := batch.Job{}
@@ -281,7 +421,7 @@ func TransformRoutine(input chan *Event, output chan NodeEvent) {
 			if err != nil {
 				panic(err) // Will be caught by handleRoutineExit
 			}
-			trans = JobResourceBuilder(&typedResource)
+			trans = JobResourceBuilder(&typedResource, opts)
 
 		case [2]string{"Namespace", ""}:
 			typedResource := core.Namespace{}
@@ -290,7 +430,16 @@ func TransformRoutine(input chan *Event, output chan NodeEvent) {
 			if err != nil {
 				panic(err) // Will be caught by handleRoutineExit
 			}
-			trans = NamespaceResourceBuilder(&typedResource)
+			trans = NamespaceResourceBuilder(&typedResource, opts)
+
+		case [2]string{"NetworkPolicy", "networking.k8s.io"}:
+			typedResource := networking.NetworkPolicy{}
+			err := runtime.DefaultUnstructuredConverter.
+				FromUnstructured(event.Resource.UnstructuredContent(), &typedResource)
+			if err != nil {
+				panic(err) // Will be caught by handleRoutineExit
+			}
+			trans = NetworkPolicyResourceBuilder(&typedResource, opts)
 
 		case [2]string{"Node", ""}:
 			typedResource := core.Node{}
@@ -299,7 +448,7 @@ func TransformRoutine(input chan *Event, output chan NodeEvent) {
 			if err != nil {
 				panic(err) // Will be caught by handleRoutineExit
 			}
-			trans = NodeResourceBuilder(&typedResource)
+			trans = NodeResourceBuilder(&typedResource, opts)
 
 		case [2]string{"PersistentVolume", ""}:
 			typedResource := core.PersistentVolume{}
@@ -308,7 +457,7 @@ func TransformRoutine(input chan *Event, output chan NodeEvent) {
 			if err != nil {
 				panic(err) // Will be caught by handleRoutineExit
 			}
-			trans = PersistentVolumeResourceBuilder(&typedResource)
+			trans = PersistentVolumeResourceBuilder(&typedResource, opts)
 
 		case [2]string{"PersistentVolumeClaim", ""}:
 			typedResource := core.PersistentVolumeClaim{}
@@ -317,16 +466,35 @@ func TransformRoutine(input chan *Event, output chan NodeEvent) {
 			if err != nil {
 				panic(err) // Will be caught by handleRoutineExit
 			}
-			trans = PersistentVolumeClaimResourceBuilder(&typedResource)
+			trans = PersistentVolumeClaimResourceBuilder(&typedResource, opts)
 
-		case [2]string{"PlacementBinding", APPS_OPEN_CLUSTER_MANAGEMENT_IO}:
+		case [2]string{"Placement", "cluster.open-cluster-management.io"}:
+			typedResource := cluster.Placement{}
+			err := runtime.DefaultUnstructuredConverter.
+				FromUnstructured(event.Resource.UnstructuredContent(), &typedResource)
+			if err != nil {
+				panic(err) // Will be caught by handleRoutineExit
+			}
+			trans = PlacementResourceBuilder(&typedResource, opts)
+
+		case [2]string{"PlacementBinding", APPS_OPEN_CLUSTER_MANAGEMENT_IO},
+			[2]string{"PlacementBinding", "policy.open-cluster-management.io"}:
 			typedResource := policy.PlacementBinding{}
 			err := runtime.DefaultUnstructuredConverter.
 				FromUnstructured(event.Resource.UnstructuredContent(), &typedResource)
 			if err != nil {
 				panic(err) // Will be caught by handleRoutineExit
 			}
-			trans = PlacementBindingResourceBuilder(&typedResource)
+			trans = PlacementBindingResourceBuilder(&typedResource, opts)
+
+		case [2]string{"PlacementDecision", "cluster.open-cluster-management.io"}:
+			typedResource := cluster.PlacementDecision{}
+			err := runtime.DefaultUnstructuredConverter.
+				FromUnstructured(event.Resource.UnstructuredContent(), &typedResource)
+			if err != nil {
+				panic(err) // Will be caught by handleRoutineExit
+			}
+			trans = PlacementDecisionResourceBuilder(&typedResource, opts)
 
 		case [2]string{"PlacementRule", APPS_OPEN_CLUSTER_MANAGEMENT_IO}:
 			typedResource := rule.PlacementRule{}
@@ -335,7 +503,7 @@ func TransformRoutine(input chan *Event, output chan NodeEvent) {
 			if err != nil {
 				panic(err) // Will be caught by handleRoutineExit
 			}
-			trans = PlacementRuleResourceBuilder(&typedResource)
+			trans = PlacementRuleResourceBuilder(&typedResource, opts)
 
 		case [2]string{"Pod", ""}:
 			typedResource := core.Pod{}
@@ -344,7 +512,16 @@ func TransformRoutine(input chan *Event, output chan NodeEvent) {
 			if err != nil {
 				panic(err) // Will be caught by handleRoutineExit
 			}
-			trans = PodResourceBuilder(&typedResource)
+			trans = PodResourceBuilder(&typedResource, opts)
+
+		case [2]string{"PodDisruptionBudget", "policy"}:
+			typedResource := policyv1.PodDisruptionBudget{}
+			err := runtime.DefaultUnstructuredConverter.
+				FromUnstructured(event.Resource.UnstructuredContent(), &typedResource)
+			if err != nil {
+				panic(err) // Will be caught by handleRoutineExit
+			}
+			trans = PodDisruptionBudgetResourceBuilder(&typedResource, opts)
 
 		case [2]string{"Policy", "policy.open-cluster-management.io"},
 			[2]string{"Policy", "policies.open-cluster-management.io"}:
@@ -354,7 +531,7 @@ func TransformRoutine(input chan *Event, output chan NodeEvent) {
 			if err != nil {
 				panic(err) // Will be caught by handleRoutineExit
 			}
-			trans = PolicyResourceBuilder(&typedResource)
+			trans = PolicyResourceBuilder(&typedResource, opts)
 
 		case [2]string{"ReplicaSet", "apps"},
 			[2]string{"ReplicaSet", "extensions"}:
@@ -364,7 +541,35 @@ func TransformRoutine(input chan *Event, output chan NodeEvent) {
 			if err != nil {
 				panic(err) // Will be caught by handleRoutineExit
 			}
-			trans = ReplicaSetResourceBuilder(&typedResource)
+			trans = ReplicaSetResourceBuilder(&typedResource, opts)
+
+		case [2]string{"Role", "rbac.authorization.k8s.io"}:
+			typedResource := rbac.Role{}
+			err := runtime.DefaultUnstructuredConverter.
+				FromUnstructured(event.Resource.UnstructuredContent(), &typedResource)
+			if err != nil {
+				panic(err) // Will be caught by handleRoutineExit
+			}
+			trans = RoleResourceBuilder(&typedResource, opts)
+
+		case [2]string{"RoleBinding", "rbac.authorization.k8s.io"}:
+			typedResource := rbac.RoleBinding{}
+			err := runtime.DefaultUnstructuredConverter.
+				FromUnstructured(event.Resource.UnstructuredContent(), &typedResource)
+			if err != nil {
+				panic(err) // Will be caught by handleRoutineExit
+			}
+			trans = RoleBindingResourceBuilder(&typedResource, opts)
+
+			//This is an ocp specific resource
+		case [2]string{"Route", "route.openshift.io"}:
+			typedResource := Route{}
+			err := runtime.DefaultUnstructuredConverter.
+				FromUnstructured(event.Resource.UnstructuredContent(), &typedResource)
+			if err != nil {
+				panic(err) // Will be caught by handleRoutineExit
+			}
+			trans = RouteResourceBuilder(&typedResource, opts)
 
 		case [2]string{"Service", ""}:
 			typedResource := core.Service{}
@@ -373,7 +578,7 @@ func TransformRoutine(input chan *Event, output chan NodeEvent) {
 			if err != nil {
 				panic(err) // Will be caught by handleRoutineExit
 			}
-			trans = ServiceResourceBuilder(&typedResource)
+			trans = ServiceResourceBuilder(&typedResource, opts)
 
 		case [2]string{"StatefulSet", "apps"}:
 			typedResource := apps.StatefulSet{}
@@ -382,7 +587,7 @@ func TransformRoutine(input chan *Event, output chan NodeEvent) {
 			if err != nil {
 				panic(err) // Will be caught by handleRoutineExit
 			}
-			trans = StatefulSetResourceBuilder(&typedResource)
+			trans = StatefulSetResourceBuilder(&typedResource, opts)
 
 		case [2]string{"Subscription", APPS_OPEN_CLUSTER_MANAGEMENT_IO}:
 			typedResource := subscription.Subscription{}
@@ -391,7 +596,7 @@ func TransformRoutine(input chan *Event, output chan NodeEvent) {
 			if err != nil {
 				panic(err) // Will be caught by handleRoutineExit
 			}
-			trans = SubscriptionResourceBuilder(&typedResource)
+			trans = SubscriptionResourceBuilder(&typedResource, opts)
 
 		case [2]string{"PolicyReport", "wgpolicyk8s.io"}:
 			typedResource := PolicyReport{}
@@ -400,10 +605,10 @@ func TransformRoutine(input chan *Event, output chan NodeEvent) {
 			if err != nil {
 				panic(err) // Will be caught by handleRoutineExit
 			}
-			trans = PolicyReportResourceBuilder(&typedResource)
+			trans = PolicyReportResourceBuilder(&typedResource, opts)
 
 		default:
-			trans = GenericResourceBuilder(event.Resource)
+			trans = GenericResourceBuilder(event.Resource, opts)
 		}
 
 		output <- NewNodeEvent(event, trans, event.ResourceString)
@@ -413,7 +618,7 @@ func TransformRoutine(input chan *Event, output chan NodeEvent) {
 // Handles a panic from inside transformRoutine.
 // If the panic was due to an error, starts another transformRoutine with the same channels as this one.
 // If not, just lets it die.
-func handleRoutineExit(input chan *Event, output chan NodeEvent) {
+func handleRoutineExit(input chan *Event, output chan NodeEvent, opts Options) {
 	// Recover and check the value. If we are here because of a panic, something will be in it.
 	if r := recover(); r != nil { // Case where we got here from a panic
 		glog.Errorf("Error in transformer routine: %v\n", r)
@@ -421,6 +626,6 @@ func handleRoutineExit(input chan *Event, output chan NodeEvent) {
 
 		// Start up a new routine with the same channels as the old one. The bad input will be gone since the
 		// old routine (the one that just crashed) took it out of the channel.
-		go TransformRoutine(input, output)
+		go TransformRoutine(input, output, opts)
 	}
 }
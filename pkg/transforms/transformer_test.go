@@ -29,7 +29,7 @@ func TestTransformRoutine(t *testing.T) {
 	var appInput unstructured.Unstructured
 	UnmarshalFile("application.json", &appTyped, t)
 	UnmarshalFile("application.json", &appInput, t)
-	appNode := ApplicationResourceBuilder(&appTyped).BuildNode()
+	appNode := ApplicationResourceBuilder(&appTyped, testOptions).BuildNode()
 	appNode.ResourceString = "applications"
 	unstructuredInput := unstructured.Unstructured{
 		Object: map[string]interface{}{
@@ -39,7 +39,7 @@ func TestTransformRoutine(t *testing.T) {
 			},
 		},
 	}
-	unstructuredNode := GenericResourceBuilder(&unstructuredInput).BuildNode()
+	unstructuredNode := GenericResourceBuilder(&unstructuredInput, testOptions).BuildNode()
 	unstructuredNode.ResourceString = "unstructured"
 
 	var addonTyped agentv1.KlusterletAddonConfig
@@ -47,7 +47,7 @@ func TestTransformRoutine(t *testing.T) {
 	UnmarshalFile("klusterletaddonconfig.json", &addonInput, t)
 	UnmarshalFile("klusterletaddonconfig.json", &addonTyped, t)
 
-	addonNode := KlusterletAddonConfigResourceBuilder(&addonTyped).BuildNode()
+	addonNode := KlusterletAddonConfigResourceBuilder(&addonTyped, testOptions).BuildNode()
 	addonNode.ResourceString = "klusterletaddonconfigs"
 
 	var tests = []struct {
@@ -113,7 +113,7 @@ func TestTransformRoutine(t *testing.T) {
 		},
 	}
 
-	go TransformRoutine(input, output)
+	go TransformRoutine(input, output, testOptions)
 
 	for _, test := range tests {
 		input <- test.in
@@ -124,3 +124,14 @@ func TestTransformRoutine(t *testing.T) {
 		AssertEqual(test.name, actual.Operation, test.expected.Operation, t)
 	}
 }
+
+func TestSetNonNSResources(t *testing.T) {
+	SetNonNSResources("cluster-a", map[string]struct{}{"Node": {}, "ClusterRole": {}})
+	SetNonNSResources("cluster-b", map[string]struct{}{"Node": {}})
+	// The next discovery replaces the kinds, so a deleted CRD is no longer non-namespaced.
+	SetNonNSResources("cluster-a", map[string]struct{}{"Node": {}})
+
+	AssertEqual("Node", isNonNamespaced("cluster-a", "Node"), true, t)
+	AssertEqual("ClusterRole", isNonNamespaced("cluster-a", "ClusterRole"), false, t)
+	AssertEqual("other cluster", isNonNamespaced("cluster-c", "Node"), false, t)
+}